@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"time"
 
 	"github.com/CREVIOS/revo/internal/config"
+	"github.com/CREVIOS/revo/internal/eval"
+	"github.com/CREVIOS/revo/internal/local"
 	"github.com/CREVIOS/revo/internal/server"
 	"github.com/CREVIOS/revo/internal/worker"
 	"github.com/rs/zerolog"
@@ -15,6 +18,25 @@ func main() {
 	// Setup logging
 	setupLogging()
 
+	// Local subcommands need no GitHub, database or Redis configuration
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "eval":
+			if err := eval.Run(config.LoadLocal(), os.Args[2:], os.Stdout); err != nil {
+				log.Fatal().Err(err).Msg("Evaluation failed")
+			}
+			return
+		case "review":
+			if err := local.Run(config.LoadLocal(), os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				if errors.Is(err, local.ErrFindings) {
+					os.Exit(1)
+				}
+				log.Fatal().Err(err).Msg("Local review failed")
+			}
+			return
+		}
+	}
+
 	log.Info().Msg("Starting TechyBot...")
 
 	// Load configuration
@@ -0,0 +1,211 @@
+package analyzers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotInstalled is returned when an analyzer's binary is not on PATH
+var ErrNotInstalled = errors.New("analyzer not installed")
+
+// Analyzer runs a static analysis tool over a checkout
+type Analyzer interface {
+	Name() string
+	// Extensions of changed files that make the analyzer worth running; empty runs it always
+	Extensions() []string
+	Run(ctx context.Context, dir string) ([]models.AnalyzerFinding, error)
+}
+
+// Config configures static analysis
+type Config struct {
+	Enabled     []string      // built-in analyzers to run; empty runs all of them
+	Commands    string        // extra "name=command" analyzers separated by semicolons
+	Timeout     time.Duration // limit for one analyzer
+	MaxFindings int           // findings sent with one review
+}
+
+// Runner runs the applicable analyzers and keeps findings on changed lines
+type Runner struct {
+	analyzers []Analyzer
+	config    Config
+}
+
+// NewRunner creates a runner with the enabled built-in analyzers and any configured commands
+func NewRunner(config Config) *Runner {
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Minute
+	}
+	if config.MaxFindings <= 0 {
+		config.MaxFindings = 50
+	}
+
+	var analyzers []Analyzer
+	for _, a := range Builtin() {
+		if len(config.Enabled) == 0 || contains(config.Enabled, a.Name()) {
+			analyzers = append(analyzers, a)
+		}
+	}
+	analyzers = append(analyzers, ParseCommands(config.Commands)...)
+
+	return &Runner{analyzers: analyzers, config: config}
+}
+
+// Analyze runs the analyzers that apply to the changed files and returns the
+// findings that fall on added or modified lines
+func (r *Runner) Analyze(ctx context.Context, dir string, files []models.PRFile) []models.AnalyzerFinding {
+	changed := make(map[string]map[int]bool, len(files))
+	for _, file := range files {
+		if file.Status != "removed" {
+			changed[file.Filename] = gh.GetChangedLineNumbers(file.Patch)
+		}
+	}
+
+	var findings []models.AnalyzerFinding
+	for _, a := range r.analyzers {
+		if !applies(a, files) {
+			continue
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+		start := time.Now()
+		results, err := a.Run(runCtx, dir)
+		cancel()
+		if errors.Is(err, ErrNotInstalled) {
+			log.Debug().Str("analyzer", a.Name()).Msg("Analyzer not installed, skipping")
+			continue
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("analyzer", a.Name()).Msg("Analyzer failed")
+			continue
+		}
+
+		kept := 0
+		for _, f := range results {
+			if lines, ok := changed[f.Path]; ok && lines[f.Line] {
+				findings = append(findings, f)
+				kept++
+			}
+		}
+
+		log.Info().
+			Str("analyzer", a.Name()).
+			Int("findings", len(results)).
+			Int("on_changed_lines", kept).
+			Dur("duration", time.Since(start)).
+			Msg("Ran static analyzer")
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	if len(findings) > r.config.MaxFindings {
+		findings = findings[:r.config.MaxFindings]
+	}
+	return findings
+}
+
+// Tag records the analyzer a comment confirms, from the "[tool ...]" prefix the
+// prompt asks for on confirmed analyzer findings
+func Tag(comments []models.ReviewComment, findings []models.AnalyzerFinding) {
+	if len(findings) == 0 {
+		return
+	}
+	for i := range comments {
+		body := strings.TrimSpace(comments[i].Body)
+		if !strings.HasPrefix(body, "[") {
+			continue
+		}
+		for _, f := range findings {
+			if strings.HasPrefix(body, "["+f.Tool+" ") || strings.HasPrefix(body, "["+f.Tool+"]") {
+				comments[i].Source = f.Tool
+				break
+			}
+		}
+	}
+}
+
+// applies reports whether any changed file has one of the analyzer's extensions
+func applies(a Analyzer, files []models.PRFile) bool {
+	extensions := a.Extensions()
+	if len(extensions) == 0 {
+		return true
+	}
+	for _, file := range files {
+		if file.Status != "removed" && contains(extensions, path.Ext(file.Filename)) {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes a tool in dir and returns its combined output. A non-zero exit
+// is not an error, since most analyzers exit 1 when they report anything.
+func run(ctx context.Context, dir, name string, args ...string) (string, error) {
+	binary, err := exec.LookPath(name)
+	if err != nil {
+		return "", ErrNotInstalled
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GOTOOLCHAIN=local", // never download the toolchain a go.mod asks for
+		"GOFLAGS=-mod=mod",
+		"CGO_ENABLED=0",
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s timed out", name)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return "", fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return output.String(), nil
+}
+
+// relPath makes a reported path relative to the checkout, or "" if it is outside
+func relPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return ""
+		}
+		p = rel
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return ""
+	}
+	return p
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
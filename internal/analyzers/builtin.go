@@ -0,0 +1,198 @@
+package analyzers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// Builtin returns the analyzers that ship with the bot
+func Builtin() []Analyzer {
+	return []Analyzer{goVet{}, staticcheck{}, gosec{}}
+}
+
+var goExtensions = []string{".go"}
+
+// goVet runs `go vet` over every package in the module
+type goVet struct{}
+
+func (goVet) Name() string         { return "go vet" }
+func (goVet) Extensions() []string { return goExtensions }
+
+func (goVet) Run(ctx context.Context, dir string) ([]models.AnalyzerFinding, error) {
+	output, err := run(ctx, dir, "go", "vet", "./...")
+	if err != nil {
+		return nil, err
+	}
+	return parseLines("go vet", dir, output), nil
+}
+
+// staticcheck runs staticcheck with JSON output
+type staticcheck struct{}
+
+func (staticcheck) Name() string         { return "staticcheck" }
+func (staticcheck) Extensions() []string { return goExtensions }
+
+func (staticcheck) Run(ctx context.Context, dir string) ([]models.AnalyzerFinding, error) {
+	output, err := run(ctx, dir, "staticcheck", "-f", "json", "./...")
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []models.AnalyzerFinding
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var issue struct {
+			Code     string `json:"code"`
+			Severity string `json:"severity"`
+			Location struct {
+				File   string `json:"file"`
+				Line   int    `json:"line"`
+				Column int    `json:"column"`
+			} `json:"location"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &issue) != nil || issue.Code == "compile" {
+			continue
+		}
+		if p := relPath(dir, issue.Location.File); p != "" {
+			findings = append(findings, models.AnalyzerFinding{
+				Tool:     "staticcheck",
+				Rule:     issue.Code,
+				Path:     p,
+				Line:     issue.Location.Line,
+				Column:   issue.Location.Column,
+				Severity: severity(issue.Severity),
+				Message:  issue.Message,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// gosec runs the gosec security scanner with JSON output
+type gosec struct{}
+
+func (gosec) Name() string         { return "gosec" }
+func (gosec) Extensions() []string { return goExtensions }
+
+func (gosec) Run(ctx context.Context, dir string) ([]models.AnalyzerFinding, error) {
+	output, err := run(ctx, dir, "gosec", "-fmt", "json", "-quiet", "-no-fail", "./...")
+	if err != nil {
+		return nil, err
+	}
+
+	// Progress lines may precede the report
+	if i := strings.Index(output, "{"); i > 0 {
+		output = output[i:]
+	}
+	var report struct {
+		Issues []struct {
+			Severity string `json:"severity"`
+			RuleID   string `json:"rule_id"`
+			Details  string `json:"details"`
+			File     string `json:"file"`
+			Line     string `json:"line"` // "12" or a range like "12-14"
+			Column   string `json:"column"`
+		} `json:"Issues"`
+	}
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		return nil, nil
+	}
+
+	var findings []models.AnalyzerFinding
+	for _, issue := range report.Issues {
+		first, _, _ := strings.Cut(issue.Line, "-")
+		line, _ := strconv.Atoi(first)
+		column, _ := strconv.Atoi(issue.Column)
+		if p := relPath(dir, issue.File); p != "" && line > 0 {
+			findings = append(findings, models.AnalyzerFinding{
+				Tool:     "gosec",
+				Rule:     issue.RuleID,
+				Path:     p,
+				Line:     line,
+				Column:   column,
+				Severity: severity(issue.Severity),
+				Message:  issue.Details,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// command is a configured analyzer whose output uses the common
+// "file:line[:column]: message" format, e.g. ruff or eslint -f unix
+type command struct {
+	name string
+	cmd  string
+}
+
+func (c command) Name() string       { return c.name }
+func (command) Extensions() []string { return nil }
+
+func (c command) Run(ctx context.Context, dir string) ([]models.AnalyzerFinding, error) {
+	output, err := run(ctx, dir, "sh", "-c", c.cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseLines(c.name, dir, output), nil
+}
+
+// ParseCommands reads "name=command" analyzers separated by semicolons
+func ParseCommands(spec string) []Analyzer {
+	var analyzers []Analyzer
+	for _, entry := range strings.Split(spec, ";") {
+		name, cmd, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name, cmd = strings.TrimSpace(name), strings.TrimSpace(cmd)
+		if ok && name != "" && cmd != "" {
+			analyzers = append(analyzers, command{name: name, cmd: cmd})
+		}
+	}
+	return analyzers
+}
+
+var linePattern = regexp.MustCompile(`^(?:\./)?([^\s:][^:]*):(\d+):(?:(\d+):)?\s*(.+)$`)
+
+// parseLines reads "file:line[:column]: message" diagnostics
+func parseLines(tool, dir, output string) []models.AnalyzerFinding {
+	var findings []models.AnalyzerFinding
+	for _, line := range strings.Split(output, "\n") {
+		match := linePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		p := relPath(dir, match[1])
+		lineNum, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		if p == "" || lineNum == 0 {
+			continue
+		}
+		findings = append(findings, models.AnalyzerFinding{
+			Tool:     tool,
+			Path:     p,
+			Line:     lineNum,
+			Column:   column,
+			Severity: "warning",
+			Message:  match[4],
+		})
+	}
+	return findings
+}
+
+// severity maps tool severities onto error, warning and info
+func severity(value string) string {
+	switch strings.ToLower(value) {
+	case "high", "error":
+		return "error"
+	case "low", "info", "ignored":
+		return "info"
+	default:
+		return "warning"
+	}
+}
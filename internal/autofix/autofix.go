@@ -0,0 +1,177 @@
+package autofix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/database"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/google/go-github/v60/github"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotFinding is returned when the comment replied to is not one of the bot's findings
+var ErrNotFinding = errors.New("the comment is not a TechyBot finding")
+
+// Fixer commits the suggested change of a finding to its pull request
+type Fixer struct {
+	githubClient *gh.Client
+	store        *database.Store
+}
+
+// Request identifies the finding to fix and who asked for it
+type Request struct {
+	Owner     string
+	Repo      string
+	PRNumber  int
+	CommentID int64 // inline comment holding the finding
+	Requester *gh.User
+}
+
+// Result describes where the fix was committed
+type Result struct {
+	CommitSHA string
+	Branch    string
+	StackedPR int // PR opened with the fix when the head branch is in a fork; 0 when pushed directly
+}
+
+// New creates a new Fixer
+func New(githubClient *gh.Client, store *database.Store) *Fixer {
+	return &Fixer{
+		githubClient: githubClient,
+		store:        store,
+	}
+}
+
+// Fix applies the finding's suggestion to the file at the PR head and commits
+// it as the requester. Same-repository branches are fast-forwarded; for forks,
+// where the app cannot push, a stacked PR with the fix is opened instead.
+func (f *Fixer) Fix(ctx context.Context, req Request) (*Result, error) {
+	comment, err := f.githubClient.GetReviewComment(ctx, req.Owner, req.Repo, req.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	appLogin, err := f.githubClient.AppLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Other apps' suggestions must not be committed in the requester's name
+	if !strings.EqualFold(comment.GetUser().GetLogin(), appLogin) {
+		return nil, ErrNotFinding
+	}
+	suggestion, ok := Suggestion(comment.GetBody())
+	if !ok {
+		return nil, ErrNoSuggestion
+	}
+
+	pr, err := f.githubClient.GetPullRequest(ctx, req.Owner, req.Repo, req.PRNumber)
+	if err != nil {
+		return nil, err
+	}
+	if pr.GetState() != "open" {
+		return nil, fmt.Errorf("%w: the pull request is %s", ErrConflict, pr.GetState())
+	}
+	headSHA := pr.GetHead().GetSHA()
+
+	path := comment.GetPath()
+	end := comment.GetOriginalLine()
+	start := comment.GetOriginalStartLine()
+	if start == 0 {
+		start = end
+	}
+
+	original, err := f.githubClient.GetFileContent(ctx, req.Owner, req.Repo, path, comment.GetOriginalCommitID())
+	if err != nil {
+		return nil, err
+	}
+	current, err := f.githubClient.GetFileContent(ctx, req.Owner, req.Repo, path, headSHA)
+	if err != nil {
+		return nil, err
+	}
+	if current == "" {
+		return nil, fmt.Errorf("%w: %s no longer exists", ErrConflict, path)
+	}
+
+	patched, err := Apply(original, current, start, end, suggestion)
+	if err != nil {
+		return nil, err
+	}
+
+	author := commitAuthor(req.Requester)
+	message := fmt.Sprintf("Apply suggested fix to %s\n\n%s\n\nRequested by @%s in #%d.",
+		path, findingTitle(comment.GetBody()), author.GetName(), req.PRNumber)
+	files := []gh.FileChange{{Path: path, Content: patched}}
+
+	result := &Result{}
+	if pr.GetHead().GetRepo().GetFullName() == req.Owner+"/"+req.Repo {
+		sha, err := f.githubClient.CommitFiles(ctx, req.Owner, req.Repo, headSHA, message, author, files)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.githubClient.UpdateBranch(ctx, req.Owner, req.Repo, pr.GetHead().GetRef(), sha); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConflict, err)
+		}
+		result.CommitSHA = sha
+		result.Branch = pr.GetHead().GetRef()
+	} else {
+		// The fork's commits are reachable in the base repository through the
+		// PR's head ref, so the fix can be stacked on them in a branch here
+		result.Branch = fmt.Sprintf("techy/fix-%d-%d", req.PRNumber, req.CommentID)
+		body := fmt.Sprintf("Applies the suggested fix from %s on top of #%d, whose branch is in a fork the app cannot push to.\n\nRequested by @%s.",
+			comment.GetHTMLURL(), req.PRNumber, author.GetName())
+		stacked, err := f.githubClient.CreateBranchPullRequest(ctx, req.Owner, req.Repo, pr.GetBase().GetRef(), headSHA, result.Branch,
+			message, fmt.Sprintf("Fix %s from #%d", path, req.PRNumber), body, author, files)
+		if err != nil {
+			return nil, err
+		}
+		result.StackedPR = stacked.GetNumber()
+		result.CommitSHA = stacked.GetHead().GetSHA()
+	}
+
+	f.markFixed(req, comment, result)
+	return result, nil
+}
+
+// markFixed records the fix on the stored finding
+func (f *Fixer) markFixed(req Request, comment *github.PullRequestComment, result *Result) {
+	if f.store == nil {
+		return
+	}
+
+	finding, err := f.store.FindReviewComment(req.Owner, req.Repo, req.PRNumber, req.CommentID,
+		comment.GetPath(), comment.GetOriginalLine(), comment.GetBody())
+	if err != nil {
+		log.Warn().Err(err).Int64("comment_id", req.CommentID).Msg("Failed to find the stored finding to mark fixed")
+		return
+	}
+
+	if err := f.store.UpdateReviewComment(finding.ID, map[string]interface{}{
+		"fixed_at":          time.Now(),
+		"fixed_by":          req.Requester.Login,
+		"fix_commit_sha":    result.CommitSHA,
+		"github_comment_id": req.CommentID,
+	}); err != nil {
+		log.Warn().Err(err).Uint("review_comment_id", finding.ID).Msg("Failed to mark finding fixed")
+	}
+}
+
+// commitAuthor attributes a commit to a GitHub user through their noreply address
+func commitAuthor(user *gh.User) *github.CommitAuthor {
+	return &github.CommitAuthor{
+		Name:  github.String(user.Login),
+		Email: github.String(fmt.Sprintf("%d+%s@users.noreply.github.com", user.ID, user.Login)),
+	}
+}
+
+// findingTitle returns the first line of a finding, without markup, for the commit message
+func findingTitle(body string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	line = strings.TrimSpace(strings.Trim(line, "*_#"))
+	if runes := []rune(line); len(runes) > 72 {
+		line = string(runes[:69]) + "..."
+	}
+	return line
+}
@@ -0,0 +1,94 @@
+package autofix
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrNoSuggestion is returned for findings without a suggestion block
+	ErrNoSuggestion = errors.New("the finding has no suggested change")
+
+	// ErrConflict is returned when the lines a suggestion replaces changed since the review
+	ErrConflict = errors.New("the suggested change conflicts with the current head")
+
+	// ErrAlreadyApplied is returned when the head already contains the suggested lines
+	ErrAlreadyApplied = errors.New("the suggested change is already applied")
+)
+
+var suggestionPattern = regexp.MustCompile("(?ms)```suggestion[ \\t]*\\r?\\n(.*?)^[ \\t]*```")
+
+// Suggestion returns the replacement lines of the first suggestion block in a
+// comment body. An empty suggestion deletes the commented lines.
+func Suggestion(body string) (string, bool) {
+	match := suggestionPattern.FindStringSubmatch(body)
+	if match == nil {
+		return "", false
+	}
+	return strings.ReplaceAll(match[1], "\r\n", "\n"), true
+}
+
+// Apply replaces lines start through end (1-based) of original, the file the
+// finding was made on, with replacement in current, the file at the PR head.
+// The lines are replaced in place when they have not moved, otherwise at their
+// only occurrence in current; anything else is reported as ErrConflict.
+func Apply(original, current string, start, end int, replacement string) (string, error) {
+	originalLines := strings.Split(original, "\n")
+	if start < 1 || end < start || end > len(originalLines) {
+		return "", fmt.Errorf("%w: lines %d-%d are outside the reviewed file", ErrConflict, start, end)
+	}
+	old := originalLines[start-1 : end]
+
+	var replaced []string
+	if replacement != "" {
+		replaced = strings.Split(strings.TrimSuffix(replacement, "\n"), "\n")
+	}
+
+	lines := strings.Split(current, "\n")
+	at := start - 1
+	if end > len(lines) || !equal(lines[at:end], old) {
+		matches := find(lines, old)
+		switch {
+		case len(matches) == 1:
+			at = matches[0]
+		case len(matches) > 1:
+			return "", fmt.Errorf("%w: the reviewed lines now appear %d times", ErrConflict, len(matches))
+		case len(replaced) > 0 && len(find(lines, replaced)) > 0:
+			return "", ErrAlreadyApplied
+		default:
+			return "", fmt.Errorf("%w: the reviewed lines were changed or removed", ErrConflict)
+		}
+	}
+
+	patched := make([]string, 0, len(lines)-len(old)+len(replaced))
+	patched = append(patched, lines[:at]...)
+	patched = append(patched, replaced...)
+	patched = append(patched, lines[at+len(old):]...)
+	return strings.Join(patched, "\n"), nil
+}
+
+// find returns the indexes at which block occurs in lines
+func find(lines, block []string) []int {
+	var matches []int
+	for i := 0; i+len(block) <= len(lines); i++ {
+		if equal(lines[i:i+len(block)], block) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// equal reports whether two line slices are identical
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package autofix
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	const original = "a\nb\nc\nd\n"
+
+	tests := []struct {
+		name        string
+		current     string
+		start, end  int
+		replacement string
+		want        string
+		wantErr     error
+	}{
+		{
+			name:        "single line in place",
+			current:     original,
+			start:       2,
+			end:         2,
+			replacement: "B\n",
+			want:        "a\nB\nc\nd\n",
+		},
+		{
+			name:        "range replaced by more lines",
+			current:     original,
+			start:       2,
+			end:         3,
+			replacement: "x\ny\nz",
+			want:        "a\nx\ny\nz\nd\n",
+		},
+		{
+			name:    "empty suggestion deletes the lines",
+			current: original,
+			start:   2,
+			end:     3,
+			want:    "a\nd\n",
+		},
+		{
+			name:        "lines moved since the review",
+			current:     "new\nnew\na\nb\nc\nd\n",
+			start:       2,
+			end:         2,
+			replacement: "B",
+			want:        "new\nnew\na\nB\nc\nd\n",
+		},
+		{
+			name:        "file shortened since the review",
+			current:     "b\nc\n",
+			start:       3,
+			end:         3,
+			replacement: "C",
+			want:        "b\nC\n",
+		},
+		{
+			name:        "lines changed since the review",
+			current:     "a\nchanged\nc\nd\n",
+			start:       2,
+			end:         2,
+			replacement: "B",
+			wantErr:     ErrConflict,
+		},
+		{
+			name:        "lines appear more than once",
+			current:     "b\na\nx\nb\n",
+			start:       2,
+			end:         2,
+			replacement: "B",
+			wantErr:     ErrConflict,
+		},
+		{
+			name:        "already applied",
+			current:     "a\nB\nc\nd\n",
+			start:       2,
+			end:         2,
+			replacement: "B\n",
+			wantErr:     ErrAlreadyApplied,
+		},
+		{
+			name:        "range outside the reviewed file",
+			current:     original,
+			start:       4,
+			end:         9,
+			replacement: "x",
+			wantErr:     ErrConflict,
+		},
+		{
+			name:        "inverted range",
+			current:     original,
+			start:       3,
+			end:         2,
+			replacement: "x",
+			wantErr:     ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(original, tt.current, tt.start, tt.end, tt.replacement)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestion(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "single block",
+			body:   "Use the constant.\n\n```suggestion\nreturn defaultTimeout\n```\n",
+			want:   "return defaultTimeout\n",
+			wantOK: true,
+		},
+		{
+			name:   "first of several blocks, CRLF line endings",
+			body:   "```suggestion\r\none\r\n```\r\n```suggestion\ntwo\n```",
+			want:   "one\n",
+			wantOK: true,
+		},
+		{
+			name:   "empty block deletes",
+			body:   "```suggestion\n```",
+			want:   "",
+			wantOK: true,
+		},
+		{
+			name: "plain code block",
+			body: "```go\nreturn nil\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Suggestion(tt.body)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Suggestion() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/CREVIOS/revo/internal/cache"
@@ -20,6 +22,21 @@ type Client struct {
 	retrier      *retry.Retrier
 	promptCache  *cache.PromptCache
 	enableCache  bool
+	backend      Backend
+}
+
+// Backend produces a completion for a prompt in place of the Claude Code CLI,
+// e.g. recorded responses for offline evaluation
+type Backend interface {
+	Complete(ctx context.Context, prompt, model string) (string, error)
+}
+
+// BackendFunc adapts a function to the Backend interface
+type BackendFunc func(ctx context.Context, prompt, model string) (string, error)
+
+// Complete calls f
+func (f BackendFunc) Complete(ctx context.Context, prompt, model string) (string, error) {
+	return f(ctx, prompt, model)
 }
 
 // ClientOption configures the Client
@@ -47,6 +64,13 @@ func WithCacheEnabled(enabled bool) ClientOption {
 	}
 }
 
+// WithBackend replaces the Claude Code CLI with another backend
+func WithBackend(backend Backend) ClientOption {
+	return func(c *Client) {
+		c.backend = backend
+	}
+}
+
 // NewClient creates a new Claude Code CLI client
 func NewClient(claudePath string, model string, opts ...ClientOption) *Client {
 	if claudePath == "" {
@@ -71,7 +95,10 @@ func NewClient(claudePath string, model string, opts ...ClientOption) *Client {
 // ReviewCode performs a code review using Claude Code CLI with retry and caching
 func (c *Client) ReviewCode(ctx context.Context, request *models.ReviewRequest) (string, error) {
 	// Get the appropriate system prompt for the review mode
-	systemPrompt := GetSystemPrompt(request.Command.Mode)
+	systemPrompt := request.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = GetSystemPrompt(request.Owner, request.Repo, request.Command.Mode)
+	}
 
 	// Build the user message with PR context
 	userMessage := buildUserMessage(request)
@@ -85,15 +112,23 @@ func (c *Client) ReviewCode(ctx context.Context, request *models.ReviewRequest)
 	// Combine system prompt, context, and user message
 	fullPrompt := fmt.Sprintf("%s%s\n\n%s", systemPrompt, contextPrompt, userMessage)
 
+	// Allow the command to override the configured model
+	model := c.model
+	if request.Command.Options.Model != "" {
+		model = request.Command.Options.Model
+	}
+	cacheKey := model + "\n" + fullPrompt
+
 	log.Debug().
 		Str("mode", string(request.Command.Mode)).
+		Str("model", model).
 		Int("diff_size", len(request.Diff)).
 		Bool("cache_enabled", c.enableCache).
 		Msg("Sending review request to Claude Code CLI")
 
 	// Check cache first (for identical prompts)
 	if c.enableCache && c.promptCache != nil {
-		if cached, found := c.promptCache.Get(fullPrompt); found {
+		if cached, found := c.promptCache.Get(cacheKey); found {
 			log.Info().
 				Str("repo", fmt.Sprintf("%s/%s", request.Owner, request.Repo)).
 				Int("pr", request.PRNumber).
@@ -106,7 +141,11 @@ func (c *Client) ReviewCode(ctx context.Context, request *models.ReviewRequest)
 	var response string
 	err := c.retrier.Do(ctx, func(ctx context.Context) error {
 		var err error
-		response, err = c.executeClaudeCLI(ctx, fullPrompt)
+		if c.backend != nil {
+			response, err = c.backend.Complete(ctx, fullPrompt, model)
+			return err
+		}
+		response, err = c.executeClaudeCLI(ctx, fullPrompt, model, request.WorkDir, request.TmpDir)
 		return err
 	})
 
@@ -116,25 +155,97 @@ func (c *Client) ReviewCode(ctx context.Context, request *models.ReviewRequest)
 
 	// Cache the successful response
 	if c.enableCache && c.promptCache != nil {
-		c.promptCache.Set(fullPrompt, response)
+		c.promptCache.Set(cacheKey, response)
 	}
 
 	return response, nil
 }
 
-// executeClaudeCLI runs the Claude Code CLI command
-func (c *Client) executeClaudeCLI(ctx context.Context, prompt string) (string, error) {
+// Tools available to the CLI inside a repository checkout. Reads are allowed
+// under the working directory only, and the CLI applies Read rules to Grep,
+// Glob and LS too; in print mode anything not allowed is denied. Anything that
+// can run commands, write files or reach the network is denied outright, as
+// are the places secrets live, in case a path resolves into them.
+const (
+	sandboxAllowedTools    = "Read(./**)"
+	sandboxDisallowedTools = "Bash,Edit,MultiEdit,Write,NotebookEdit,WebFetch,WebSearch,Task," +
+		"Read(~/**),Read(//etc/**),Read(//proc/**),Read(//sys/**),Read(//dev/**),Read(//root/**),Read(//var/**)"
+
+	// noTools denies every tool when there is no checkout to read
+	noTools = "Read,Grep,Glob,LS," + sandboxDisallowedTools
+)
+
+// cliEnvPrefixes are the environment variables passed to the CLI in a checkout.
+// The worker's own settings, such as the GitHub key and database URL, are not.
+var cliEnvPrefixes = []string{
+	"PATH=", "HOME=", "USER=", "LANG=", "LC_", "TZ=", "TERM=",
+	"ANTHROPIC_", "CLAUDE_", "XDG_CONFIG_HOME=",
+	"HTTP_PROXY=", "HTTPS_PROXY=", "NO_PROXY=", "http_proxy=", "https_proxy=", "no_proxy=",
+	"NODE_EXTRA_CA_CERTS=", "SSL_CERT_FILE=", "SSL_CERT_DIR=",
+}
+
+// cliEnv returns the environment for a CLI run in a checkout
+func cliEnv(tmpDir string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		for _, prefix := range cliEnvPrefixes {
+			if strings.HasPrefix(kv, prefix) {
+				env = append(env, kv)
+				break
+			}
+		}
+	}
+	if tmpDir != "" {
+		env = append(env, "TMPDIR="+tmpDir)
+	}
+	return env
+}
+
+// Prompt sends a free-form prompt without caching, running the CLI inside
+// workDir when set. It is used for follow-up tasks such as writing a test.
+func (c *Client) Prompt(ctx context.Context, prompt, model, workDir, tmpDir string) (string, error) {
+	if model == "" {
+		model = c.model
+	}
+
+	var response string
+	err := c.retrier.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if c.backend != nil {
+			response, err = c.backend.Complete(ctx, prompt, model)
+			return err
+		}
+		response, err = c.executeClaudeCLI(ctx, prompt, model, workDir, tmpDir)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
+
+// executeClaudeCLI runs the Claude Code CLI command, inside workDir when set
+func (c *Client) executeClaudeCLI(ctx context.Context, prompt, model, workDir, tmpDir string) (string, error) {
 	// Prepare Claude Code CLI command
 	args := []string{
-		"-p",                             // Print mode (non-interactive)
-		"--dangerously-skip-permissions", // Skip permission prompts
-		"--no-session-persistence",       // Don't save session
-		"--output-format", "text",        // Plain text output
+		"-p",                       // Print mode (non-interactive)
+		"--no-session-persistence", // Don't save session
+		"--output-format", "text",  // Plain text output
+	}
+	if workDir != "" {
+		// Reads of the checkout only; other tool calls are denied in print mode
+		args = append(args,
+			"--allowedTools", sandboxAllowedTools,
+			"--disallowedTools", sandboxDisallowedTools,
+		)
+	} else {
+		args = append(args, "--disallowedTools", noTools) // No tools are needed without a checkout
 	}
 
 	// Add model if specified
-	if c.model != "" {
-		args = append(args, "--model", c.model)
+	if model != "" {
+		args = append(args, "--model", model)
 	}
 
 	// Add the prompt as the last argument
@@ -142,6 +253,10 @@ func (c *Client) executeClaudeCLI(ctx context.Context, prompt string) (string, e
 
 	// Execute Claude Code CLI
 	cmd := exec.CommandContext(ctx, c.claudePath, args...)
+	if workDir != "" {
+		cmd.Dir = workDir
+		cmd.Env = cliEnv(tmpDir)
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -201,9 +316,30 @@ func (c *Client) ClearCache() {
 func buildUserMessage(request *models.ReviewRequest) string {
 	var sb strings.Builder
 
-	sb.WriteString("## Pull Request\n\n")
-	sb.WriteString(fmt.Sprintf("**Repository:** %s/%s\n", request.Owner, request.Repo))
-	sb.WriteString(fmt.Sprintf("**PR #%d:** %s\n\n", request.PRNumber, request.PRTitle))
+	switch target := request.Target; {
+	case target.IsPullRequest():
+		sb.WriteString("## Pull Request\n\n")
+		sb.WriteString(fmt.Sprintf("**Repository:** %s/%s\n", request.Owner, request.Repo))
+		sb.WriteString(fmt.Sprintf("**PR #%d:** %s\n\n", request.PRNumber, request.PRTitle))
+	case target.Kind == models.TargetIssue:
+		sb.WriteString("## Issue\n\n")
+		sb.WriteString(fmt.Sprintf("**Repository:** %s/%s (default branch %s at %s)\n", request.Owner, request.Repo, target.Ref, target.Head))
+		sb.WriteString(fmt.Sprintf("**Issue #%d:** %s\n\n", target.Issue, request.PRTitle))
+	case target.Kind == models.TargetAudit:
+		sb.WriteString("## Scheduled Audit\n\n")
+		sb.WriteString(fmt.Sprintf("**Repository:** %s/%s\n", request.Owner, request.Repo))
+		sb.WriteString(fmt.Sprintf("**Branch %s at %s:** %s\n\n", target.Ref, target.Head, request.PRTitle))
+		sb.WriteString("There is no change under review: the diff shows the current contents of the audited files as added lines. ")
+		sb.WriteString("Report problems in the existing code, using these line numbers.\n\n")
+	case target.Kind == models.TargetCommit:
+		sb.WriteString("## Commit\n\n")
+		sb.WriteString(fmt.Sprintf("**Repository:** %s/%s\n", request.Owner, request.Repo))
+		sb.WriteString(fmt.Sprintf("**Commit %s on %s:** %s\n\n", target.Head, target.Ref, request.PRTitle))
+	default:
+		sb.WriteString("## Pushed Commits\n\n")
+		sb.WriteString(fmt.Sprintf("**Repository:** %s/%s\n", request.Owner, request.Repo))
+		sb.WriteString(fmt.Sprintf("**Range %s...%s on %s:** %s\n\n", target.Base, target.Head, target.Ref, request.PRTitle))
+	}
 
 	if request.PRBody != "" {
 		sb.WriteString("### Description\n")
@@ -211,7 +347,17 @@ func buildUserMessage(request *models.ReviewRequest) string {
 		sb.WriteString("\n\n")
 	}
 
-	sb.WriteString("### Files Changed\n\n")
+	if len(request.Labels) > 0 {
+		sb.WriteString("### Repository Labels\n\n")
+		for _, label := range request.Labels {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", label))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(request.Files) > 0 {
+		sb.WriteString("### Files Changed\n\n")
+	}
 	for _, file := range request.Files {
 		status := file.Status
 		if status == "" {
@@ -219,13 +365,133 @@ func buildUserMessage(request *models.ReviewRequest) string {
 		}
 		sb.WriteString(fmt.Sprintf("- `%s` (%s, +%d/-%d)\n",
 			file.Filename, status, file.Additions, file.Deletions))
+		for _, hunk := range file.Hunks {
+			if hunk.Symbol != "" {
+				sb.WriteString(fmt.Sprintf("  - hunk at line %d in %s `%s`\n", hunk.FirstChange, hunk.SymbolKind, hunk.Symbol))
+			}
+		}
+	}
+	if len(request.Files) > 0 {
+		sb.WriteString("\n")
+	}
+
+	if len(request.SkippedFiles) > 0 {
+		sb.WriteString("### Skipped Files\n\n")
+		sb.WriteString("These generated, vendored or lock files changed but are left out of the diff; do not review them.\n\n")
+		for _, file := range request.SkippedFiles {
+			sb.WriteString(fmt.Sprintf("- `%s` (+%d/-%d)\n", file.Filename, file.Additions, file.Deletions))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(request.Analysis) > 0 {
+		sb.WriteString("### Static Analysis\n\n")
+		sb.WriteString("These analyzer findings are on changed lines. Check each against the code: if it is a real problem, report it as a finding whose comment starts with the tool and rule in brackets, e.g. `[staticcheck SA4006]`, and explain the impact and fix. Leave out false positives and findings that do not matter in context.\n\n")
+		for _, f := range request.Analysis {
+			tool := f.Tool
+			if f.Rule != "" {
+				tool += " " + f.Rule
+			}
+			sb.WriteString(fmt.Sprintf("- `%s:%d` [%s] (%s) %s\n", f.Path, f.Line, tool, f.Severity, f.Message))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(request.Migrations) > 0 {
+		sb.WriteString("### Migration Checks\n\n")
+		sb.WriteString("Deterministic rules flagged these statements in the changed migrations. Write one finding for each, on its line, judging it in context.\n\n")
+		for _, f := range request.Migrations {
+			sb.WriteString(fmt.Sprintf("- `%s:%d` [%s %s] (%s) %s\n", f.Path, f.Line, f.Tool, f.Rule, f.Severity, f.Message))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(request.Dependencies) > 0 {
+		sb.WriteString("### Dependency Changes\n\n")
+		sb.WriteString("These package changes were parsed from the changed manifests and checked against the OSV vulnerability database and the license allowlist; a table of them is added to the review. Rely on them rather than reading versions from the diff, and do not repeat them as findings. Comment on a manifest only for problems this list cannot show, such as a package that duplicates an existing one or a change that does not match the code.\n\n")
+		for _, change := range request.Dependencies {
+			version := change.NewVersion
+			switch change.Change {
+			case "removed":
+				version = change.OldVersion
+			case "upgraded", "downgraded", "changed":
+				version = change.OldVersion + " → " + change.NewVersion
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` (%s, `%s`) %s %s", change.Name, change.Ecosystem, change.Manifest, change.Change, version))
+			if change.License != "" {
+				sb.WriteString("; license " + change.License)
+				if change.Disallowed {
+					sb.WriteString(" (not allowed)")
+				}
+			}
+			for _, vuln := range change.Vulnerabilities {
+				sb.WriteString(fmt.Sprintf("; %s %s", vuln.ID, vuln.Summary))
+				if vuln.Fixed != "" {
+					sb.WriteString(" (fixed in " + vuln.Fixed + ")")
+				}
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
 	}
-	sb.WriteString("\n")
 
-	sb.WriteString("### Diff\n\n")
-	sb.WriteString("```diff\n")
-	sb.WriteString(request.Diff)
-	sb.WriteString("\n```\n")
+	if len(request.TestTargets) > 0 {
+		sb.WriteString("### Functions Without Tests\n\n")
+		sb.WriteString("No test in the same package refers to these changed functions; write tests for them.\n\n")
+		for _, target := range request.TestTargets {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) in `%s:%d`\n", target.Name, target.Kind, target.Path, target.Line))
+		}
+		sb.WriteString("\n")
+	}
+
+	if request.Diff != "" {
+		sb.WriteString("### Diff\n\n")
+		sb.WriteString("```diff\n")
+		sb.WriteString(request.Diff)
+		sb.WriteString("\n```\n")
+	}
+
+	if request.WorkDir != "" {
+		sb.WriteString("\n### Repository\n\n")
+		sb.WriteString("The repository is checked out at the head commit in your working directory. ")
+		sb.WriteString("Use Read, Grep and Glob to check callers, definitions and tests before reporting an issue. ")
+		sb.WriteString("You cannot run commands or modify files.\n")
+	}
+
+	if len(request.ContextFiles) > 0 {
+		sb.WriteString("\n### File Contents\n\n")
+		if request.Diff == "" {
+			sb.WriteString("Files found by searching the repository for terms in the issue, with line numbers. ")
+			sb.WriteString("They are candidates only; say so when none of them is relevant.\n")
+		} else {
+			sb.WriteString("Full contents at the head commit, with line numbers, so you can see the code around each hunk. ")
+			sb.WriteString("Only comment on lines that are part of the diff.\n")
+		}
+		for _, file := range request.ContextFiles {
+			sb.WriteString(fmt.Sprintf("\n#### `%s` (%s)\n\n", file.Path, file.Reason))
+			sb.WriteString("```" + fenceLanguage(file.Path) + "\n")
+			for i, line := range strings.Split(strings.TrimRight(file.Content, "\n"), "\n") {
+				sb.WriteString(fmt.Sprintf("%4d  %s\n", i+1, line))
+			}
+			sb.WriteString("```\n")
+		}
+	}
+
+	opts := request.Command.Options
+	if opts.Focus != "" {
+		sb.WriteString("\n### Focus\n")
+		sb.WriteString(fmt.Sprintf("Concentrate the review on: %s\n", opts.Focus))
+	}
+
+	if opts.Text != "" {
+		sb.WriteString("\n### Additional Instructions from the Requester\n")
+		sb.WriteString(opts.Text)
+		sb.WriteString("\n")
+	}
+
+	if len(opts.Files) > 0 || len(opts.Exclude) > 0 {
+		sb.WriteString("\n**Note:** The diff was restricted to the paths requested by the user; do not comment on files outside it.\n")
+	}
 
 	if request.Command.Verbose {
 		sb.WriteString("\n**Note:** Verbose mode enabled. Please provide detailed analysis.\n")
@@ -233,3 +499,25 @@ func buildUserMessage(request *models.ReviewRequest) string {
 
 	return sb.String()
 }
+
+// fenceLanguage returns the code fence language for a file's extension
+func fenceLanguage(filename string) string {
+	switch ext := strings.TrimPrefix(filepath.Ext(filename), "."); ext {
+	case "js", "jsx", "mjs":
+		return "javascript"
+	case "ts", "tsx":
+		return "typescript"
+	case "py":
+		return "python"
+	case "rb":
+		return "ruby"
+	case "rs":
+		return "rust"
+	case "yml":
+		return "yaml"
+	case "sh":
+		return "bash"
+	default:
+		return ext
+	}
+}
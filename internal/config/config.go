@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/CREVIOS/revo/pkg/models"
 	"github.com/joho/godotenv"
@@ -84,6 +85,84 @@ func Load() (*models.Config, error) {
 	cfg.DedupEnabled = getEnvBoolOrDefault("DEDUP_ENABLED", true)              // Enable dedup by default
 	cfg.DedupTTLMin = getEnvIntOrDefault("DEDUP_TTL_MIN", 5)                   // 5 minute dedup window
 
+	// Authorization policy configuration
+	cfg.AuthEnabled = getEnvBoolOrDefault("AUTH_ENABLED", true)
+	cfg.AuthMinPermission = getEnvOrDefault("AUTH_MIN_PERMISSION", "write")
+	cfg.AuthAllowOrgMembers = getEnvBoolOrDefault("AUTH_ALLOW_ORG_MEMBERS", false)
+	cfg.AuthRequireApproval = getEnvBoolOrDefault("AUTH_REQUIRE_APPROVAL", false)
+
+	// File context configuration
+	cfg.ContextFilesEnabled = getEnvBoolOrDefault("CONTEXT_FILES_ENABLED", true)
+	cfg.ContextIncludeImports = getEnvBoolOrDefault("CONTEXT_INCLUDE_IMPORTS", false)
+	cfg.ContextMaxTokens = getEnvIntOrDefault("CONTEXT_MAX_TOKENS", 30000)    // ~120KB of source
+	cfg.ContextMaxFileSize = getEnvIntOrDefault("CONTEXT_MAX_FILE_SIZE", 100000) // 100KB per file
+
+	// Static analysis configuration (needs the sandbox)
+	cfg.AnalyzersEnabled = getEnvBoolOrDefault("ANALYZERS_ENABLED", false)
+	cfg.Analyzers = getEnvListOrDefault("ANALYZERS", nil)
+	cfg.AnalyzerCommands = os.Getenv("ANALYZER_COMMANDS")
+	cfg.AnalyzerTimeoutSec = getEnvIntOrDefault("ANALYZER_TIMEOUT_SEC", 180) // 3 minutes per analyzer
+	cfg.AnalyzerMaxFindings = getEnvIntOrDefault("ANALYZER_MAX_FINDINGS", 50)
+
+	// Generated file filtering
+	cfg.SkipGeneratedFiles = getEnvBoolOrDefault("SKIP_GENERATED_FILES", true)
+	cfg.GeneratedFilePatterns = getEnvListOrDefault("GENERATED_FILE_PATTERNS", nil)
+
+	// Sandbox configuration
+	cfg.SandboxEnabled = getEnvBoolOrDefault("SANDBOX_ENABLED", false)
+	cfg.SandboxDir = getEnvOrDefault("SANDBOX_DIR", os.TempDir())
+	cfg.SandboxCloneTimeoutSec = getEnvIntOrDefault("SANDBOX_CLONE_TIMEOUT_SEC", 120) // 2 minutes
+	cfg.SandboxMaxSizeMB = getEnvIntOrDefault("SANDBOX_MAX_SIZE_MB", 500)           // 500 MB
+
+	// Bug verification configuration
+	cfg.VerifyEnabled = getEnvBoolOrDefault("VERIFY_ENABLED", false)
+	cfg.VerifyMode = getEnvOrDefault("VERIFY_MODE", "annotate")
+	cfg.VerifyMinSeverity = getEnvOrDefault("VERIFY_MIN_SEVERITY", "error")
+	cfg.VerifyMaxFindings = getEnvIntOrDefault("VERIFY_MAX_FINDINGS", 3)
+	cfg.VerifyTimeoutSec = getEnvIntOrDefault("VERIFY_TIMEOUT_SEC", 180) // 3 minutes per test
+	cfg.VerifyMemoryMB = getEnvIntOrDefault("VERIFY_MEMORY_MB", 1024)
+	cfg.VerifyCPUs = getEnvOrDefault("VERIFY_CPUS", "1")
+	cfg.VerifyNetwork = getEnvOrDefault("VERIFY_NETWORK", "none")
+	cfg.VerifyImages = os.Getenv("VERIFY_IMAGES")
+	cfg.VerifyGoModCache = os.Getenv("VERIFY_GO_MOD_CACHE")
+	cfg.VerifyDownloadNetwork = getEnvOrDefault("VERIFY_DOWNLOAD_NETWORK", "bridge")
+	cfg.DockerPath = getEnvOrDefault("DOCKER_PATH", "docker")
+
+	// Code scanning upload (needs the security_events: write app permission)
+	cfg.SARIFUploadEnabled = getEnvBoolOrDefault("SARIF_UPLOAD_ENABLED", false)
+	cfg.SARIFUploadModes = getEnvListOrDefault("SARIF_UPLOAD_MODES", []string{"security"})
+
+	// Sticky comment configuration
+	cfg.StickyComments = getEnvBoolOrDefault("STICKY_COMMENTS", true)
+
+	// PR summary configuration
+	cfg.AutoSummarize = getEnvBoolOrDefault("AUTO_SUMMARIZE", false)
+	cfg.SummaryTarget = getEnvOrDefault("SUMMARY_TARGET", "comment")
+
+	// Test generation configuration
+	cfg.TestsDelivery = getEnvOrDefault("TESTS_DELIVERY", "comment")
+
+	// Push review configuration
+	cfg.PushReviewBranches = getEnvListOrDefault("PUSH_REVIEW_BRANCHES", nil)
+	cfg.PushReviewMode = getEnvOrDefault("PUSH_REVIEW_MODE", "review")
+	cfg.CompareReviewOutput = getEnvOrDefault("COMPARE_REVIEW_OUTPUT", "issue")
+
+	// Scheduled audit configuration
+	cfg.AuditSchedules = os.Getenv("AUDIT_SCHEDULES")
+	cfg.AuditMaxFiles = getEnvIntOrDefault("AUDIT_MAX_FILES", 200)
+
+	// Migration check configuration
+	cfg.MigrationPaths = getEnvListOrDefault("MIGRATION_PATHS", nil)
+
+	// Secret scanning configuration
+	cfg.SecretScan = getEnvBoolOrDefault("SECRET_SCAN_ENABLED", true)
+
+	// Dependency review configuration
+	cfg.DependencyReview = getEnvBoolOrDefault("DEPENDENCY_REVIEW_ENABLED", true)
+	cfg.OSVDir = os.Getenv("OSV_DB_DIR")
+	cfg.LicenseFile = os.Getenv("LICENSE_DB_FILE")
+	cfg.LicenseAllowlist = getEnvListOrDefault("LICENSE_ALLOWLIST", nil)
+
 	// Load admin API key
 	cfg.AdminAPIKey = os.Getenv("ADMIN_API_KEY")
 	if cfg.AdminAPIKey == "" {
@@ -93,6 +172,34 @@ func Load() (*models.Config, error) {
 	return cfg, nil
 }
 
+// LoadLocal reads the subset of configuration needed to run reviews locally
+// (eval, review subcommands). Unlike Load it does not require GitHub, database
+// or admin settings.
+func LoadLocal() *models.Config {
+	if err := godotenv.Load(); err != nil {
+		log.Debug().Msg("No .env file found, using environment variables")
+	}
+
+	return &models.Config{
+		BotUsername:       getEnvOrDefault("BOT_USERNAME", "techy"),
+		ClaudePath:        getEnvOrDefault("CLAUDE_PATH", "claude"),
+		ClaudeModel:       getEnvOrDefault("CLAUDE_MODEL", "claude-sonnet-4-20250514"),
+		MaxDiffSize:       getEnvIntOrDefault("MAX_DIFF_SIZE", 100000),
+		RetryMaxAttempts:  getEnvIntOrDefault("RETRY_MAX_ATTEMPTS", 5),
+		RetryInitialDelay: getEnvIntOrDefault("RETRY_INITIAL_DELAY_MS", 1000),
+		RetryMaxDelay:     getEnvIntOrDefault("RETRY_MAX_DELAY_MS", 60000),
+
+		AnalyzersEnabled:    getEnvBoolOrDefault("ANALYZERS_ENABLED", false),
+		Analyzers:           getEnvListOrDefault("ANALYZERS", nil),
+		AnalyzerCommands:    os.Getenv("ANALYZER_COMMANDS"),
+		AnalyzerTimeoutSec:  getEnvIntOrDefault("ANALYZER_TIMEOUT_SEC", 180),
+		AnalyzerMaxFindings: getEnvIntOrDefault("ANALYZER_MAX_FINDINGS", 50),
+
+		SkipGeneratedFiles:    getEnvBoolOrDefault("SKIP_GENERATED_FILES", true),
+		GeneratedFilePatterns: getEnvListOrDefault("GENERATED_FILE_PATTERNS", nil),
+	}
+}
+
 // getEnvBoolOrDefault returns the environment variable as bool or a default
 func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -128,3 +235,19 @@ func getEnvInt64OrDefault(key string, defaultValue int64) int64 {
 	}
 	return defaultValue
 }
+
+// getEnvListOrDefault returns a comma separated environment variable as a list or a default
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
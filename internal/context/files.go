@@ -0,0 +1,286 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/google/go-github/v60/github"
+	"github.com/rs/zerolog/log"
+)
+
+// Reasons a file is included as context
+const (
+	ReasonChanged  = "changed"
+	ReasonImported = "imported"
+)
+
+// charsPerToken is a rough estimate used to keep file contents within budget
+const charsPerToken = 4
+
+// FileContextConfig limits how much file content is sent with a review
+type FileContextConfig struct {
+	MaxTokens      int  // approximate budget for all file contents
+	MaxFileSize    int  // skip files larger than this many bytes
+	IncludeImports bool // also fetch files imported by changed files
+}
+
+// FileContextGatherer fetches full file contents at the PR head for the prompt
+type FileContextGatherer struct {
+	githubClient *gh.Client
+	config       FileContextConfig
+}
+
+// NewFileContextGatherer creates a new file context gatherer
+func NewFileContextGatherer(githubClient *gh.Client, config FileContextConfig) *FileContextGatherer {
+	return &FileContextGatherer{
+		githubClient: githubClient,
+		config:       config,
+	}
+}
+
+// GatherFiles returns the post-change contents of changed files, then of files
+// they import, until the token budget is spent. Failures skip the file.
+func (g *FileContextGatherer) GatherFiles(ctx context.Context, owner, repo, ref string, files []models.PRFile) ([]models.ContextFile, error) {
+	client, err := g.githubClient.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+	}
+
+	fetcher := &contentFetcher{client: client, owner: owner, repo: repo, ref: ref}
+	budget := g.config.MaxTokens * charsPerToken
+	seen := map[string]bool{}
+	var result []models.ContextFile
+
+	add := func(filePath, content, reason string) bool {
+		if seen[filePath] {
+			return false
+		}
+		seen[filePath] = true
+		if len(content) > budget {
+			log.Debug().Str("file", filePath).Int("size", len(content)).Msg("File exceeds remaining context budget, skipping")
+			return false
+		}
+		budget -= len(content)
+		result = append(result, models.ContextFile{Path: filePath, Content: content, Reason: reason})
+		return true
+	}
+
+	// Most-changed files first, so the budget goes where the review will look hardest
+	changed := make([]models.PRFile, 0, len(files))
+	for _, file := range files {
+		if file.Status != "removed" {
+			changed = append(changed, file)
+		}
+	}
+	sort.SliceStable(changed, func(i, j int) bool { return changed[i].Changes > changed[j].Changes })
+
+	var included []models.ContextFile
+	for _, file := range changed {
+		content, ok := fetcher.file(ctx, file.Filename, g.config.MaxFileSize)
+		if !ok {
+			continue
+		}
+		if add(file.Filename, content, ReasonChanged) {
+			included = append(included, result[len(result)-1])
+		}
+	}
+
+	if g.config.IncludeImports {
+		for _, file := range included {
+			for _, imported := range fetcher.imports(ctx, file.Path, file.Content) {
+				if budget <= 0 {
+					break
+				}
+				if seen[imported] {
+					continue
+				}
+				content, ok := fetcher.file(ctx, imported, g.config.MaxFileSize)
+				if !ok {
+					seen[imported] = true
+					continue
+				}
+				add(imported, content, ReasonImported)
+			}
+		}
+	}
+
+	log.Info().
+		Int("files", len(result)).
+		Int("tokens", (g.config.MaxTokens*charsPerToken-budget)/charsPerToken).
+		Msg("Gathered file contents for review")
+
+	return result, nil
+}
+
+// contentFetcher reads files and directories at a fixed ref
+type contentFetcher struct {
+	client      *github.Client
+	owner, repo string
+	ref         string
+
+	files map[string]string // fetched text files; "" marks a miss
+
+	goModule       string
+	goModuleLoaded bool
+}
+
+// file returns a text file's content, or false if it is missing, binary or too large
+func (f *contentFetcher) file(ctx context.Context, filePath string, maxSize int) (string, bool) {
+	if content, ok := f.files[filePath]; ok {
+		return content, content != "" && (maxSize <= 0 || len(content) <= maxSize)
+	}
+	content, ok := f.fetch(ctx, filePath, maxSize)
+	if f.files == nil {
+		f.files = map[string]string{}
+	}
+	f.files[filePath] = content
+	return content, ok
+}
+
+// fetch reads a file through the contents API
+func (f *contentFetcher) fetch(ctx context.Context, filePath string, maxSize int) (string, bool) {
+	fileContent, _, resp, err := f.client.Repositories.GetContents(ctx, f.owner, f.repo, filePath,
+		&github.RepositoryContentGetOptions{Ref: f.ref})
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			log.Warn().Err(err).Str("file", filePath).Msg("Failed to fetch file contents")
+		}
+		return "", false
+	}
+	if fileContent == nil || (maxSize > 0 && fileContent.GetSize() > maxSize) {
+		return "", false
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil || strings.ContainsRune(content, 0) {
+		return "", false
+	}
+	return content, true
+}
+
+// dir lists the files in a directory
+func (f *contentFetcher) dir(ctx context.Context, dirPath string) []string {
+	_, entries, _, err := f.client.Repositories.GetContents(ctx, f.owner, f.repo, dirPath,
+		&github.RepositoryContentGetOptions{Ref: f.ref})
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.GetType() == "file" {
+			names = append(names, entry.GetPath())
+		}
+	}
+	return names
+}
+
+var (
+	jsImportPattern = regexp.MustCompile(`(?:from\s+|import\s+|require\(\s*)['"](\.{1,2}/[^'"]+)['"]`)
+	pyImportPattern = regexp.MustCompile(`(?m)^\s*from\s+(\.+)([\w.]*)\s+import\s+([\w, ]+)`)
+	jsExtensions    = []string{"", ".ts", ".tsx", ".js", ".jsx", ".mjs", "/index.ts", "/index.tsx", "/index.js"}
+)
+
+// imports resolves repository files imported by a file. Go imports within the
+// module and relative JavaScript, TypeScript and Python imports are supported.
+func (f *contentFetcher) imports(ctx context.Context, filePath, content string) []string {
+	dir := path.Dir(filePath)
+
+	switch path.Ext(filePath) {
+	case ".go":
+		return f.goImports(ctx, filePath, content)
+
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs":
+		var resolved []string
+		for _, match := range jsImportPattern.FindAllStringSubmatch(content, -1) {
+			base := path.Join(dir, match[1])
+			for _, ext := range jsExtensions {
+				candidate := base + ext
+				if _, ok := f.file(ctx, candidate, 0); ok {
+					resolved = append(resolved, candidate)
+					break
+				}
+			}
+		}
+		return resolved
+
+	case ".py":
+		var resolved []string
+		for _, match := range pyImportPattern.FindAllStringSubmatch(content, -1) {
+			base := dir
+			for i := 1; i < len(match[1]); i++ {
+				base = path.Dir(base)
+			}
+			if match[2] != "" {
+				resolved = append(resolved, path.Join(base, strings.ReplaceAll(match[2], ".", "/")+".py"))
+				continue
+			}
+			// from . import a, b imports sibling modules
+			for _, name := range strings.Split(match[3], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					resolved = append(resolved, path.Join(base, name+".py"))
+				}
+			}
+		}
+		return resolved
+	}
+
+	return nil
+}
+
+// goImports returns the non-test files of packages in the same module
+func (f *contentFetcher) goImports(ctx context.Context, filePath, content string) []string {
+	parsed, err := parser.ParseFile(token.NewFileSet(), filePath, content, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+
+	module := f.module(ctx)
+	if module == "" {
+		return nil
+	}
+
+	var resolved []string
+	for _, spec := range parsed.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || !strings.HasPrefix(importPath, module+"/") {
+			continue
+		}
+		for _, name := range f.dir(ctx, strings.TrimPrefix(importPath, module+"/")) {
+			if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+				resolved = append(resolved, name)
+			}
+		}
+	}
+	return resolved
+}
+
+// module reads the module path from the repository's root go.mod
+func (f *contentFetcher) module(ctx context.Context) string {
+	if f.goModuleLoaded {
+		return f.goModule
+	}
+	f.goModuleLoaded = true
+
+	content, ok := f.file(ctx, "go.mod", 0)
+	if !ok {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			f.goModule = strings.Trim(fields[1], `"`)
+			break
+		}
+	}
+	return f.goModule
+}
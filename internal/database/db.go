@@ -48,6 +48,9 @@ func Connect(dsn string) (*gorm.DB, error) {
 		&WebhookEvent{},
 		&WorkerMetrics{},
 		&APIKey{},
+		&AuditLog{},
+		&CustomMode{},
+		&PromptTemplate{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate: %w", err)
 	}
@@ -20,8 +20,15 @@ type Review struct {
 	PRTitle   string `json:"pr_title"`
 	CommitSHA string `gorm:"index" json:"commit_sha"`
 
+	// Target of reviews not made on a pull request, where PRNumber is 0
+	TargetKind  string `gorm:"index" json:"target_kind,omitempty"` // commit, compare, audit or issue
+	BaseSHA     string `json:"base_sha,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	IssueNumber int    `gorm:"index" json:"issue_number,omitempty"` // plain issue of a triage or explain
+
 	// Review Details
 	Mode           string `gorm:"index;not null" json:"mode"`   // hunt, security, performance, etc.
+	PromptVersion  string `gorm:"index" json:"prompt_version"`  // e.g. hunt@v3, or "default" for the built-in prompt
 	Status         string `gorm:"index;not null" json:"status"` // queued, processing, completed, failed, cancelled
 	BugsFound      int    `json:"bugs_found"`
 	CommentsPosted int    `json:"comments_posted"`
@@ -40,8 +47,12 @@ type Review struct {
 	RetryCount   int    `gorm:"default:0" json:"retry_count"`
 
 	// User Information
-	RequestedBy string `json:"requested_by"` // GitHub username who triggered review
-	WorkerID    string `json:"worker_id"`    // Which worker processed this
+	RequestedBy string `json:"requested_by"`         // GitHub username who triggered review
+	WorkerID    string `json:"worker_id"`            // Which worker processed this
+	TaskID      string `gorm:"index" json:"task_id"` // Asynq task ID of the review task
+
+	// ApprovalRequestedAt is set once the bot has asked a maintainer to approve the review
+	ApprovalRequestedAt *time.Time `json:"approval_requested_at,omitempty"`
 
 	// Relationships
 	Comments []ReviewComment `gorm:"foreignKey:ReviewID" json:"comments,omitempty"`
@@ -60,6 +71,18 @@ type ReviewComment struct {
 	Category string `json:"category"` // bug, security, performance, etc.
 	Body     string `gorm:"type:text;not null" json:"body"`
 
+	// Verification by running a generated test against the head commit
+	Verification       string `gorm:"index" json:"verification,omitempty"` // verified, not_reproduced, inconclusive
+	VerificationOutput string `gorm:"type:text" json:"verification_output,omitempty"`
+
+	// Static analyzer the finding confirms, e.g. staticcheck
+	Source string `gorm:"index" json:"source,omitempty"`
+
+	// Suggested change committed by `@techy fix`
+	FixedAt      *time.Time `gorm:"index" json:"fixed_at,omitempty"`
+	FixedBy      string     `json:"fixed_by,omitempty"`
+	FixCommitSHA string     `json:"fix_commit_sha,omitempty"`
+
 	// GitHub metadata
 	GitHubCommentID int64 `gorm:"index" json:"github_comment_id,omitempty"`
 }
@@ -87,6 +110,76 @@ type Repository struct {
 	AutoReviewEnabled bool   `gorm:"default:false" json:"auto_review_enabled"`
 	DefaultMode       string `gorm:"default:'hunt'" json:"default_mode"`
 	CustomRules       string `gorm:"type:text" json:"custom_rules,omitempty"`
+	PromptPins        string `json:"prompt_pins,omitempty"` // comma separated mode=version pairs, e.g. hunt=3,review=default
+
+	// Authorization policy (empty / nil values inherit the global settings)
+	AllowedUsers    string `gorm:"type:text" json:"allowed_users,omitempty"` // comma separated logins always allowed
+	DeniedUsers     string `gorm:"type:text" json:"denied_users,omitempty"`  // comma separated logins always denied
+	MinPermission   string `json:"min_permission,omitempty"`                 // read, triage, write, maintain, admin
+	AllowOrgMembers *bool  `json:"allow_org_members,omitempty"`
+	RequireApproval *bool  `json:"require_approval,omitempty"` // first-time contributors need maintainer approval
+}
+
+// CustomMode is a user-defined review mode, triggered with @bot <name>
+type CustomMode struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name  string `gorm:"uniqueIndex:idx_mode_scope;not null" json:"name"` // command word, e.g. a11y
+	Owner string `gorm:"uniqueIndex:idx_mode_scope" json:"owner"`         // empty for every repository
+	Repo  string `gorm:"uniqueIndex:idx_mode_scope" json:"repo"`          // empty for every repository of Owner
+
+	Emoji        string `json:"emoji"`
+	Description  string `gorm:"not null" json:"description"`
+	SystemPrompt string `gorm:"type:text;not null" json:"system_prompt"`
+	IsActive     bool   `gorm:"default:true;index" json:"is_active"`
+
+	// Default options, used when the command does not set them
+	DefaultFiles       string `json:"default_files,omitempty"`   // comma separated globs
+	DefaultExclude     string `json:"default_exclude,omitempty"` // comma separated globs
+	DefaultModel       string `json:"default_model,omitempty"`
+	DefaultMinSeverity string `json:"default_min_severity,omitempty"`
+	DefaultFocus       string `json:"default_focus,omitempty"`
+	DefaultVerbose     bool   `gorm:"default:false" json:"default_verbose"`
+
+	// Disabled explains why a stored mode is not in use, e.g. its name is taken by a built-in mode
+	Disabled string `gorm:"-" json:"disabled,omitempty"`
+}
+
+// PromptTemplate is a versioned text/template for a mode's system prompt
+type PromptTemplate struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Mode     string `gorm:"uniqueIndex:idx_prompt_mode_version;not null" json:"mode"`
+	Version  int    `gorm:"uniqueIndex:idx_prompt_mode_version;not null" json:"version"`
+	Template string `gorm:"type:text;not null" json:"template"`
+	Notes    string `gorm:"type:text" json:"notes,omitempty"`
+
+	// Share of PRs (0-100) that use this version when the repository has no pin
+	RolloutPercent int  `gorm:"default:0" json:"rollout_percent"`
+	IsActive       bool `gorm:"default:true;index" json:"is_active"`
+}
+
+// AuditLog records authorization decisions for review requests
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Owner    string `gorm:"index;not null" json:"owner"`
+	Repo     string `gorm:"index;not null" json:"repo"`
+	PRNumber int    `gorm:"index" json:"pr_number"`
+	Actor    string `gorm:"index;not null" json:"actor"`    // GitHub login of the requester
+	Command  string `json:"command"`                        // raw command text
+	Decision string `gorm:"index;not null" json:"decision"` // allowed, denied, pending_approval, approved
+	Reason   string `gorm:"type:text" json:"reason"`
+
+	ReviewID       *uint `gorm:"index" json:"review_id,omitempty"`
+	WebhookEventID *uint `gorm:"index" json:"webhook_event_id,omitempty"`
 }
 
 // WebhookEvent tracks all webhook events received
@@ -94,16 +187,20 @@ type WebhookEvent struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 
-	EventType string `gorm:"index;not null" json:"event_type"` // issue_comment, pull_request, etc.
-	Owner     string `gorm:"index;not null" json:"owner"`
-	Repo      string `gorm:"index;not null" json:"repo"`
-	PRNumber  int    `gorm:"index" json:"pr_number"`
-	Action    string `json:"action"` // opened, synchronize, created, etc.
-
-	Payload     string     `gorm:"type:jsonb" json:"payload,omitempty"`
-	ProcessedAt *time.Time `json:"processed_at,omitempty"`
-	ReviewID    *uint      `gorm:"index" json:"review_id,omitempty"`
-	Signature   string     `json:"signature,omitempty"`
+	EventType  string `gorm:"index;not null" json:"event_type"`   // issue_comment, pull_request, etc.
+	DeliveryID string `gorm:"index" json:"delivery_id,omitempty"` // X-GitHub-Delivery header
+	Owner      string `gorm:"index;not null" json:"owner"`
+	Repo       string `gorm:"index;not null" json:"repo"`
+	PRNumber   int    `gorm:"index" json:"pr_number"`
+	Action     string `json:"action"` // opened, synchronize, created, etc.
+
+	Payload      string     `gorm:"type:jsonb" json:"payload,omitempty"`
+	Status       string     `gorm:"index;default:'received'" json:"status"` // received, processed, failed
+	ErrorMessage string     `gorm:"type:text" json:"error_message,omitempty"`
+	Attempts     int        `gorm:"default:0" json:"attempts"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+	ReviewID     *uint      `gorm:"index" json:"review_id,omitempty"`
+	Signature    string     `json:"signature,omitempty"`
 }
 
 // WorkerMetrics tracks worker performance
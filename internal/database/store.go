@@ -1,6 +1,8 @@
 package database
 
 import (
+	"errors"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -33,11 +35,73 @@ func (s *Store) UpdateReview(id uint, updates map[string]interface{}) error {
 	return s.db.Model(&Review{}).Where("id = ?", id).Updates(updates).Error
 }
 
+// GetReview loads a review by ID.
+func (s *Store) GetReview(id uint) (*Review, error) {
+	var review Review
+	if err := s.db.First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListActiveReviews returns queued and processing reviews for a PR, or for a
+// plain issue when issueNumber is set, oldest first.
+// When requestedBy is non-empty only that user's reviews are returned.
+func (s *Store) ListActiveReviews(owner, repo string, prNumber, issueNumber int, requestedBy string) ([]Review, error) {
+	query := s.db.Where("owner = ? AND repo = ? AND pr_number = ? AND issue_number = ? AND status IN ?",
+		owner, repo, prNumber, issueNumber, []string{"queued", "processing"})
+	if requestedBy != "" {
+		query = query.Where("requested_by = ?", requestedBy)
+	}
+
+	var reviews []Review
+	if err := query.Order("id asc").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
 // CreateReviewComment inserts a new review comment record.
 func (s *Store) CreateReviewComment(comment *ReviewComment) error {
 	return s.db.Create(comment).Error
 }
 
+// UpdateReviewComment updates a review comment by ID.
+func (s *Store) UpdateReviewComment(id uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.db.Model(&ReviewComment{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindReviewComment returns the stored finding behind an inline comment the bot
+// posted on a PR, by its GitHub comment ID or else by its location and text.
+func (s *Store) FindReviewComment(owner, repo string, prNumber int, githubCommentID int64, path string, line int, body string) (*ReviewComment, error) {
+	query := func() *gorm.DB {
+		return s.db.Model(&ReviewComment{}).
+			Joins("JOIN reviews ON reviews.id = review_comments.review_id").
+			Where("reviews.owner = ? AND reviews.repo = ? AND reviews.pr_number = ?", owner, repo, prNumber).
+			Order("review_comments.id desc")
+	}
+
+	var comment ReviewComment
+	err := query().Where("review_comments.github_comment_id = ?", githubCommentID).First(&comment).Error
+	if err == nil {
+		return &comment, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = query().
+		Where("review_comments.file_path = ? AND review_comments.line = ? AND review_comments.body = ?", path, line, body).
+		First(&comment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
 // UpsertRepository creates or updates a repository record.
 func (s *Store) UpsertRepository(repo *Repository) error {
 	return s.db.Clauses(clause.OnConflict{
@@ -55,3 +119,93 @@ func (s *Store) UpsertRepository(repo *Repository) error {
 func (s *Store) CreateWebhookEvent(event *WebhookEvent) error {
 	return s.db.Create(event).Error
 }
+
+// GetWebhookEvent loads a webhook event by ID.
+func (s *Store) GetWebhookEvent(id uint) (*WebhookEvent, error) {
+	var event WebhookEvent
+	if err := s.db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// FindWebhookEventByDelivery looks up a webhook event by its GitHub delivery ID.
+func (s *Store) FindWebhookEventByDelivery(deliveryID string) (*WebhookEvent, error) {
+	var event WebhookEvent
+	if err := s.db.Where("delivery_id = ?", deliveryID).Order("id desc").First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// UpdateWebhookEvent updates a webhook event by ID.
+func (s *Store) UpdateWebhookEvent(id uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.db.Model(&WebhookEvent{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GetRepository loads a repository by owner and name.
+func (s *Store) GetRepository(owner, name string) (*Repository, error) {
+	var repo Repository
+	if err := s.db.Where("owner = ? AND name = ?", owner, name).First(&repo).Error; err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// CreateAuditLog inserts an authorization audit record.
+func (s *Store) CreateAuditLog(entry *AuditLog) error {
+	return s.db.Create(entry).Error
+}
+
+// ListReviewsByStatus returns a PR's reviews in the given status, or a plain
+// issue's when issueNumber is set, oldest first.
+func (s *Store) ListReviewsByStatus(owner, repo string, prNumber, issueNumber int, status string) ([]Review, error) {
+	var reviews []Review
+	err := s.db.Where("owner = ? AND repo = ? AND pr_number = ? AND issue_number = ? AND status = ?", owner, repo, prNumber, issueNumber, status).
+		Order("id asc").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// FindWebhookEventByReview returns the webhook event that created a review.
+func (s *Store) FindWebhookEventByReview(reviewID uint) (*WebhookEvent, error) {
+	var event WebhookEvent
+	if err := s.db.Where("review_id = ?", reviewID).Order("id asc").First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ListActiveCustomModes returns all enabled user-defined review modes.
+func (s *Store) ListActiveCustomModes() ([]CustomMode, error) {
+	var modes []CustomMode
+	err := s.db.Where("is_active = ?", true).Order("name asc").Find(&modes).Error
+	return modes, err
+}
+
+// ListActivePromptTemplates returns a mode's active prompt templates, lowest version first.
+func (s *Store) ListActivePromptTemplates(mode string) ([]PromptTemplate, error) {
+	var templates []PromptTemplate
+	err := s.db.Where("mode = ? AND is_active = ?", mode, true).Order("version asc").Find(&templates).Error
+	return templates, err
+}
+
+// GetPromptTemplate loads a specific version of a mode's prompt template.
+func (s *Store) GetPromptTemplate(mode string, version int) (*PromptTemplate, error) {
+	var template PromptTemplate
+	if err := s.db.Where("mode = ? AND version = ?", mode, version).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// NextPromptVersion returns the version number for a new template of mode.
+func (s *Store) NextPromptVersion(mode string) (int, error) {
+	var latest int
+	err := s.db.Unscoped().Model(&PromptTemplate{}).Where("mode = ?", mode).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+	return latest + 1, err
+}
@@ -77,6 +77,11 @@ func RequestKeyWithMode(owner, repo string, prNumber int, commitSHA, mode string
 	return fmt.Sprintf("review:%s/%s/%d:%s:%s", owner, repo, prNumber, commitSHA, mode)
 }
 
+// IssueKey generates a key for a command on a plain issue
+func IssueKey(owner, repo string, issueNumber int, commentID int64, mode string) string {
+	return fmt.Sprintf("issue:%s/%s/%d:%d:%s", owner, repo, issueNumber, commentID, mode)
+}
+
 // CheckAndMark attempts to mark a request as in-progress
 // Returns (isDuplicate, waitChan) where:
 // - isDuplicate: true if this is a duplicate request
@@ -0,0 +1,148 @@
+package deps
+
+import (
+	"sort"
+
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Config configures the dependency review
+type Config struct {
+	OSVDir           string   // OSV export files; empty skips the vulnerability check
+	LicenseFile      string   // JSON license database; empty leaves licenses unknown
+	LicenseAllowlist []string // SPDX licenses packages may use; empty skips the check
+}
+
+// Manifest is a changed manifest's contents before and after the change; Old
+// is empty for an added manifest and New for a removed one
+type Manifest struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// Checker lists the package changes in manifests and checks them
+type Checker struct {
+	db       *DB
+	licenses *Licenses
+}
+
+// NewChecker loads the configured vulnerability and license databases
+func NewChecker(config Config) (*Checker, error) {
+	c := &Checker{}
+	if config.OSVDir != "" {
+		db, err := LoadDB(config.OSVDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Info().Int("advisories", db.Len()).Str("dir", config.OSVDir).Msg("Loaded OSV database")
+		c.db = db
+	}
+	licenses, err := LoadLicenses(config.LicenseFile, config.LicenseAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	c.licenses = licenses
+	return c, nil
+}
+
+// Check lists the packages added, removed and changed in each manifest, with
+// the license and known vulnerabilities of the new versions
+func (c *Checker) Check(manifests []Manifest) []models.DependencyChange {
+	var changes []models.DependencyChange
+	for _, manifest := range manifests {
+		before, err := parseSide(manifest.Path, manifest.Old)
+		if err != nil {
+			log.Warn().Err(err).Str("manifest", manifest.Path).Msg("Failed to parse old manifest")
+			continue
+		}
+		after, err := parseSide(manifest.Path, manifest.New)
+		if err != nil {
+			log.Warn().Err(err).Str("manifest", manifest.Path).Msg("Failed to parse new manifest")
+			continue
+		}
+
+		for _, change := range Diff(before, after) {
+			change.Manifest = manifest.Path
+			if change.Change != "removed" {
+				c.checkPackage(&change)
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// checkPackage fills in the license and vulnerabilities of a change's new version
+func (c *Checker) checkPackage(change *models.DependencyChange) {
+	if c.licenses != nil {
+		license, allowed := c.licenses.Check(change.Ecosystem, change.Name)
+		change.License = license
+		change.Disallowed = !allowed
+	}
+	if c.db != nil && change.Pinned {
+		change.Vulnerabilities = c.db.Lookup(change.Ecosystem, change.Name, change.NewVersion)
+		change.Checked = true
+	}
+}
+
+// parseSide parses one side of a manifest change; an empty side has no packages
+func parseSide(p, content string) ([]Package, error) {
+	if content == "" {
+		return nil, nil
+	}
+	return Parse(p, content)
+}
+
+// Diff lists the packages added, removed and changed between two versions of
+// a manifest, sorted by name
+func Diff(before, after []Package) []models.DependencyChange {
+	old := make(map[string]Package, len(before))
+	for _, pkg := range before {
+		old[pkg.Name] = pkg
+	}
+	current := make(map[string]bool, len(after))
+
+	var changes []models.DependencyChange
+	for _, pkg := range after {
+		current[pkg.Name] = true
+		change := models.DependencyChange{
+			Ecosystem:  pkg.Ecosystem,
+			Name:       pkg.Name,
+			NewVersion: pkg.Version,
+			Pinned:     pkg.Exact,
+		}
+		prev, ok := old[pkg.Name]
+		switch {
+		case !ok:
+			change.Change = "added"
+		case prev.Version == pkg.Version:
+			continue
+		default:
+			change.OldVersion = prev.Version
+			switch c := compareVersions(baseVersion(prev.Version), baseVersion(pkg.Version)); {
+			case c < 0:
+				change.Change = "upgraded"
+			case c > 0:
+				change.Change = "downgraded"
+			default:
+				change.Change = "changed"
+			}
+		}
+		changes = append(changes, change)
+	}
+	for _, pkg := range before {
+		if !current[pkg.Name] {
+			changes = append(changes, models.DependencyChange{
+				Ecosystem:  pkg.Ecosystem,
+				Name:       pkg.Name,
+				Change:     "removed",
+				OldVersion: pkg.Version,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -0,0 +1,75 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Licenses looks up package licenses in a local database and checks them
+// against an allowlist
+type Licenses struct {
+	known   map[string]string // SPDX expression by ecosystem and normalized name
+	allowed map[string]bool   // lower-cased SPDX identifiers
+}
+
+// LoadLicenses reads a JSON object mapping "ecosystem:name" to an SPDX
+// expression, e.g. {"npm:left-pad": "WTFPL", "Go:golang.org/x/net": "BSD-3-Clause"}.
+// An empty file leaves every license unknown.
+func LoadLicenses(file string, allowlist []string) (*Licenses, error) {
+	l := &Licenses{known: map[string]string{}, allowed: map[string]bool{}}
+	for _, id := range allowlist {
+		l.allowed[strings.ToLower(strings.TrimSpace(id))] = true
+	}
+	if file == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read license database: %w", err)
+	}
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse license database: %w", err)
+	}
+	for key, license := range entries {
+		ecosystem, name, ok := strings.Cut(key, ":")
+		if !ok {
+			return nil, fmt.Errorf("license database key %q is not ecosystem:name", key)
+		}
+		l.known[ecosystem+":"+NormalizeName(ecosystem, name)] = license
+	}
+	return l, nil
+}
+
+// Check returns a package's license and whether the allowlist permits it.
+// Unknown licenses and an empty allowlist are allowed.
+func (l *Licenses) Check(ecosystem, name string) (string, bool) {
+	license := l.known[ecosystem+":"+NormalizeName(ecosystem, name)]
+	if license == "" || len(l.allowed) == 0 {
+		return license, true
+	}
+	return license, l.permits(license)
+}
+
+// permits evaluates an SPDX expression: one OR alternative must have all of
+// its AND terms on the allowlist. Exceptions after WITH are not checked.
+func (l *Licenses) permits(expression string) bool {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	for _, alternative := range strings.Split(expression, " OR ") {
+		permitted := true
+		for _, term := range strings.Split(alternative, " AND ") {
+			id, _, _ := strings.Cut(strings.TrimSpace(term), " WITH ")
+			if !l.allowed[strings.ToLower(strings.TrimSpace(id))] {
+				permitted = false
+				break
+			}
+		}
+		if permitted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// OSV ecosystem names of the supported manifests
+const (
+	EcosystemGo    = "Go"
+	EcosystemNPM   = "npm"
+	EcosystemPyPI  = "PyPI"
+	EcosystemCrate = "crates.io"
+)
+
+// Package is a dependency declared in a manifest
+type Package struct {
+	Ecosystem string
+	Name      string
+	Version   string // as written: one release or a range
+	Exact     bool   // Version names one release
+}
+
+var (
+	exactVersionPattern = regexp.MustCompile(`^=?v?\d+(\.\d+)*([-+][0-9A-Za-z.+-]+)?$`)
+	requirementPattern  = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+	pythonNamePattern   = regexp.MustCompile(`[-_.]+`)
+	cargoEntryPattern   = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=\s*(.+)$`)
+	cargoVersionPattern = regexp.MustCompile(`version\s*=\s*"([^"]*)"`)
+)
+
+// Supported reports whether p is a manifest the dependency review parses
+func Supported(p string) bool {
+	return parser(p) != nil
+}
+
+// Parse reads the packages declared in the manifest at p
+func Parse(p, content string) ([]Package, error) {
+	parse := parser(p)
+	if parse == nil {
+		return nil, fmt.Errorf("unsupported manifest %s", p)
+	}
+	return parse(content)
+}
+
+// parser returns the parser for the manifest at p, or nil
+func parser(p string) func(string) ([]Package, error) {
+	base := path.Base(p)
+	switch {
+	case base == "go.mod":
+		return parseGoMod
+	case base == "package.json":
+		return parsePackageJSON
+	case base == "Cargo.toml":
+		return parseCargo
+	case strings.HasPrefix(base, "requirements") && path.Ext(base) == ".txt":
+		return parseRequirements
+	}
+	return nil
+}
+
+// parseGoMod reads the require directives of a go.mod
+func parseGoMod(content string) ([]Package, error) {
+	var packages []Package
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		if len(fields) != 2 {
+			continue
+		}
+		packages = append(packages, Package{
+			Ecosystem: EcosystemGo,
+			Name:      strings.Trim(fields[0], `"`),
+			Version:   fields[1],
+			Exact:     true,
+		})
+	}
+	return packages, nil
+}
+
+// parsePackageJSON reads the dependency maps of a package.json
+func parsePackageJSON(content string) ([]Package, error) {
+	var manifest struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var packages []Package
+	for _, group := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies, manifest.PeerDependencies} {
+		for name, version := range group {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			version = strings.TrimSpace(version)
+			packages = append(packages, Package{
+				Ecosystem: EcosystemNPM,
+				Name:      name,
+				Version:   version,
+				Exact:     exactVersionPattern.MatchString(version),
+			})
+		}
+	}
+	return packages, nil
+}
+
+// parseRequirements reads a pip requirements file; only == pins are exact
+func parseRequirements(content string) ([]Package, error) {
+	var packages []Package
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, " #")
+		line, _, _ = strings.Cut(line, ";")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		match := requirementPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		spec := strings.ReplaceAll(match[3], " ", "")
+		pkg := Package{Ecosystem: EcosystemPyPI, Name: NormalizeName(EcosystemPyPI, match[1]), Version: spec}
+		if version, ok := strings.CutPrefix(spec, "=="); ok && !strings.ContainsAny(version, ",*") {
+			pkg.Version = version
+			pkg.Exact = true
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// parseCargo reads the dependency tables of a Cargo.toml; only = requirements are exact
+func parseCargo(content string) ([]Package, error) {
+	var packages []Package
+	inTable := false
+	tableName := ""
+	add := func(name, version string) {
+		packages = append(packages, Package{
+			Ecosystem: EcosystemCrate,
+			Name:      name,
+			Version:   version,
+			Exact:     strings.HasPrefix(version, "=") && exactVersionPattern.MatchString(version),
+		})
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			header := strings.Trim(line, "[]")
+			// [dependencies.serde] declares one dependency as a table
+			tableName = ""
+			inTable = strings.HasSuffix(header, "dependencies")
+			if i := strings.LastIndex(header, "dependencies."); i >= 0 && !inTable {
+				tableName = header[i+len("dependencies."):]
+			}
+			continue
+		}
+		if tableName != "" {
+			if match := cargoVersionPattern.FindStringSubmatch(line); match != nil && strings.HasPrefix(line, "version") {
+				add(tableName, match[1])
+				tableName = ""
+			}
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		match := cargoEntryPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		value := strings.TrimSpace(match[2])
+		if strings.HasPrefix(value, `"`) {
+			add(match[1], strings.Trim(value, `"`))
+		} else if version := cargoVersionPattern.FindStringSubmatch(value); version != nil {
+			add(match[1], version[1])
+		}
+	}
+	return packages, nil
+}
+
+// NormalizeName folds a package name the way its registry compares names
+func NormalizeName(ecosystem, name string) string {
+	switch ecosystem {
+	case EcosystemPyPI:
+		return pythonNamePattern.ReplaceAllString(strings.ToLower(name), "-")
+	case EcosystemNPM, EcosystemCrate:
+		return strings.ToLower(name)
+	}
+	return name
+}
@@ -0,0 +1,145 @@
+package deps
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []Package
+		wantErr bool
+	}{
+		{
+			name: "go.mod",
+			path: "go.mod",
+			content: `module example.com/app
+
+go 1.22
+
+require github.com/pkg/errors v0.9.1
+
+require (
+	golang.org/x/net v0.20.0 // indirect
+	"github.com/quoted/mod" v1.0.0
+)
+
+replace github.com/pkg/errors => ../errors
+`,
+			want: []Package{
+				{Ecosystem: EcosystemGo, Name: "github.com/pkg/errors", Version: "v0.9.1", Exact: true},
+				{Ecosystem: EcosystemGo, Name: "golang.org/x/net", Version: "v0.20.0", Exact: true},
+				{Ecosystem: EcosystemGo, Name: "github.com/quoted/mod", Version: "v1.0.0", Exact: true},
+			},
+		},
+		{
+			name: "package.json",
+			path: "web/package.json",
+			content: `{
+  "dependencies": {"react": "18.2.0", "lodash": "^4.17.21"},
+  "devDependencies": {"typescript": "~5.3.0", "react": "17.0.0"},
+  "peerDependencies": {"left-pad": "=1.3.0"}
+}`,
+			want: []Package{
+				{Ecosystem: EcosystemNPM, Name: "left-pad", Version: "=1.3.0", Exact: true},
+				{Ecosystem: EcosystemNPM, Name: "lodash", Version: "^4.17.21"},
+				{Ecosystem: EcosystemNPM, Name: "react", Version: "18.2.0", Exact: true},
+				{Ecosystem: EcosystemNPM, Name: "typescript", Version: "~5.3.0"},
+			},
+		},
+		{
+			name:    "invalid package.json",
+			path:    "package.json",
+			content: "{",
+			wantErr: true,
+		},
+		{
+			name: "requirements.txt",
+			path: "requirements-dev.txt",
+			content: `# tools
+-r base.txt
+Django==4.2.7
+requests[security] >= 2.31, < 3  # http
+zope.interface==6.*
+Flask_Login
+numpy==1.26.2; python_version >= "3.9"
+git+https://github.com/org/repo.git
+`,
+			want: []Package{
+				{Ecosystem: EcosystemPyPI, Name: "django", Version: "4.2.7", Exact: true},
+				{Ecosystem: EcosystemPyPI, Name: "requests", Version: ">=2.31,<3"},
+				{Ecosystem: EcosystemPyPI, Name: "zope-interface", Version: "==6.*"},
+				{Ecosystem: EcosystemPyPI, Name: "flask-login", Version: ""},
+				{Ecosystem: EcosystemPyPI, Name: "numpy", Version: "1.26.2", Exact: true},
+			},
+		},
+		{
+			name: "Cargo.toml",
+			path: "Cargo.toml",
+			content: `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = "1.0"
+tokio = { version = "=1.35.1", features = ["full"] }
+local = { path = "../local" }
+
+[dev-dependencies]
+insta = "=1.34.0" # snapshots
+
+[dependencies.regex]
+default-features = false
+version = "1.10"
+`,
+			want: []Package{
+				{Ecosystem: EcosystemCrate, Name: "serde", Version: "1.0"},
+				{Ecosystem: EcosystemCrate, Name: "tokio", Version: "=1.35.1", Exact: true},
+				{Ecosystem: EcosystemCrate, Name: "insta", Version: "=1.34.0", Exact: true},
+				{Ecosystem: EcosystemCrate, Name: "regex", Version: "1.10"},
+			},
+		},
+		{
+			name:    "unsupported manifest",
+			path:    "pom.xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.path, tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.path == "web/package.json" {
+				// JSON objects are unordered
+				sort.Slice(got, func(i, j int) bool { return got[i].Name < got[j].Name })
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, want string
+	}{
+		{EcosystemPyPI, "Zope.Interface", "zope-interface"},
+		{EcosystemPyPI, "flask__login", "flask-login"},
+		{EcosystemNPM, "@Scope/Pkg", "@scope/pkg"},
+		{EcosystemCrate, "Serde_JSON", "serde_json"},
+		{EcosystemGo, "github.com/BurntSushi/toml", "github.com/BurntSushi/toml"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.ecosystem, tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q, %q) = %q, want %q", tt.ecosystem, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package deps
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// advisory is the part of an OSV record the lookup needs
+// (https://ossf.github.io/osv-schema/)
+type advisory struct {
+	ID        string     `json:"id"`
+	Aliases   []string   `json:"aliases"`
+	Summary   string     `json:"summary"`
+	Withdrawn string     `json:"withdrawn"`
+	Affected  []affected `json:"affected"`
+
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string  `json:"type"`
+		Events []event `json:"events"`
+	} `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+type event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// version is the version an event is at
+func (e event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	}
+	return e.LastAffected
+}
+
+// entry is one affected package of an advisory
+type entry struct {
+	advisory *advisory
+	affected affected
+}
+
+// DB is an in-memory index of OSV advisories for the supported ecosystems
+type DB struct {
+	entries map[string][]entry // by ecosystem and normalized package name
+	count   int
+}
+
+// LoadDB reads the OSV export under dir: advisory JSON files, and the
+// all.zip archives OSV publishes per ecosystem, in any layout
+func LoadDB(dir string) (*DB, error) {
+	db := &DB{entries: map[string][]entry{}}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(p) {
+		case ".json":
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return db.add(p, data)
+		case ".zip":
+			return db.addArchive(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OSV database: %w", err)
+	}
+	return db, nil
+}
+
+// addArchive indexes the advisories in an OSV all.zip
+func (db *DB) addArchive(p string) error {
+	archive, err := zip.OpenReader(p)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		if err := db.add(p+":"+file.Name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add indexes one advisory under each supported package it affects
+func (db *DB) add(name string, data []byte) error {
+	adv := &advisory{}
+	if err := json.Unmarshal(data, adv); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if adv.Withdrawn != "" {
+		return nil
+	}
+	indexed := false
+	for _, a := range adv.Affected {
+		ecosystem := a.Package.Ecosystem
+		if ecosystem != EcosystemGo && ecosystem != EcosystemNPM && ecosystem != EcosystemPyPI && ecosystem != EcosystemCrate {
+			continue
+		}
+		key := ecosystem + ":" + NormalizeName(ecosystem, a.Package.Name)
+		db.entries[key] = append(db.entries[key], entry{advisory: adv, affected: a})
+		indexed = true
+	}
+	if indexed {
+		db.count++
+	}
+	return nil
+}
+
+// Len is the number of advisories indexed
+func (db *DB) Len() int {
+	return db.count
+}
+
+// Lookup returns the advisories affecting one release of a package
+func (db *DB) Lookup(ecosystem, name, version string) []models.Vulnerability {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "="), "v")
+	seen := map[string]bool{}
+	var vulns []models.Vulnerability
+	for _, e := range db.entries[ecosystem+":"+NormalizeName(ecosystem, name)] {
+		fixed, ok := e.affected.affects(version)
+		if !ok || seen[e.advisory.ID] {
+			continue
+		}
+		seen[e.advisory.ID] = true
+		vulns = append(vulns, models.Vulnerability{
+			ID:       e.advisory.ID,
+			Aliases:  e.advisory.Aliases,
+			Summary:  e.advisory.Summary,
+			Severity: strings.ToLower(e.advisory.DatabaseSpecific.Severity),
+			Fixed:    fixed,
+		})
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].ID < vulns[j].ID })
+	return vulns
+}
+
+// affects reports whether version is affected, and the first release that
+// fixes it. Ranges are evaluated the way the OSV schema describes: events are
+// sorted by version and each introduced event opens an affected interval that
+// the next fixed or last_affected event closes. GIT ranges are ignored.
+func (a affected) affects(version string) (string, bool) {
+	for _, v := range a.Versions {
+		if strings.TrimPrefix(v, "v") == version {
+			return "", true
+		}
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		events := append([]event(nil), r.Events...)
+		sort.SliceStable(events, func(i, j int) bool {
+			vi, vj := events[i].version(), events[j].version()
+			if vi == "0" || vj == "0" {
+				return vi == "0" && vj != "0"
+			}
+			return compareVersions(vi, vj) < 0
+		})
+
+		affected := false
+		fixed := ""
+		for _, e := range events {
+			switch {
+			case e.Introduced != "":
+				if e.Introduced == "0" || compareVersions(version, e.Introduced) >= 0 {
+					affected = true
+				}
+			case e.Fixed != "":
+				if compareVersions(version, e.Fixed) >= 0 {
+					affected = false
+				} else if affected && fixed == "" {
+					fixed = e.Fixed
+				}
+			case e.LastAffected != "":
+				if compareVersions(version, e.LastAffected) > 0 {
+					affected = false
+				}
+			}
+			if fixed != "" {
+				break
+			}
+		}
+		if affected {
+			return fixed, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package deps
+
+import (
+	"strconv"
+	"strings"
+)
+
+// baseVersion strips range operators, so "^1.2.3" and ">=1.2.3" compare as 1.2.3
+func baseVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if i := strings.IndexAny(version, ", |"); i >= 0 {
+		version = version[:i]
+	}
+	return strings.TrimLeft(version, "^~<>=!v ")
+}
+
+// compareVersions orders two versions by their dot-separated parts, numerically
+// where both parts are numbers. A prerelease sorts before its release and
+// build metadata is ignored. This follows semver and covers the common cases
+// of the other ecosystems' version schemes.
+func compareVersions(a, b string) int {
+	a, aPre := splitVersion(a)
+	b, bPre := splitVersion(b)
+	if c := compareParts(a, b); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareParts(aPre, bPre)
+}
+
+// splitVersion separates a version's release from its prerelease
+func splitVersion(version string) (string, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	release, pre, _ := strings.Cut(version, "-")
+	// PyPI writes prereleases without a separator, e.g. 2.0rc1
+	for i, r := range release {
+		if r != '.' && (r < '0' || r > '9') {
+			return release[:i], release[i:] + pre
+		}
+	}
+	return release, pre
+}
+
+// compareParts compares dot-separated parts; missing parts count as zero
+func compareParts(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) && as[i] != "" {
+			x = as[i]
+		}
+		if i < len(bs) && bs[i] != "" {
+			y = bs[i]
+		}
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case xErr == nil:
+			// Numeric parts sort before alphanumeric ones
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+package deps
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-beta", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0+build.5", "1.0.0+build.1", 0},
+		{"2.0rc1", "2.0", -1},
+		{"2.0rc1", "2.0rc2", -1},
+		{"v0.0.0-20240101000000-abcdef", "v0.0.0-20250101000000-012345", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := compareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := compareVersions(tt.b, tt.a); got != -tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3":            "1.2.3",
+		"^1.2.3":           "1.2.3",
+		"~1.2":             "1.2",
+		">=1.2.3":          "1.2.3",
+		">=1.2.3,<2":       "1.2.3",
+		"^1.0.0 || ^2.0.0": "1.0.0",
+		" v0.4.1 ":         "0.4.1",
+		"==2.31.0":         "2.31.0",
+	}
+	for in, want := range tests {
+		if got := baseVersion(in); got != want {
+			t.Errorf("baseVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
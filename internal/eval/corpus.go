@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Corpus file names inside each case directory
+const (
+	caseFile     = "case.json"
+	diffFile     = "diff.patch"
+	responseFile = "response.md"
+)
+
+// defaultLineTolerance is how far a finding may be from the labelled line and still match
+const defaultLineTolerance = 3
+
+// Case is one recorded PR diff with its labelled bugs
+type Case struct {
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	Mode     string        `json:"mode,omitempty"`
+	PRTitle  string        `json:"pr_title,omitempty"`
+	PRBody   string        `json:"pr_body,omitempty"`
+	Expected []ExpectedBug `json:"expected"`
+
+	Dir      string `json:"-"`
+	Diff     string `json:"-"`
+	Response string `json:"-"` // recorded model output, if any
+}
+
+// ExpectedBug is a labelled defect the review should report
+type ExpectedBug struct {
+	File          string   `json:"file"`
+	Line          int      `json:"line"`
+	LineTolerance int      `json:"line_tolerance,omitempty"` // defaults to 3
+	Keywords      []string `json:"keywords,omitempty"`       // any one must appear in the finding
+	Severity      string   `json:"severity,omitempty"`
+	Description   string   `json:"description,omitempty"`
+}
+
+// tolerance returns the allowed line distance for a match
+func (b ExpectedBug) tolerance() int {
+	if b.LineTolerance > 0 {
+		return b.LineTolerance
+	}
+	return defaultLineTolerance
+}
+
+// LoadCorpus reads every case directory under dir, sorted by ID.
+// Each case holds case.json, diff.patch and optionally a recorded response.md.
+func LoadCorpus(dir string) ([]*Case, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus %s: %w", dir, err)
+	}
+
+	var cases []*Case
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		c, err := loadCase(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("corpus %s has no cases", dir)
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].ID < cases[j].ID })
+	return cases, nil
+}
+
+// loadCase reads a single case directory
+func loadCase(dir string) (*Case, error) {
+	data, err := os.ReadFile(filepath.Join(dir, caseFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, caseFile), err)
+	}
+
+	var c Case
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, caseFile), err)
+	}
+	if c.ID == "" {
+		c.ID = filepath.Base(dir)
+	}
+	c.Dir = dir
+
+	diff, err := os.ReadFile(filepath.Join(dir, diffFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read diff for case %s: %w", c.ID, err)
+	}
+	c.Diff = string(diff)
+
+	if response, err := os.ReadFile(filepath.Join(dir, responseFile)); err == nil {
+		c.Response = string(response)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read recorded response for case %s: %w", c.ID, err)
+	}
+
+	for i, bug := range c.Expected {
+		if bug.File == "" || bug.Line <= 0 {
+			return nil, fmt.Errorf("case %s: expected bug %d needs a file and a positive line", c.ID, i+1)
+		}
+		c.Expected[i].File = strings.TrimPrefix(bug.File, "/")
+	}
+
+	return &c, nil
+}
+
+// SaveResponse records a model response next to the case for later replay
+func (c *Case) SaveResponse(response string) error {
+	if err := os.WriteFile(filepath.Join(c.Dir, responseFile), []byte(response), 0o644); err != nil {
+		return fmt.Errorf("failed to record response for case %s: %w", c.ID, err)
+	}
+	c.Response = response
+	return nil
+}
@@ -0,0 +1,268 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/claude"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/internal/prompts"
+	"github.com/CREVIOS/revo/internal/retry"
+	"github.com/CREVIOS/revo/internal/review"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Backends
+const (
+	BackendRecorded = "recorded" // replay response.md from each case
+	BackendCLI      = "cli"      // run the Claude Code CLI
+)
+
+// Options configure an evaluation run
+type Options struct {
+	Corpus      string
+	Backend     string
+	Record      bool   // save CLI responses as response.md for later replay
+	Model       string // overrides the configured model
+	Mode        string // overrides each case's mode
+	PromptFile  string // text/template used instead of the mode's prompt
+	MinSeverity string
+	Filter      string // only run cases whose ID contains this
+	Timeout     time.Duration
+}
+
+// Run implements `techy eval`
+func Run(cfg *models.Config, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	opts := Options{}
+	fs.StringVar(&opts.Corpus, "corpus", "eval/corpus", "directory of cases (case.json, diff.patch, response.md)")
+	fs.StringVar(&opts.Backend, "backend", BackendRecorded, "recorded (replay response.md) or cli (run Claude Code)")
+	fs.BoolVar(&opts.Record, "record", false, "with -backend cli, save responses as response.md")
+	fs.StringVar(&opts.Model, "model", "", "model override (default CLAUDE_MODEL)")
+	fs.StringVar(&opts.Mode, "mode", "", "review mode for every case (default: each case's mode)")
+	fs.StringVar(&opts.PromptFile, "prompt", "", "prompt template file to evaluate instead of the built-in prompt")
+	fs.StringVar(&opts.MinSeverity, "min-severity", "", "only score findings at error, warning or info and above")
+	fs.StringVar(&opts.Filter, "case", "", "only run cases whose ID contains this text")
+	fs.DurationVar(&opts.Timeout, "timeout", 5*time.Minute, "timeout per case")
+	format := fs.String("format", "markdown", "report format: markdown or json")
+	out := fs.String("out", "", "write the report to this file instead of stdout")
+	baselinePath := fs.String("baseline", "", "JSON report of a previous run to compare against (markdown only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unknown format %q (use markdown or json)", *format)
+	}
+
+	var baseline *Report
+	if *baselinePath != "" {
+		var err error
+		if baseline, err = LoadReport(*baselinePath); err != nil {
+			return err
+		}
+	}
+
+	report, err := Evaluate(context.Background(), cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	var output []byte
+	if *format == "json" {
+		if output, err = report.JSON(); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		output = append(output, '\n')
+	} else {
+		output = []byte(report.Markdown(baseline))
+	}
+
+	if *out != "" {
+		if err := os.WriteFile(*out, output, 0o644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		log.Info().Str("path", *out).Msg("Evaluation report written")
+		return nil
+	}
+
+	_, err = stdout.Write(output)
+	return err
+}
+
+// Evaluate runs every case in the corpus through the review pipeline and scores it
+func Evaluate(ctx context.Context, cfg *models.Config, opts Options) (*Report, error) {
+	if opts.Backend != BackendRecorded && opts.Backend != BackendCLI {
+		return nil, fmt.Errorf("unknown backend %q (use %s or %s)", opts.Backend, BackendRecorded, BackendCLI)
+	}
+	if opts.Record && opts.Backend != BackendCLI {
+		return nil, errors.New("-record requires -backend cli")
+	}
+	if opts.Mode != "" {
+		if _, ok := modes.Lookup("", "", models.ReviewMode(opts.Mode)); !ok {
+			return nil, fmt.Errorf("unknown mode %q", opts.Mode)
+		}
+	}
+
+	cases, err := LoadCorpus(opts.Corpus)
+	if err != nil {
+		return nil, err
+	}
+
+	model := cfg.ClaudeModel
+	if opts.Model != "" {
+		model = opts.Model
+	}
+
+	report := &Report{
+		GeneratedAt: time.Now().UTC(),
+		Corpus:      opts.Corpus,
+		Backend:     opts.Backend,
+		Model:       model,
+		Mode:        opts.Mode,
+		MinSeverity: opts.MinSeverity,
+		Summary:     Metrics{},
+	}
+
+	var promptTemplate string
+	if opts.PromptFile != "" {
+		data, err := os.ReadFile(opts.PromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		promptTemplate = string(data)
+		if err := prompts.Validate(promptTemplate); err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		report.PromptFile = opts.PromptFile
+		report.PromptHash = hex.EncodeToString(sum[:])[:12]
+	}
+
+	for _, c := range cases {
+		if opts.Filter != "" && !strings.Contains(c.ID, opts.Filter) {
+			continue
+		}
+
+		result := runCase(ctx, cfg, opts, model, promptTemplate, c)
+		if result.Error != "" {
+			log.Warn().Str("case", c.ID).Str("error", result.Error).Msg("Evaluation case failed")
+			report.Summary.Errors++
+			report.Summary.Cases++
+		} else {
+			report.Summary.Add(result.Metrics)
+		}
+		report.Cases = append(report.Cases, result)
+	}
+
+	if len(report.Cases) == 0 {
+		return nil, fmt.Errorf("no cases match %q", opts.Filter)
+	}
+
+	return report, nil
+}
+
+// runCase reviews one case and scores the parsed findings
+func runCase(ctx context.Context, cfg *models.Config, opts Options, model, promptTemplate string, c *Case) (result CaseResult) {
+	mode := models.ReviewMode(c.Mode)
+	if opts.Mode != "" {
+		mode = models.ReviewMode(opts.Mode)
+	}
+	if mode == "" {
+		mode = models.ModeHunt
+	}
+
+	result = CaseResult{ID: c.ID, Title: c.Title, Mode: string(mode)}
+	start := time.Now()
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	if opts.Backend == BackendRecorded && c.Response == "" {
+		result.Error = fmt.Sprintf("no recorded %s; run with -backend cli -record first", responseFile)
+		return result
+	}
+
+	diff := c.Diff
+	if cfg.MaxDiffSize > 0 && len(diff) > cfg.MaxDiffSize {
+		diff = gh.TruncateDiff(diff, cfg.MaxDiffSize)
+	}
+
+	request := &models.ReviewRequest{
+		Owner:    "eval",
+		Repo:     c.ID,
+		PRNumber: 1,
+		Command: models.Command{
+			Mode:    mode,
+			Raw:     fmt.Sprintf("@%s %s", cfg.BotUsername, mode),
+			Options: models.CommandOptions{Model: model, MinSeverity: opts.MinSeverity},
+		},
+		Diff:    diff,
+		PRTitle: c.PRTitle,
+		PRBody:  c.PRBody,
+		Files:   gh.ParseDiff(c.Diff),
+	}
+
+	if promptTemplate != "" {
+		prompt, err := prompts.Render(promptTemplate, prompts.Data{
+			Mode:          string(mode),
+			Owner:         request.Owner,
+			Repo:          request.Repo,
+			PRNumber:      request.PRNumber,
+			PRTitle:       request.PRTitle,
+			DefaultPrompt: claude.GetSystemPrompt(request.Owner, request.Repo, mode),
+		})
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		request.SystemPrompt = prompt
+	}
+
+	clientOpts := []claude.ClientOption{
+		claude.WithCacheEnabled(false),
+		claude.WithRetryConfig(retry.Config{
+			MaxRetries:     cfg.RetryMaxAttempts,
+			InitialDelay:   time.Duration(cfg.RetryInitialDelay) * time.Millisecond,
+			MaxDelay:       time.Duration(cfg.RetryMaxDelay) * time.Millisecond,
+			Multiplier:     2.0,
+			JitterFraction: 0.3,
+		}),
+	}
+	if opts.Backend == BackendRecorded {
+		clientOpts = append(clientOpts, claude.WithBackend(claude.BackendFunc(
+			func(context.Context, string, string) (string, error) {
+				return c.Response, nil
+			})))
+	}
+	client := claude.NewClient(cfg.ClaudePath, model, clientOpts...)
+
+	caseCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	response, err := client.ReviewCode(caseCtx, request)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if opts.Record {
+		if err := c.SaveResponse(response); err != nil {
+			log.Warn().Err(err).Msg("Failed to record response")
+		}
+	}
+
+	_, comments := review.ParseStructuredReview(response)
+	comments = review.FilterBySeverity(comments, opts.MinSeverity)
+
+	result.Metrics, result.Matches, result.Missed, result.FalsePositives = Score(c.Expected, toFindings(comments))
+	return result
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report is the result of an evaluation run
+type Report struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Corpus      string       `json:"corpus"`
+	Backend     string       `json:"backend"`
+	Model       string       `json:"model"`
+	Mode        string       `json:"mode,omitempty"` // mode override, if any
+	PromptFile  string       `json:"prompt_file,omitempty"`
+	PromptHash  string       `json:"prompt_hash,omitempty"`
+	MinSeverity string       `json:"min_severity,omitempty"`
+	Summary     Metrics      `json:"summary"`
+	Cases       []CaseResult `json:"cases"`
+}
+
+// CaseResult is the outcome of one case
+type CaseResult struct {
+	ID             string        `json:"id"`
+	Title          string        `json:"title"`
+	Mode           string        `json:"mode"`
+	DurationMs     int64         `json:"duration_ms"`
+	Error          string        `json:"error,omitempty"`
+	Metrics        Metrics       `json:"metrics"`
+	Matches        []Match       `json:"matches,omitempty"`
+	Missed         []ExpectedBug `json:"missed,omitempty"`
+	FalsePositives []Finding     `json:"false_positives,omitempty"`
+}
+
+// LoadReport reads a JSON report written by a previous run
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// JSON renders the report as indented JSON
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown renders the report, with deltas against baseline when given
+func (r *Report) Markdown(baseline *Report) string {
+	var sb strings.Builder
+
+	sb.WriteString("# TechyBot Evaluation Report\n\n")
+	sb.WriteString(fmt.Sprintf("- **Generated:** %s\n", r.GeneratedAt.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("- **Corpus:** `%s` (%d cases)\n", r.Corpus, r.Summary.Cases))
+	sb.WriteString(fmt.Sprintf("- **Backend:** %s\n", r.Backend))
+	sb.WriteString(fmt.Sprintf("- **Model:** %s\n", r.Model))
+	if r.Mode != "" {
+		sb.WriteString(fmt.Sprintf("- **Mode:** %s\n", r.Mode))
+	}
+	if r.PromptFile != "" {
+		sb.WriteString(fmt.Sprintf("- **Prompt:** `%s` (%s)\n", r.PromptFile, r.PromptHash))
+	}
+	if r.MinSeverity != "" {
+		sb.WriteString(fmt.Sprintf("- **Min severity:** %s\n", r.MinSeverity))
+	}
+
+	sb.WriteString("\n## Summary\n\n")
+	if baseline != nil {
+		sb.WriteString("| Metric | This run | Baseline | Δ |\n")
+		sb.WriteString("|--------|----------|----------|---|\n")
+		writeRate(&sb, "Recall", r.Summary.Recall, baseline.Summary.Recall)
+		writeRate(&sb, "Precision", r.Summary.Precision, baseline.Summary.Precision)
+		writeRate(&sb, "F1", r.Summary.F1, baseline.Summary.F1)
+		writeRate(&sb, "Line accuracy", r.Summary.LineAccuracy, baseline.Summary.LineAccuracy)
+		sb.WriteString(fmt.Sprintf("| Mean line error | %.2f | %.2f | %+.2f |\n",
+			r.Summary.MeanLineError, baseline.Summary.MeanLineError, r.Summary.MeanLineError-baseline.Summary.MeanLineError))
+		sb.WriteString(fmt.Sprintf("| False positives | %d | %d | %+d |\n",
+			r.Summary.FalsePositives, baseline.Summary.FalsePositives, r.Summary.FalsePositives-baseline.Summary.FalsePositives))
+	} else {
+		sb.WriteString("| Metric | Value |\n")
+		sb.WriteString("|--------|-------|\n")
+		sb.WriteString(fmt.Sprintf("| Recall | %s (%d/%d) |\n", percent(r.Summary.Recall), r.Summary.TruePositives, r.Summary.Expected))
+		sb.WriteString(fmt.Sprintf("| Precision | %s (%d/%d) |\n", percent(r.Summary.Precision), r.Summary.MatchedFindings, r.Summary.Findings))
+		sb.WriteString(fmt.Sprintf("| F1 | %s |\n", percent(r.Summary.F1)))
+		sb.WriteString(fmt.Sprintf("| Line accuracy | %s |\n", percent(r.Summary.LineAccuracy)))
+		sb.WriteString(fmt.Sprintf("| Mean line error | %.2f |\n", r.Summary.MeanLineError))
+		sb.WriteString(fmt.Sprintf("| False positives | %d |\n", r.Summary.FalsePositives))
+	}
+	if r.Summary.Errors > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️ %d case(s) failed to run and are excluded from the scores.\n", r.Summary.Errors))
+	}
+
+	sb.WriteString("\n## Cases\n\n")
+	sb.WriteString("| Case | Mode | Recall | Precision | Line acc. | FP | Time |\n")
+	sb.WriteString("|------|------|--------|-----------|-----------|----|------|\n")
+	for _, c := range r.Cases {
+		if c.Error != "" {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | ❌ error | | | | %s |\n", c.ID, c.Mode, formatDuration(c.DurationMs)))
+			continue
+		}
+		lineAccuracy := "n/a"
+		if c.Metrics.TruePositives > 0 {
+			lineAccuracy = percent(c.Metrics.LineAccuracy)
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s | %d | %s |\n",
+			c.ID, c.Mode,
+			percent(c.Metrics.Recall), percent(c.Metrics.Precision), lineAccuracy,
+			c.Metrics.FalsePositives, formatDuration(c.DurationMs)))
+	}
+
+	var details strings.Builder
+	for _, c := range r.Cases {
+		if c.Error == "" && len(c.Missed) == 0 && len(c.FalsePositives) == 0 {
+			continue
+		}
+		details.WriteString(fmt.Sprintf("\n### `%s`", c.ID))
+		if c.Title != "" {
+			details.WriteString(" - " + c.Title)
+		}
+		details.WriteString("\n\n")
+		if c.Error != "" {
+			details.WriteString(fmt.Sprintf("- ❌ %s\n", c.Error))
+		}
+		for _, bug := range c.Missed {
+			details.WriteString(fmt.Sprintf("- Missed `%s:%d`", bug.File, bug.Line))
+			if bug.Description != "" {
+				details.WriteString(" - " + bug.Description)
+			}
+			details.WriteString("\n")
+		}
+		for _, f := range c.FalsePositives {
+			details.WriteString(fmt.Sprintf("- False positive `%s:%d`: %s\n", f.File, f.Line, firstLine(f.Body)))
+		}
+	}
+	if details.Len() > 0 {
+		sb.WriteString("\n## Details\n")
+		sb.WriteString(details.String())
+	}
+
+	return sb.String()
+}
+
+// writeRate writes a comparison row for a rate metric
+func writeRate(sb *strings.Builder, name string, current, baseline float64) {
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s | %+.1f pp |\n", name, percent(current), percent(baseline), (current-baseline)*100))
+}
+
+// percent formats a rate as a percentage
+func percent(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
+
+// formatDuration renders milliseconds compactly
+func formatDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(10 * time.Millisecond).String()
+}
+
+// firstLine returns the first line of text, trimmed for table output
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(line); len(runes) > 120 {
+		line = string(runes[:117]) + "..."
+	}
+	return line
+}
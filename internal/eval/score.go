@@ -0,0 +1,212 @@
+package eval
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// Finding is a parsed inline comment from the review
+type Finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Body     string `json:"body"`
+}
+
+// Match pairs a labelled bug with the finding that reported it
+type Match struct {
+	Expected  ExpectedBug `json:"expected"`
+	Finding   Finding     `json:"finding"`
+	LineError int         `json:"line_error"`
+}
+
+// Metrics are the scores for a case or a whole run
+type Metrics struct {
+	Cases           int     `json:"cases"`
+	Errors          int     `json:"errors"`
+	Expected        int     `json:"expected"`
+	Findings        int     `json:"findings"`
+	TruePositives   int     `json:"true_positives"`   // labelled bugs that were reported
+	FalseNegatives  int     `json:"false_negatives"`  // labelled bugs that were missed
+	MatchedFindings int     `json:"matched_findings"` // findings that point at a labelled bug
+	FalsePositives  int     `json:"false_positives"`  // findings that point at nothing labelled
+	ExactLines      int     `json:"exact_lines"`      // matches on exactly the labelled line
+	LineErrorSum    int     `json:"line_error_sum"`
+	Recall          float64 `json:"recall"`
+	Precision       float64 `json:"precision"`
+	F1              float64 `json:"f1"`
+	LineAccuracy    float64 `json:"line_accuracy"`   // share of matches on the exact line
+	MeanLineError   float64 `json:"mean_line_error"` // average distance of matches from the labelled line
+}
+
+// Score matches findings against the labelled bugs of a case.
+// Each labelled bug is credited to at most one finding, closest line first.
+// A finding counts towards precision if it is within tolerance of any labelled bug.
+func Score(expected []ExpectedBug, findings []Finding) (Metrics, []Match, []ExpectedBug, []Finding) {
+	type candidate struct {
+		expected, finding, distance int
+	}
+
+	var candidates []candidate
+	relevant := make([]bool, len(findings))
+	for ei, bug := range expected {
+		for fi, finding := range findings {
+			if distance, ok := matches(bug, finding); ok {
+				candidates = append(candidates, candidate{ei, fi, distance})
+				relevant[fi] = true
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	expectedUsed := make([]bool, len(expected))
+	findingUsed := make([]bool, len(findings))
+	var matched []Match
+	for _, c := range candidates {
+		if expectedUsed[c.expected] || findingUsed[c.finding] {
+			continue
+		}
+		expectedUsed[c.expected] = true
+		findingUsed[c.finding] = true
+		matched = append(matched, Match{
+			Expected:  expected[c.expected],
+			Finding:   findings[c.finding],
+			LineError: c.distance,
+		})
+	}
+
+	var missed []ExpectedBug
+	for i, bug := range expected {
+		if !expectedUsed[i] {
+			missed = append(missed, bug)
+		}
+	}
+
+	var falsePositives []Finding
+	for i, finding := range findings {
+		if !relevant[i] {
+			falsePositives = append(falsePositives, finding)
+		}
+	}
+
+	m := Metrics{
+		Cases:           1,
+		Expected:        len(expected),
+		Findings:        len(findings),
+		TruePositives:   len(matched),
+		FalseNegatives:  len(missed),
+		MatchedFindings: len(findings) - len(falsePositives),
+		FalsePositives:  len(falsePositives),
+	}
+	for _, match := range matched {
+		if match.LineError == 0 {
+			m.ExactLines++
+		}
+		m.LineErrorSum += match.LineError
+	}
+	m.finalize()
+
+	return m, matched, missed, falsePositives
+}
+
+// Add accumulates another case's counts into m and recomputes the rates
+func (m *Metrics) Add(other Metrics) {
+	m.Cases += other.Cases
+	m.Errors += other.Errors
+	m.Expected += other.Expected
+	m.Findings += other.Findings
+	m.TruePositives += other.TruePositives
+	m.FalseNegatives += other.FalseNegatives
+	m.MatchedFindings += other.MatchedFindings
+	m.FalsePositives += other.FalsePositives
+	m.ExactLines += other.ExactLines
+	m.LineErrorSum += other.LineErrorSum
+	m.finalize()
+}
+
+// finalize derives the rates from the counts.
+// Recall and precision are 1 when there is nothing to find or nothing was reported.
+func (m *Metrics) finalize() {
+	m.Recall = ratio(m.TruePositives, m.Expected)
+	m.Precision = ratio(m.MatchedFindings, m.Findings)
+	if m.Recall+m.Precision > 0 {
+		m.F1 = 2 * m.Recall * m.Precision / (m.Recall + m.Precision)
+	} else {
+		m.F1 = 0
+	}
+	if m.TruePositives > 0 {
+		m.LineAccuracy = float64(m.ExactLines) / float64(m.TruePositives)
+		m.MeanLineError = float64(m.LineErrorSum) / float64(m.TruePositives)
+	} else {
+		m.LineAccuracy = 0
+		m.MeanLineError = 0
+	}
+}
+
+// matches reports whether a finding reports bug, and its line distance
+func matches(bug ExpectedBug, finding Finding) (int, bool) {
+	if !samePath(bug.File, finding.File) {
+		return 0, false
+	}
+
+	distance := bug.Line - finding.Line
+	if distance < 0 {
+		distance = -distance
+	}
+	if distance > bug.tolerance() {
+		return 0, false
+	}
+
+	if len(bug.Keywords) > 0 {
+		body := strings.ToLower(finding.Body)
+		found := false
+		for _, keyword := range bug.Keywords {
+			if strings.Contains(body, strings.ToLower(keyword)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	return distance, true
+}
+
+// samePath compares repository paths, tolerating a/ b/ or ./ prefixes on either side
+func samePath(expected, reported string) bool {
+	clean := func(p string) string {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "./")
+		for _, prefix := range []string{"a/", "b/"} {
+			p = strings.TrimPrefix(p, prefix)
+		}
+		return strings.TrimPrefix(p, "/")
+	}
+	e, r := clean(expected), clean(reported)
+	return e == r || strings.HasSuffix(r, "/"+e) || strings.HasSuffix(e, "/"+r)
+}
+
+// toFindings converts parsed review comments into findings
+func toFindings(comments []models.ReviewComment) []Finding {
+	findings := make([]Finding, 0, len(comments))
+	for _, c := range comments {
+		findings = append(findings, Finding{
+			File:     c.Path,
+			Line:     c.Line,
+			Severity: c.Severity,
+			Body:     c.Body,
+		})
+	}
+	return findings
+}
+
+// ratio returns n/d, or 1 when d is zero
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,161 @@
+package eval
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name           string
+		expected       []ExpectedBug
+		findings       []Finding
+		truePositives  int
+		falseNegatives int
+		falsePositives int
+		exactLines     int
+		lineErrorSum   int
+		recall         float64
+		precision      float64
+	}{
+		{
+			name:      "nothing expected nothing found",
+			recall:    1,
+			precision: 1,
+		},
+		{
+			name:          "exact match",
+			expected:      []ExpectedBug{{File: "main.go", Line: 10}},
+			findings:      []Finding{{File: "main.go", Line: 10}},
+			truePositives: 1,
+			exactLines:    1,
+			recall:        1,
+			precision:     1,
+		},
+		{
+			name:          "within default tolerance with diff prefix",
+			expected:      []ExpectedBug{{File: "pkg/main.go", Line: 10}},
+			findings:      []Finding{{File: "b/pkg/main.go", Line: 13}},
+			truePositives: 1,
+			lineErrorSum:  3,
+			recall:        1,
+			precision:     1,
+		},
+		{
+			name:           "outside tolerance",
+			expected:       []ExpectedBug{{File: "main.go", Line: 10}},
+			findings:       []Finding{{File: "main.go", Line: 14}},
+			falseNegatives: 1,
+			falsePositives: 1,
+		},
+		{
+			name:          "custom tolerance",
+			expected:      []ExpectedBug{{File: "main.go", Line: 10, LineTolerance: 5}},
+			findings:      []Finding{{File: "main.go", Line: 15}},
+			truePositives: 1,
+			lineErrorSum:  5,
+			recall:        1,
+			precision:     1,
+		},
+		{
+			name:           "wrong file",
+			expected:       []ExpectedBug{{File: "main.go", Line: 10}},
+			findings:       []Finding{{File: "other.go", Line: 10}},
+			falseNegatives: 1,
+			falsePositives: 1,
+		},
+		{
+			name:           "keyword required",
+			expected:       []ExpectedBug{{File: "main.go", Line: 10, Keywords: []string{"nil", "panic"}}},
+			findings:       []Finding{{File: "main.go", Line: 10, Body: "style nit"}},
+			falseNegatives: 1,
+			falsePositives: 1,
+		},
+		{
+			name:          "keyword case insensitive",
+			expected:      []ExpectedBug{{File: "main.go", Line: 10, Keywords: []string{"nil"}}},
+			findings:      []Finding{{File: "main.go", Line: 10, Body: "NIL dereference"}},
+			truePositives: 1,
+			exactLines:    1,
+			recall:        1,
+			precision:     1,
+		},
+		{
+			name:     "closest finding wins and the duplicate still counts as relevant",
+			expected: []ExpectedBug{{File: "main.go", Line: 10}},
+			findings: []Finding{
+				{File: "main.go", Line: 12},
+				{File: "main.go", Line: 10},
+			},
+			truePositives: 1,
+			exactLines:    1,
+			recall:        1,
+			precision:     1,
+		},
+		{
+			name: "one finding credits one bug",
+			expected: []ExpectedBug{
+				{File: "main.go", Line: 10},
+				{File: "main.go", Line: 11},
+			},
+			findings:       []Finding{{File: "main.go", Line: 11}},
+			truePositives:  1,
+			falseNegatives: 1,
+			exactLines:     1,
+			recall:         0.5,
+			precision:      1,
+		},
+		{
+			name:           "nothing reported",
+			expected:       []ExpectedBug{{File: "main.go", Line: 10}},
+			falseNegatives: 1,
+			precision:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, matched, missed, falsePositives := Score(tt.expected, tt.findings)
+			if m.TruePositives != tt.truePositives || len(matched) != tt.truePositives {
+				t.Errorf("true positives = %d (%d matches), want %d", m.TruePositives, len(matched), tt.truePositives)
+			}
+			if m.FalseNegatives != tt.falseNegatives || len(missed) != tt.falseNegatives {
+				t.Errorf("false negatives = %d (%d missed), want %d", m.FalseNegatives, len(missed), tt.falseNegatives)
+			}
+			if m.FalsePositives != tt.falsePositives || len(falsePositives) != tt.falsePositives {
+				t.Errorf("false positives = %d (%d findings), want %d", m.FalsePositives, len(falsePositives), tt.falsePositives)
+			}
+			if m.ExactLines != tt.exactLines {
+				t.Errorf("exact lines = %d, want %d", m.ExactLines, tt.exactLines)
+			}
+			if m.LineErrorSum != tt.lineErrorSum {
+				t.Errorf("line error sum = %d, want %d", m.LineErrorSum, tt.lineErrorSum)
+			}
+			if !near(m.Recall, tt.recall) || !near(m.Precision, tt.precision) {
+				t.Errorf("recall, precision = %v, %v, want %v, %v", m.Recall, m.Precision, tt.recall, tt.precision)
+			}
+		})
+	}
+}
+
+func TestMetricsAdd(t *testing.T) {
+	var total Metrics
+	a, _, _, _ := Score([]ExpectedBug{{File: "a.go", Line: 1}}, []Finding{{File: "a.go", Line: 2}})
+	b, _, _, _ := Score([]ExpectedBug{{File: "b.go", Line: 1}}, []Finding{{File: "c.go", Line: 1}})
+	total.Add(a)
+	total.Add(b)
+
+	if total.Cases != 2 || total.Expected != 2 || total.Findings != 2 {
+		t.Fatalf("counts = %+v", total)
+	}
+	if !near(total.Recall, 0.5) || !near(total.Precision, 0.5) || !near(total.F1, 0.5) {
+		t.Errorf("recall, precision, f1 = %v, %v, %v, want 0.5 each", total.Recall, total.Precision, total.F1)
+	}
+	if !near(total.MeanLineError, 1) || total.LineAccuracy != 0 {
+		t.Errorf("mean line error, line accuracy = %v, %v, want 1, 0", total.MeanLineError, total.LineAccuracy)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +18,9 @@ type Client struct {
 	appID           int64
 	privateKey      []byte
 	installationIDs sync.Map // repo full name -> installation ID cache
+
+	appLoginMu sync.Mutex
+	appLogin   string // "<slug>[bot]", cached after the first lookup
 }
 
 // NewClient creates a new GitHub App client
@@ -47,17 +51,66 @@ func (c *Client) createJWT() (string, error) {
 
 // GetInstallationClient returns a GitHub client authenticated as an installation
 func (c *Client) GetInstallationClient(ctx context.Context, owner, repo string) (*github.Client, error) {
+	installationID, err := c.installationID(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return c.clientForInstallation(ctx, installationID)
+}
+
+// GetCloneToken returns a short-lived installation token that can only read
+// the contents of one repository, for cloning it
+func (c *Client) GetCloneToken(ctx context.Context, owner, repo string) (string, error) {
+	installationID, err := c.installationID(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	return c.installationToken(ctx, installationID, &github.InstallationTokenOptions{
+		Repositories: []string{repo},
+		Permissions:  &github.InstallationPermissions{Contents: github.String("read")},
+	})
+}
+
+// AppLogin returns the login the app comments as, "<slug>[bot]"
+func (c *Client) AppLogin(ctx context.Context) (string, error) {
+	c.appLoginMu.Lock()
+	defer c.appLoginMu.Unlock()
+	if c.appLogin != "" {
+		return c.appLogin, nil
+	}
+
+	jwtToken, err := c.createJWT()
+	if err != nil {
+		return "", err
+	}
+	appClient := github.NewClient(&http.Client{Transport: &jwtTransport{token: jwtToken}})
+
+	app, _, err := appClient.Apps.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get app: %w", err)
+	}
+	if app.GetSlug() == "" {
+		return "", fmt.Errorf("app %d has no slug", c.appID)
+	}
+
+	c.appLogin = app.GetSlug() + "[bot]"
+	return c.appLogin, nil
+}
+
+// installationID finds the app installation for a repository
+func (c *Client) installationID(ctx context.Context, owner, repo string) (int64, error) {
 	fullName := fmt.Sprintf("%s/%s", owner, repo)
 
 	// Check cache first
 	if cached, ok := c.installationIDs.Load(fullName); ok {
-		return c.clientForInstallation(ctx, cached.(int64))
+		return cached.(int64), nil
 	}
 
 	// Get installation ID for the repository
 	jwtToken, err := c.createJWT()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	// Create a client with JWT auth to find the installation
@@ -67,38 +120,48 @@ func (c *Client) GetInstallationClient(ctx context.Context, owner, repo string)
 
 	installation, _, err := appClient.Apps.FindRepositoryInstallation(ctx, owner, repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find installation for %s: %w", fullName, err)
+		return 0, fmt.Errorf("failed to find installation for %s: %w", fullName, err)
 	}
 
 	installationID := installation.GetID()
 	c.installationIDs.Store(fullName, installationID)
 
-	return c.clientForInstallation(ctx, installationID)
+	return installationID, nil
 }
 
 // clientForInstallation creates a client for a specific installation ID
 func (c *Client) clientForInstallation(ctx context.Context, installationID int64) (*github.Client, error) {
-	jwtToken, err := c.createJWT()
+	token, err := c.installationToken(ctx, installationID, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	// Create client with installation token
+	installTransport := &tokenTransport{token: token}
+	installClient := &http.Client{Transport: installTransport}
+
+	return github.NewClient(installClient), nil
+}
+
+// installationToken creates an installation access token, optionally narrowed by opts
+func (c *Client) installationToken(ctx context.Context, installationID int64, opts *github.InstallationTokenOptions) (string, error) {
+	jwtToken, err := c.createJWT()
+	if err != nil {
+		return "", err
+	}
+
 	// Create app client
 	transport := &jwtTransport{token: jwtToken}
 	httpClient := &http.Client{Transport: transport}
 	appClient := github.NewClient(httpClient)
 
 	// Get installation access token
-	token, _, err := appClient.Apps.CreateInstallationToken(ctx, installationID, nil)
+	token, _, err := appClient.Apps.CreateInstallationToken(ctx, installationID, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create installation token: %w", err)
+		return "", fmt.Errorf("failed to create installation token: %w", err)
 	}
 
-	// Create client with installation token
-	installTransport := &tokenTransport{token: token.GetToken()}
-	installClient := &http.Client{Transport: installTransport}
-
-	return github.NewClient(installClient), nil
+	return token.GetToken(), nil
 }
 
 // GetPullRequestDiff fetches the diff for a pull request
@@ -151,6 +214,459 @@ func (c *Client) GetPullRequestFiles(ctx context.Context, owner, repo string, pr
 	return files, nil
 }
 
+// GetCommit fetches a commit with its message and changed files
+func (c *Client) GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, _, err := client.Repositories.GetCommit(ctx, owner, repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", sha, err)
+	}
+
+	return commit, nil
+}
+
+// GetCommitDiff fetches the diff of a single commit
+func (c *Client) GetCommitDiff(ctx context.Context, owner, repo, sha string) (string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	diff, _, err := client.Repositories.GetCommitRaw(ctx, owner, repo, sha, github.RawOptions{
+		Type: github.Diff,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// CompareCommits fetches the commits and changed files between base and head
+func (c *Client) CompareCommits(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, _, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, err)
+	}
+
+	return comparison, nil
+}
+
+// GetCompareDiff fetches the diff between base and head
+func (c *Client) GetCompareDiff(ctx context.Context, owner, repo, base, head string) (string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	diff, _, err := client.Repositories.CompareCommitsRaw(ctx, owner, repo, base, head, github.RawOptions{
+		Type: github.Diff,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get compare diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// GetFileContent fetches a file's content at ref. A missing file returns "" and no error.
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	fileContent, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, path,
+		&github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if fileContent == nil {
+		return "", nil
+	}
+
+	return fileContent.GetContent()
+}
+
+// FindComment returns the bot's comment on an issue or PR containing marker, or nil
+func (c *Client) FindComment(ctx context.Context, owner, repo string, number int, marker string) (*github.IssueComment, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, comment := range comments {
+			if comment.GetUser().GetType() == "Bot" && strings.Contains(comment.GetBody(), marker) {
+				return comment, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// EditComment replaces the body of an issue or PR comment
+func (c *Client) EditComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.EditComment(ctx, owner, repo, commentID, &github.IssueComment{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to edit comment: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertComment edits the bot's comment containing marker, or posts body as a new comment
+func (c *Client) UpsertComment(ctx context.Context, owner, repo string, number int, marker, body string) error {
+	existing, err := c.FindComment(ctx, owner, repo, number, marker)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return c.EditComment(ctx, owner, repo, existing.GetID(), body)
+	}
+	return c.CreateComment(ctx, owner, repo, number, body)
+}
+
+// ListReviews lists the reviews on a PR, oldest first
+func (c *Client) ListReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*github.PullRequestReview
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := client.PullRequests.ListReviews(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reviews: %w", err)
+		}
+		all = append(all, reviews...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// UpdateReviewBody replaces the body of a submitted review
+func (c *Client) UpdateReviewBody(ctx context.Context, owner, repo string, prNumber int, reviewID int64, body string) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := client.PullRequests.UpdateReview(ctx, owner, repo, prNumber, reviewID, body); err != nil {
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePullRequestBody replaces a PR's description
+func (c *Client) UpdatePullRequestBody(ctx context.Context, owner, repo string, prNumber int, body string) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.PullRequests.Edit(ctx, owner, repo, prNumber, &github.PullRequest{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update PR description: %w", err)
+	}
+
+	return nil
+}
+
+// GetDefaultBranch returns a repository's default branch and its head commit SHA
+func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", "", err
+	}
+
+	repository, _, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	branch, _, err := client.Repositories.GetBranch(ctx, owner, repo, repository.GetDefaultBranch(), 1)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get branch %s: %w", repository.GetDefaultBranch(), err)
+	}
+
+	return repository.GetDefaultBranch(), branch.GetCommit().GetSHA(), nil
+}
+
+// ListTree returns the paths of all files in the tree of a commit
+func (c *Client) ListTree(ctx context.Context, owner, repo, sha string) ([]string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, _, err := client.Git.GetTree(ctx, owner, repo, sha, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	if tree.GetTruncated() {
+		log.Warn().Str("repo", owner+"/"+repo).Msg("Repository tree is too large, listing a partial tree")
+	}
+
+	var files []string
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			files = append(files, entry.GetPath())
+		}
+	}
+	return files, nil
+}
+
+// GetIssue fetches an issue
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*github.Issue, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, _, err := client.Issues.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	return issue, nil
+}
+
+// ListLabels returns the names of the labels defined in a repository
+func (c *Client) ListLabels(ctx context.Context, owner, repo string) ([]string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, label := range labels {
+			names = append(names, label.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return names, nil
+}
+
+// SearchCode returns the paths of up to limit files in a repository's default
+// branch that match a code search query
+func (c *Client) SearchCode(ctx context.Context, owner, repo, query string, limit int) ([]string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := client.Search.Code(ctx, fmt.Sprintf("%s repo:%s/%s", query, owner, repo),
+		&github.SearchOptions{ListOptions: github.ListOptions{PerPage: limit}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search code: %w", err)
+	}
+
+	paths := make([]string, 0, len(result.CodeResults))
+	for _, match := range result.CodeResults {
+		paths = append(paths, match.GetPath())
+	}
+	return paths, nil
+}
+
+// ListDirectory returns the paths of the files in a directory at ref
+func (c *Client) ListDirectory(ctx context.Context, owner, repo, path, ref string) ([]string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	_, entries, resp, err := client.Repositories.GetContents(ctx, owner, repo, path,
+		&github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %s: %w", path, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.GetType() == "file" {
+			files = append(files, entry.GetPath())
+		}
+	}
+	return files, nil
+}
+
+// FileChange is the new content of one file in a commit
+type FileChange struct {
+	Path    string
+	Content string
+}
+
+// CommitFiles creates a commit that changes files on top of parentSHA through
+// the Git data API and returns its SHA. A nil author commits as the app.
+func (c *Client) CommitFiles(ctx context.Context, owner, repo, parentSHA, message string, author *github.CommitAuthor, files []FileChange) (string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	parent, _, err := client.Git.GetCommit(ctx, owner, repo, parentSHA)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", parentSHA, err)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(file.Path),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(file.Content),
+		})
+	}
+	tree, _, err := client.Git.CreateTree(ctx, owner, repo, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	commit, _, err := client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: github.String(parentSHA)}},
+		Author:  author,
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	return commit.GetSHA(), nil
+}
+
+// UpdateBranch fast-forwards a branch to sha. It fails when sha does not
+// descend from the branch head, e.g. because someone pushed in the meantime.
+func (c *Client) UpdateBranch(ctx context.Context, owner, repo, branch, sha string) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Git.UpdateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	}, false)
+	if err != nil {
+		return fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// CreateBranchPullRequest commits files on top of parentSHA in a new branch
+// through the Git data API and opens a PR from it into base
+func (c *Client) CreateBranchPullRequest(ctx context.Context, owner, repo, base, parentSHA, branch, message, title, body string, author *github.CommitAuthor, files []FileChange) (*github.PullRequest, error) {
+	sha, err := c.CommitFiles(ctx, owner, repo, parentSHA, message, author, files)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	pr, _, err := client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(branch),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// GetReviewComment fetches an inline review comment
+func (c *Client) GetReviewComment(ctx context.Context, owner, repo string, commentID int64) (*github.PullRequestComment, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, _, err := client.PullRequests.GetComment(ctx, owner, repo, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review comment %d: %w", commentID, err)
+	}
+
+	return comment, nil
+}
+
+// ReplyToReviewComment posts a reply in the thread of an inline review comment
+func (c *Client) ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.PullRequests.CreateCommentInReplyTo(ctx, owner, repo, prNumber, body, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to reply to review comment: %w", err)
+	}
+
+	return nil
+}
+
 // CreateComment posts a comment on an issue or PR
 func (c *Client) CreateComment(ctx context.Context, owner, repo string, number int, body string) error {
 	client, err := c.GetInstallationClient(ctx, owner, repo)
@@ -174,6 +690,11 @@ func (c *Client) CreateComment(ctx context.Context, owner, repo string, number i
 
 // AddReaction adds a reaction to a comment
 func (c *Client) AddReaction(ctx context.Context, owner, repo string, commentID int64, reaction string) error {
+	if commentID == 0 {
+		// Events without a triggering comment, e.g. automatic summaries
+		return nil
+	}
+
 	client, err := c.GetInstallationClient(ctx, owner, repo)
 	if err != nil {
 		return err
@@ -187,6 +708,95 @@ func (c *Client) AddReaction(ctx context.Context, owner, repo string, commentID
 	return nil
 }
 
+// CreateCommitComment comments on a commit, on a line of its diff when path is
+// set and position is the line's position in the file's patch
+func (c *Client) CreateCommitComment(ctx context.Context, owner, repo, sha, path string, position int, body string) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	comment := &github.RepositoryComment{Body: github.String(body)}
+	if path != "" && position > 0 {
+		comment.Path = github.String(path)
+		comment.Position = github.Int(position)
+	}
+
+	if _, _, err := client.Repositories.CreateComment(ctx, owner, repo, sha, comment); err != nil {
+		return fmt.Errorf("failed to create commit comment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateIssue opens an issue and returns its number
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string) (int, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	issue, _, err := client.Issues.Create(ctx, owner, repo, &github.IssueRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create issue: %w", err)
+	}
+
+	return issue.GetNumber(), nil
+}
+
+// maxAnnotations is the number of annotations GitHub accepts per check run request
+const maxAnnotations = 50
+
+// CreateCheckRun posts a completed check run on headSHA, sending annotations
+// in batches of the size GitHub accepts per request
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo, name, headSHA, conclusion, title, summary string, annotations []*github.CheckRunAnnotation) error {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	batch := annotations
+	if len(batch) > maxAnnotations {
+		batch = batch[:maxAnnotations]
+	}
+	output := &github.CheckRunOutput{
+		Title:       github.String(title),
+		Summary:     github.String(summary),
+		Annotations: batch,
+	}
+
+	run, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:        name,
+		HeadSHA:     headSHA,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(conclusion),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output:      output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	for start := maxAnnotations; start < len(annotations); start += maxAnnotations {
+		end := start + maxAnnotations
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		output.Annotations = annotations[start:end]
+		if _, _, err := client.Checks.UpdateCheckRun(ctx, owner, repo, run.GetID(), github.UpdateCheckRunOptions{
+			Name:   name,
+			Output: output,
+		}); err != nil {
+			return fmt.Errorf("failed to add check run annotations: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // CreateReviewComment creates an inline comment on a specific line in a PR
 func (c *Client) CreateReviewComment(ctx context.Context, owner, repo string, prNumber int, commitID, path, body string, line int) error {
 	client, err := c.GetInstallationClient(ctx, owner, repo)
@@ -244,6 +854,82 @@ func (c *Client) CreateReview(ctx context.Context, owner, repo string, prNumber
 	return nil
 }
 
+// GetPermissionLevel returns a user's role on a repository:
+// admin, maintain, write, triage, read or none
+func (c *Client) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	level, _, err := client.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get permission level: %w", err)
+	}
+
+	// The per-user permissions map distinguishes maintain and triage,
+	// which the legacy permission field folds into write and read.
+	perms := level.GetUser().GetPermissions()
+	switch {
+	case perms["admin"]:
+		return "admin", nil
+	case perms["maintain"]:
+		return "maintain", nil
+	case perms["push"]:
+		return "write", nil
+	case perms["triage"]:
+		return "triage", nil
+	case perms["pull"]:
+		return "read", nil
+	}
+
+	return level.GetPermission(), nil
+}
+
+// IsOrgMember reports whether a user is a member of an organization
+func (c *Client) IsOrgMember(ctx context.Context, owner, repo, org, user string) (bool, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return false, err
+	}
+
+	member, _, err := client.Organizations.IsMember(ctx, org, user)
+	if err != nil {
+		return false, fmt.Errorf("failed to check org membership: %w", err)
+	}
+
+	return member, nil
+}
+
+// UploadSARIF uploads a gzipped, base64-encoded SARIF document to code scanning
+func (c *Client) UploadSARIF(ctx context.Context, owner, repo, ref, commitSHA, encodedSARIF string) (string, error) {
+	client, err := c.GetInstallationClient(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	analysis := &github.SarifAnalysis{
+		CommitSHA: github.String(commitSHA),
+		Ref:       github.String(ref),
+		Sarif:     github.String(encodedSARIF),
+		StartedAt: &github.Timestamp{Time: time.Now()},
+		ToolName:  github.String("TechyBot"),
+	}
+
+	id, _, err := client.CodeScanning.UploadSarif(ctx, owner, repo, analysis)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload SARIF: %w", err)
+	}
+
+	log.Info().
+		Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+		Str("ref", ref).
+		Str("sarif_id", id.GetID()).
+		Msg("Uploaded SARIF to code scanning")
+
+	return id.GetID(), nil
+}
+
 // jwtTransport adds JWT auth header to requests
 type jwtTransport struct {
 	token string
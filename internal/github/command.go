@@ -0,0 +1,240 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// controlActions maps command words to non-review actions
+var controlActions = map[string]models.CommandAction{
+	"help":    models.ActionHelp,
+	"status":  models.ActionStatus,
+	"cancel":  models.ActionCancel,
+	"approve": models.ActionApprove,
+	"fix":     models.ActionFix,
+}
+
+// severityAliases normalises user supplied severities to error, warning or info
+var severityAliases = map[string]string{
+	"error":    "error",
+	"critical": "error",
+	"high":     "error",
+	"warning":  "warning",
+	"warn":     "warning",
+	"medium":   "warning",
+	"info":     "info",
+	"low":      "info",
+}
+
+// CommandError describes why a comment addressed to the bot could not be parsed
+type CommandError struct {
+	Raw     string
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+// ParseCommand extracts a command addressed to botUsername from a comment body
+// posted on owner/repo, whose custom modes are accepted alongside the built-in ones.
+// It returns (nil, nil) when the comment does not mention the bot with a command.
+//
+// Grammar:
+//
+//	@bot <mode> [verbose] [--files GLOB]... [--exclude GLOB]... [--model NAME]
+//	     [--min-severity LEVEL] [--focus TEXT] [--verbose] [free text...]
+//
+// Values may be single or double quoted, and --flag=value is accepted.
+func ParseCommand(botUsername, owner, repo, body string) (*models.Command, error) {
+	pattern := fmt.Sprintf(`(?i)(?:^|\s)@%s\b[ \t]+([^\r\n]+)`, regexp.QuoteMeta(botUsername))
+	matches := regexp.MustCompile(pattern).FindStringSubmatch(body)
+	if matches == nil {
+		return nil, nil
+	}
+
+	line := strings.TrimSpace(matches[1])
+	raw := "@" + botUsername + " " + line
+
+	tokens, err := tokenizeCommand(line)
+	if err != nil {
+		return nil, &CommandError{Raw: raw, Message: err.Error()}
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	cmd := &models.Command{
+		Mode: models.ModeReview,
+		Raw:  raw,
+	}
+
+	// Control commands take no options
+	if action, ok := controlActions[strings.ToLower(tokens[0])]; ok {
+		return &models.Command{Action: action, Raw: raw}, nil
+	}
+
+	// The first token names the mode unless the command starts with a flag
+	mode, _ := modes.Lookup(owner, repo, models.ModeReview)
+	if !strings.HasPrefix(tokens[0], "--") {
+		var ok bool
+		mode, ok = modes.Lookup(owner, repo, models.ReviewMode(strings.ToLower(tokens[0])))
+		if !ok {
+			return nil, &CommandError{
+				Raw:     raw,
+				Message: fmt.Sprintf("unknown mode %q (available: %s)", tokens[0], strings.Join(modes.Names(owner, repo), ", ")),
+			}
+		}
+		cmd.Mode = mode.Name
+		tokens = tokens[1:]
+	}
+
+	var freeText []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if !strings.HasPrefix(token, "--") {
+			if strings.EqualFold(token, "verbose") && len(freeText) == 0 {
+				cmd.Verbose = true
+				continue
+			}
+			freeText = append(freeText, token)
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimPrefix(token, "--"))
+		value := ""
+		hasValue := false
+		if idx := strings.Index(name, "="); idx != -1 {
+			value = token[2+idx+1:]
+			name = name[:idx]
+			hasValue = true
+		}
+
+		if name == "verbose" {
+			if hasValue {
+				return nil, &CommandError{Raw: raw, Message: "--verbose does not take a value"}
+			}
+			cmd.Verbose = true
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(tokens) || strings.HasPrefix(tokens[i+1], "--") {
+				return nil, &CommandError{Raw: raw, Message: fmt.Sprintf("--%s requires a value", name)}
+			}
+			i++
+			value = tokens[i]
+		}
+
+		switch name {
+		case "files", "file", "include":
+			cmd.Options.Files = append(cmd.Options.Files, splitList(value)...)
+		case "exclude":
+			cmd.Options.Exclude = append(cmd.Options.Exclude, splitList(value)...)
+		case "model":
+			model := strings.ToLower(strings.TrimSpace(value))
+			if !modes.ValidModel(model) {
+				return nil, &CommandError{Raw: raw, Message: fmt.Sprintf("unknown model %q (use opus, sonnet, haiku or a full claude-* model id)", value)}
+			}
+			cmd.Options.Model = model
+		case "min-severity", "severity":
+			severity, ok := severityAliases[strings.ToLower(strings.TrimSpace(value))]
+			if !ok {
+				return nil, &CommandError{Raw: raw, Message: fmt.Sprintf("unknown severity %q (use error, warning or info)", value)}
+			}
+			cmd.Options.MinSeverity = severity
+		case "focus":
+			cmd.Options.Focus = strings.TrimSpace(value)
+		default:
+			return nil, &CommandError{Raw: raw, Message: fmt.Sprintf("unknown option --%s", name)}
+		}
+	}
+
+	cmd.Options.Text = strings.Join(freeText, " ")
+	modes.ApplyDefaults(cmd, mode)
+
+	return cmd, nil
+}
+
+// CommandUsage returns a short usage string for replies to malformed commands on owner/repo
+func CommandUsage(botUsername, owner, repo string) string {
+	return fmt.Sprintf("`@%s <%s> [verbose] [--files GLOB] [--exclude GLOB] [--model opus|sonnet|haiku] [--min-severity error|warning|info] [--focus \"TEXT\"] [free text]` or `@%s help|status|cancel|approve|fix`",
+		botUsername, strings.Join(modes.Names(owner, repo), "|"), botUsername)
+}
+
+// tokenizeCommand splits a command line into shell-like words.
+// Single quotes preserve text literally; double quotes allow backslash escapes.
+// Quotes that do not start a word are kept as ordinary characters.
+func tokenizeCommand(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				current.WriteRune(runes[i])
+			} else if r == '"' || r == '”' || r == '“' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case (r == '\'' || r == '"' || r == '“' || r == '”') && (!inToken || runes[i-1] == '='):
+			// Quotes only open at the start of a word or after "--flag=",
+			// so apostrophes in free text ("don't") stay literal.
+			quote = r
+			if r == '“' || r == '”' {
+				// Normalise curly quotes that GitHub's editor may produce
+				quote = '"'
+			}
+			inToken = true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+// splitList splits a comma separated option value
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
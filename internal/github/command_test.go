@@ -0,0 +1,174 @@
+package github
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *models.Command
+	}{
+		{
+			name: "no mention",
+			body: "looks good to me",
+			want: nil,
+		},
+		{
+			name: "mention without command",
+			body: "thanks @techy",
+			want: nil,
+		},
+		{
+			name: "mode only",
+			body: "@techy hunt",
+			want: &models.Command{Mode: models.ModeHunt, Raw: "@techy hunt"},
+		},
+		{
+			name: "mode is case insensitive",
+			body: "@Techy SECURITY",
+			want: &models.Command{Mode: models.ModeSecurity, Raw: "@techy SECURITY"},
+		},
+		{
+			name: "flags without mode review",
+			body: "@techy --files internal/**",
+			want: &models.Command{
+				Mode:    models.ModeReview,
+				Raw:     "@techy --files internal/**",
+				Options: models.CommandOptions{Files: []string{"internal/**"}},
+			},
+		},
+		{
+			name: "mention inside a longer comment",
+			body: "Could you take a look?\n\n@techy hunt verbose\nThanks!",
+			want: &models.Command{Mode: models.ModeHunt, Verbose: true, Raw: "@techy hunt verbose"},
+		},
+		{
+			name: "all options",
+			body: `@techy review --files "a/*.go, b/**" --exclude=vendor/ --model Opus --min-severity high --focus "error handling" --verbose check retries`,
+			want: &models.Command{
+				Mode:    models.ModeReview,
+				Verbose: true,
+				Raw:     `@techy review --files "a/*.go, b/**" --exclude=vendor/ --model Opus --min-severity high --focus "error handling" --verbose check retries`,
+				Options: models.CommandOptions{
+					Files:       []string{"a/*.go", "b/**"},
+					Exclude:     []string{"vendor/"},
+					Model:       "opus",
+					MinSeverity: "error",
+					Focus:       "error handling",
+					Text:        "check retries",
+				},
+			},
+		},
+		{
+			name: "verbose after free text is text",
+			body: "@techy hunt please be verbose",
+			want: &models.Command{
+				Mode:    models.ModeHunt,
+				Raw:     "@techy hunt please be verbose",
+				Options: models.CommandOptions{Text: "please be verbose"},
+			},
+		},
+		{
+			name: "full model id",
+			body: "@techy hunt --model claude-sonnet-4-5",
+			want: &models.Command{
+				Mode:    models.ModeHunt,
+				Raw:     "@techy hunt --model claude-sonnet-4-5",
+				Options: models.CommandOptions{Model: "claude-sonnet-4-5"},
+			},
+		},
+		{
+			name: "control command ignores the rest",
+			body: "@techy cancel --files x",
+			want: &models.Command{Action: models.ActionCancel, Raw: "@techy cancel --files x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommand("techy", "acme", "api", tt.body)
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCommand() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommandErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"unknown mode", "@techy nitpick", `unknown mode "nitpick"`},
+		{"unknown option", "@techy hunt --color red", "unknown option --color"},
+		{"missing value", "@techy hunt --files", "--files requires a value"},
+		{"value is a flag", "@techy hunt --focus --verbose", "--focus requires a value"},
+		{"verbose with value", "@techy hunt --verbose=yes", "--verbose does not take a value"},
+		{"unknown model", "@techy hunt --model gpt", `unknown model "gpt"`},
+		{"unknown severity", "@techy hunt --min-severity urgent", `unknown severity "urgent"`},
+		{"unterminated quote", `@techy hunt --focus "auth`, "unterminated \" quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommand("techy", "acme", "api", tt.body)
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) {
+				t.Fatalf("ParseCommand() = %+v, %v, want a CommandError", got, err)
+			}
+			if len(cmdErr.Message) < len(tt.message) || cmdErr.Message[:len(tt.message)] != tt.message {
+				t.Errorf("message = %q, want prefix %q", cmdErr.Message, tt.message)
+			}
+			if cmdErr.Raw == "" {
+				t.Error("Raw is empty")
+			}
+		})
+	}
+}
+
+func TestTokenizeCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  hunt   verbose ", []string{"hunt", "verbose"}},
+		{`--focus "error handling"`, []string{"--focus", "error handling"}},
+		{`--focus 'a "quoted" word'`, []string{"--focus", `a "quoted" word`}},
+		{`--focus="x y"`, []string{"--focus=x y"}},
+		{`"say \"hi\" \\ now"`, []string{`say "hi" \ now`}},
+		{"don't stop", []string{"don't", "stop"}},
+		{"“curly quotes”", []string{"curly quotes"}},
+		{`""`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := tokenizeCommand(tt.line)
+			if err != nil {
+				t.Fatalf("tokenizeCommand() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeCommandUnterminated(t *testing.T) {
+	for _, line := range []string{`"open`, `--focus 'open`} {
+		if _, err := tokenizeCommand(line); err == nil {
+			t.Errorf("tokenizeCommand(%q) error = nil, want unterminated quote", line)
+		}
+	}
+}
@@ -202,3 +202,65 @@ func GetChangedLineNumbers(patch string) map[int]bool {
 
 	return changed
 }
+
+// DiffPosition returns the position of a new-file line in a patch as commit
+// comments count it: lines below the first hunk header, including later hunk
+// headers. It returns 0 when the line is not in the patch.
+func DiffPosition(patch string, line int) int {
+	var currentLine int
+	position := 0
+	started := false
+	for _, text := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(text, "@@") {
+			if hunk := ParseHunkHeader(text); hunk != nil {
+				currentLine = hunk.NewStart
+			}
+			if started {
+				position++
+			}
+			started = true
+			continue
+		}
+		if !started {
+			continue
+		}
+		position++
+
+		if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "\\") {
+			continue
+		}
+		if currentLine == line {
+			return position
+		}
+		currentLine++
+	}
+	return 0
+}
+
+// FilterDiff keeps only the file sections of a unified diff for which keep
+// returns true. Content before the first file header is preserved.
+func FilterDiff(diff string, keep func(filename string) bool) string {
+	filePattern := regexp.MustCompile(`(?m)^diff --git a/(.+?) b/(.+?)$`)
+	matches := filePattern.FindAllStringSubmatchIndex(diff, -1)
+	if len(matches) == 0 {
+		return diff
+	}
+
+	var sb strings.Builder
+	sb.WriteString(diff[:matches[0][0]])
+
+	for i, match := range matches {
+		start := match[0]
+		end := len(diff)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		filename := diff[match[4]:match[5]]
+		if keep(filename) {
+			sb.WriteString(diff[start:end])
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// globCache holds compiled glob patterns
+var globCache sync.Map // pattern -> *regexp.Regexp
+
+// MatchGlob reports whether filePath matches a glob pattern.
+// Supported syntax: "*" matches within a path segment, "**" matches across
+// segments, "?" matches a single character. Patterns without a slash are
+// matched against the base name, and a trailing slash matches a directory.
+func MatchGlob(pattern, filePath string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	if pattern == "" {
+		return false
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		filePath = path.Base(filePath)
+	}
+
+	return compileGlob(pattern).MatchString(filePath)
+}
+
+// MatchAnyGlob reports whether filePath matches at least one of the patterns
+func MatchAnyGlob(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileGlob converts a glob pattern into an anchored regular expression
+func compileGlob(pattern string) *regexp.Regexp {
+	if cached, ok := globCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re := regexp.MustCompile(sb.String())
+	globCache.Store(pattern, re)
+	return re
+}
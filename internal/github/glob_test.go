@@ -0,0 +1,57 @@
+package github
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/review/modes.go", true}, // no slash: matched on the base name
+		{"*.go", "main.py", false},
+		{"internal/*.go", "internal/main.go", true},
+		{"internal/*.go", "internal/review/modes.go", false},
+		{"internal/**", "internal/review/modes.go", true},
+		{"internal/**/*.go", "internal/modes.go", true},
+		{"internal/**/*.go", "internal/a/b/modes.go", true},
+		{"**/testdata/**", "pkg/x/testdata/in.json", true},
+		{"vendor/", "vendor/github.com/x/y.go", true},
+		{"vendor/", "src/vendor.go", false},
+		{"./cmd/*", "cmd/main.go", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"a.b", "axb", false}, // dots are literal
+		{"", "main.go", false},
+		{"  *.md  ", "README.md", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchAnyGlob(t *testing.T) {
+	patterns := []string{"*.sql", "db/migrations/**"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"schema.sql", true},
+		{"db/migrations/001_init.up", true},
+		{"db/seed.go", false},
+	}
+	for _, tt := range tests {
+		if got := MatchAnyGlob(patterns, tt.path); got != tt.want {
+			t.Errorf("MatchAnyGlob(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if MatchAnyGlob(nil, "schema.sql") {
+		t.Error("MatchAnyGlob(nil) = true, want false")
+	}
+}
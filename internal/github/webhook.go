@@ -5,12 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 	"strings"
 
+	"github.com/CREVIOS/revo/internal/modes"
 	"github.com/CREVIOS/revo/pkg/models"
 	"github.com/rs/zerolog/log"
 )
@@ -20,18 +21,44 @@ type WebhookHandler struct {
 	secret      string
 	botUsername string
 	onCommand   func(event *WebhookEvent) error
+
+	autoSummarize  bool
+	autoMigrations bool
+	pushBranches   []string
+	pushMode       models.ReviewMode
 }
 
 // WebhookEvent contains parsed webhook event data
 type WebhookEvent struct {
-	EventType   string
-	Action      string
-	Repository  *Repository
-	PullRequest *PullRequest
-	Comment     *Comment
-	Sender      *User
-	Command     *models.Command
-	ReviewID    uint
+	EventType   string          `json:"event_type"`
+	Action      string          `json:"action"`
+	DeliveryID  string          `json:"delivery_id,omitempty"`
+	Repository  *Repository     `json:"repository"`
+	PullRequest *PullRequest    `json:"pull_request,omitempty"`
+	Comment     *Comment        `json:"comment,omitempty"`
+	Sender      *User           `json:"sender,omitempty"`
+	Command     *models.Command `json:"command,omitempty"`
+	// CommandError is set when the comment addressed the bot but the command was malformed
+	CommandError string `json:"command_error,omitempty"`
+	ReviewID     uint   `json:"review_id,omitempty"`
+	// Target is set for reviews of pushed commits and commands on plain issues
+	Target *models.ReviewTarget `json:"target,omitempty"`
+}
+
+// IssueNumber returns the plain issue a command was made on, or 0 on pull requests
+func (e *WebhookEvent) IssueNumber() int {
+	if e.Target != nil && e.Target.Kind == models.TargetIssue {
+		return e.Target.Issue
+	}
+	return 0
+}
+
+// ThreadNumber returns the issue or pull request to reply on, or 0 for pushes
+func (e *WebhookEvent) ThreadNumber() int {
+	if issue := e.IssueNumber(); issue != 0 {
+		return issue
+	}
+	return e.PullRequest.Number
 }
 
 // Repository represents GitHub repository data
@@ -54,6 +81,9 @@ type PullRequest struct {
 	Head    *Branch `json:"head"`
 	Base    *Branch `json:"base"`
 	User    *User   `json:"user"`
+	Draft   bool    `json:"draft,omitempty"`
+
+	AuthorAssociation string `json:"author_association,omitempty"`
 }
 
 // Branch represents a git branch reference
@@ -65,10 +95,12 @@ type Branch struct {
 
 // Comment represents a GitHub comment
 type Comment struct {
-	ID      int64  `json:"id"`
-	Body    string `json:"body"`
-	User    *User  `json:"user"`
-	HTMLURL string `json:"html_url"`
+	ID                int64  `json:"id"`
+	Body              string `json:"body"`
+	User              *User  `json:"user"`
+	HTMLURL           string `json:"html_url"`
+	AuthorAssociation string `json:"author_association"`       // OWNER, MEMBER, COLLABORATOR, CONTRIBUTOR, FIRST_TIME_CONTRIBUTOR, FIRST_TIMER, NONE
+	InReplyToID       int64  `json:"in_reply_to_id,omitempty"` // inline comment this one replies to
 }
 
 // User represents a GitHub user
@@ -78,9 +110,10 @@ type User struct {
 	Type  string `json:"type"`
 }
 
-// Issue represents a GitHub issue (used for PR comments on issues endpoint)
+// Issue represents a GitHub issue, or a PR when comments use the issues endpoint
 type Issue struct {
 	Number      int          `json:"number"`
+	Title       string       `json:"title"`
 	PullRequest *PullRequest `json:"pull_request,omitempty"`
 }
 
@@ -94,6 +127,18 @@ type webhookPayload struct {
 	Sender      *User        `json:"sender"`
 }
 
+// pushPayload is the part of a push event payload used to review pushed commits
+type pushPayload struct {
+	Ref        string      `json:"ref"`
+	Before     string      `json:"before"`
+	After      string      `json:"after"`
+	Created    bool        `json:"created"`
+	Deleted    bool        `json:"deleted"`
+	Commits    []struct{}  `json:"commits"`
+	Repository *Repository `json:"repository"`
+	Sender     *User       `json:"sender"`
+}
+
 // NewWebhookHandler creates a new webhook handler
 func NewWebhookHandler(secret, botUsername string, onCommand func(event *WebhookEvent) error) *WebhookHandler {
 	return &WebhookHandler{
@@ -103,6 +148,24 @@ func NewWebhookHandler(secret, botUsername string, onCommand func(event *Webhook
 	}
 }
 
+// SetAutoSummarize summarizes pull requests when they are opened or marked ready for review
+func (h *WebhookHandler) SetAutoSummarize(enabled bool) {
+	h.autoSummarize = enabled
+}
+
+// SetAutoMigrations checks the migrations of pull requests when they are opened
+// or updated; the inbox drops the check when no migration file changed
+func (h *WebhookHandler) SetAutoMigrations(enabled bool) {
+	h.autoMigrations = enabled
+}
+
+// SetPushReview reviews commits pushed to branches matching these globs in
+// mode; no branches turns push reviews off
+func (h *WebhookHandler) SetPushReview(branches []string, mode models.ReviewMode) {
+	h.pushBranches = branches
+	h.pushMode = mode
+}
+
 // HandleWebhook processes incoming webhook requests
 func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	// Only accept POST requests
@@ -134,7 +197,7 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		Msg("Received webhook event")
 
 	// Parse and handle event
-	event, err := h.parseEvent(eventType, body)
+	events, err := h.parseEvent(eventType, body)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to parse webhook event")
 		http.Error(w, "Failed to parse event", http.StatusBadRequest)
@@ -142,18 +205,32 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if this is a command we should handle
-	if event == nil || event.Command == nil {
+	if len(events) == 0 {
 		// Not a command for us, acknowledge and return
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	// Process command asynchronously
-	go func() {
+	deliveryID := r.Header.Get("X-GitHub-Delivery")
+	for n, event := range events {
+		// Further commands from one delivery get derived IDs, so redeliveries
+		// are still recognized
+		event.DeliveryID = deliveryID
+		if n > 0 && deliveryID != "" {
+			event.DeliveryID = deliveryID + "/" + string(event.Command.Mode)
+		}
+
+		// Persist the command before acknowledging so a crash cannot lose it.
+		// Any failure is surfaced as a 5xx so the delivery can be retried.
 		if err := h.onCommand(event); err != nil {
-			log.Error().Err(err).Msg("Failed to process command")
+			log.Error().
+				Err(err).
+				Str("delivery_id", event.DeliveryID).
+				Msg("Failed to accept command")
+			http.Error(w, "Failed to accept event", http.StatusInternalServerError)
+			return
 		}
-	}()
+	}
 
 	w.WriteHeader(http.StatusAccepted)
 }
@@ -178,13 +255,34 @@ func (h *WebhookHandler) verifySignature(body []byte, signature string) bool {
 	return hmac.Equal([]byte(signature), []byte(expected))
 }
 
-// parseEvent parses the webhook payload and extracts command if present
-func (h *WebhookHandler) parseEvent(eventType string, body []byte) (*WebhookEvent, error) {
+// parseEvent parses the webhook payload and extracts the commands it carries
+func (h *WebhookHandler) parseEvent(eventType string, body []byte) ([]*WebhookEvent, error) {
 	var payload webhookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
+	if eventType == "pull_request" {
+		return h.parsePullRequestEvent(payload), nil
+	}
+	if eventType == "push" {
+		event, err := h.parsePushEvent(body)
+		if event == nil {
+			return nil, err
+		}
+		return []*WebhookEvent{event}, nil
+	}
+
+	event, err := h.parseCommentEvent(eventType, payload)
+	if event == nil {
+		return nil, err
+	}
+	return []*WebhookEvent{event}, nil
+}
+
+// parseCommentEvent extracts the command of a comment addressed to the bot
+func (h *WebhookHandler) parseCommentEvent(eventType string, payload webhookPayload) (*WebhookEvent, error) {
+
 	// Only handle comment events
 	if eventType != "issue_comment" && eventType != "pull_request_review_comment" {
 		return nil, nil
@@ -200,19 +298,37 @@ func (h *WebhookHandler) parseEvent(eventType string, body []byte) (*WebhookEven
 		return nil, nil
 	}
 
-	// Parse command from comment body
-	command := h.parseCommand(payload.Comment.Body)
+	// Parse command from comment body. Malformed commands are still
+	// returned so the user can be told what was wrong.
+	owner, repo := "", ""
+	if payload.Repository != nil {
+		repo = payload.Repository.Name
+		if payload.Repository.Owner != nil {
+			owner = payload.Repository.Owner.Login
+		}
+	}
+	command, err := ParseCommand(h.botUsername, owner, repo, payload.Comment.Body)
+	commandError := ""
+	if err != nil {
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) {
+			return nil, err
+		}
+		command = &models.Command{Raw: cmdErr.Raw}
+		commandError = cmdErr.Message
+	}
 	if command == nil {
 		return nil, nil
 	}
 
 	event := &WebhookEvent{
-		EventType:  eventType,
-		Action:     payload.Action,
-		Repository: payload.Repository,
-		Comment:    payload.Comment,
-		Sender:     payload.Sender,
-		Command:    command,
+		EventType:    eventType,
+		Action:       payload.Action,
+		Repository:   payload.Repository,
+		Comment:      payload.Comment,
+		Sender:       payload.Sender,
+		Command:      command,
+		CommandError: commandError,
 	}
 
 	// For issue comments, check if this is a PR and get PR number
@@ -222,61 +338,148 @@ func (h *WebhookHandler) parseEvent(eventType string, body []byte) (*WebhookEven
 			event.PullRequest = &PullRequest{
 				Number: payload.Issue.Number,
 			}
+		} else if payload.Issue != nil {
+			// Plain issues take the issue modes; there is no pull request
+			event.PullRequest = &PullRequest{Title: payload.Issue.Title}
+			event.Target = &models.ReviewTarget{Kind: models.TargetIssue, Issue: payload.Issue.Number}
 		} else {
-			// Not a PR comment, ignore
 			return nil, nil
 		}
 	} else {
 		event.PullRequest = payload.PullRequest
 	}
 
+	// Issue modes run on plain issues and the other modes on pull requests
+	if event.CommandError == "" && !command.IsControl() {
+		onIssue := event.Target != nil
+		if mode, ok := modes.Lookup(owner, repo, command.Mode); ok && mode.IssueOnly != onIssue {
+			if onIssue {
+				event.CommandError = fmt.Sprintf("`%s` runs on pull requests; on issues use `triage` or `explain`", command.Mode)
+			} else {
+				event.CommandError = fmt.Sprintf("`%s` runs on issues, not pull requests", command.Mode)
+			}
+			commandError = event.CommandError
+		}
+	}
+
 	log.Info().
 		Str("repo", payload.Repository.FullName).
 		Int("pr", event.PullRequest.Number).
+		Str("action", string(command.Action)).
 		Str("mode", string(command.Mode)).
 		Bool("verbose", command.Verbose).
+		Str("command_error", commandError).
 		Msg("Parsed command from comment")
 
 	return event, nil
 }
 
-// parseCommand extracts the @techy command from comment body
-func (h *WebhookHandler) parseCommand(body string) *models.Command {
-	// Match @botname <mode> [verbose]
-	// Case-insensitive matching for the username
-	pattern := fmt.Sprintf(`(?i)@%s\s+(\w+)(?:\s+(verbose))?`, regexp.QuoteMeta(h.botUsername))
-	re := regexp.MustCompile(pattern)
-
-	matches := re.FindStringSubmatch(body)
-	if matches == nil {
+// parsePullRequestEvent turns a newly opened, non-draft PR into a summarize
+// command when automatic summaries are enabled, and an opened or updated PR
+// into a migrations command when automatic migration checks are enabled
+func (h *WebhookHandler) parsePullRequestEvent(payload webhookPayload) []*WebhookEvent {
+	if payload.PullRequest == nil || payload.Repository == nil || payload.PullRequest.Draft {
 		return nil
 	}
 
-	modeStr := strings.ToLower(matches[1])
-	verbose := len(matches) > 2 && strings.ToLower(matches[2]) == "verbose"
+	var events []*WebhookEvent
+	switch payload.Action {
+	case "opened", "ready_for_review":
+		if h.autoSummarize {
+			events = append(events, h.automaticEvent(payload, models.ModeSummarize))
+		}
+		if h.autoMigrations {
+			events = append(events, h.automaticEvent(payload, models.ModeMigrations))
+		}
+	case "synchronize", "reopened":
+		if h.autoMigrations {
+			events = append(events, h.automaticEvent(payload, models.ModeMigrations))
+		}
+	}
 
-	// Map mode string to ReviewMode
-	var mode models.ReviewMode
-	switch modeStr {
-	case "review":
-		mode = models.ModeReview
-	case "hunt":
-		mode = models.ModeHunt
-	case "security":
-		mode = models.ModeSecurity
-	case "performance":
-		mode = models.ModePerformance
-	case "analyze":
-		mode = models.ModeAnalyze
-	default:
-		// Unknown mode, default to review
-		log.Warn().Str("mode", modeStr).Msg("Unknown review mode, defaulting to review")
-		mode = models.ModeReview
+	for _, event := range events {
+		log.Info().
+			Str("repo", payload.Repository.FullName).
+			Int("pr", payload.PullRequest.Number).
+			Str("action", payload.Action).
+			Str("mode", string(event.Command.Mode)).
+			Msg("Running command on pull request automatically")
+	}
+
+	return events
+}
+
+// automaticEvent builds the command the bot runs on a pull request event by itself
+func (h *WebhookHandler) automaticEvent(payload webhookPayload, mode models.ReviewMode) *WebhookEvent {
+	raw := "@" + h.botUsername + " " + string(mode)
+	return &WebhookEvent{
+		EventType:   "pull_request",
+		Action:      payload.Action,
+		Repository:  payload.Repository,
+		PullRequest: payload.PullRequest,
+		Sender:      payload.Sender,
+		// There is no triggering comment; the author's association stands in for the policy check
+		Comment: &Comment{
+			Body:              raw,
+			User:              payload.PullRequest.User,
+			AuthorAssociation: payload.PullRequest.AuthorAssociation,
+		},
+		Command: &models.Command{Mode: mode, Raw: raw},
+	}
+}
+
+// parsePushEvent turns a push to a configured branch into a review of the
+// pushed commit, or of the compare range when several commits were pushed
+func (h *WebhookHandler) parsePushEvent(body []byte) (*WebhookEvent, error) {
+	if len(h.pushBranches) == 0 {
+		return nil, nil
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal push payload: %w", err)
 	}
 
-	return &models.Command{
-		Mode:    mode,
-		Verbose: verbose,
-		Raw:     matches[0],
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if !ok || payload.Deleted || payload.Repository == nil || len(payload.Commits) == 0 {
+		return nil, nil
+	}
+	if !MatchAnyGlob(h.pushBranches, branch) {
+		return nil, nil
+	}
+	// Skip the bot's own commits, e.g. from `fix`
+	if payload.Sender != nil && payload.Sender.Type == "Bot" {
+		return nil, nil
+	}
+
+	target := &models.ReviewTarget{Kind: models.TargetCommit, Head: payload.After, Ref: branch}
+	if !payload.Created && len(payload.Commits) > 1 {
+		target.Kind = models.TargetCompare
+		target.Base = payload.Before
+	}
+
+	mode := h.pushMode
+	if mode == "" {
+		mode = models.ModeReview
 	}
+	raw := "@" + h.botUsername + " " + string(mode)
+	event := &WebhookEvent{
+		EventType:  "push",
+		Repository: payload.Repository,
+		// The PR-keyed plumbing reads the head commit from here
+		PullRequest: &PullRequest{Head: &Branch{Ref: branch, SHA: payload.After}},
+		Sender:      payload.Sender,
+		Comment:     &Comment{Body: raw, User: payload.Sender},
+		Command:     &models.Command{Mode: mode, Raw: raw},
+		Target:      target,
+	}
+
+	log.Info().
+		Str("repo", payload.Repository.FullName).
+		Str("branch", branch).
+		Str("target", string(target.Kind)).
+		Int("commits", len(payload.Commits)).
+		Msg("Reviewing pushed commits")
+
+	return event, nil
 }
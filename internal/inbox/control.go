@@ -0,0 +1,268 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/database"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/review"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+// maxPositionScan bounds how many pending tasks are scanned to find a queue position
+const maxPositionScan = 1000
+
+// handleControl answers help, status and cancel commands
+func (i *Inbox) handleControl(ctx context.Context, event *gh.WebhookEvent) error {
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+	prNumber := event.PullRequest.Number
+	issueNumber := event.IssueNumber()
+
+	var body string
+	var err error
+	switch event.Command.Action {
+	case models.ActionHelp:
+		body = review.FormatHelp(i.botUsername, owner, repo)
+	case models.ActionStatus:
+		body, err = i.statusReport(owner, repo, prNumber, issueNumber)
+	case models.ActionCancel:
+		body, err = i.cancelReviews(owner, repo, prNumber, issueNumber, event.Sender)
+	default:
+		return fmt.Errorf("unsupported control command %q", event.Command.Action)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := i.githubClient.CreateComment(ctx, owner, repo, event.ThreadNumber(), body); err != nil {
+		return fmt.Errorf("failed to reply to %s command: %w", event.Command.Action, err)
+	}
+
+	if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "+1"); err != nil {
+		log.Warn().Err(err).Msg("Failed to add +1 reaction")
+	}
+
+	return nil
+}
+
+// statusReport lists queued and processing reviews for a PR or plain issue
+func (i *Inbox) statusReport(owner, repo string, prNumber, issueNumber int) (string, error) {
+	reviews, err := i.store.ListActiveReviews(owner, repo, prNumber, issueNumber, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to list active reviews: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 📊 TechyBot Status\n\n")
+
+	if len(reviews) == 0 {
+		sb.WriteString("No reviews are queued or running for this PR.\n")
+		return sb.String(), nil
+	}
+
+	sb.WriteString("| Review | Mode | Requested by | Status | Details |\n")
+	sb.WriteString("|--------|------|--------------|--------|---------|\n")
+	for _, r := range reviews {
+		details := ""
+		switch r.Status {
+		case "queued":
+			details = fmt.Sprintf("waiting %s", formatAge(r.QueuedAt))
+			if position := i.queuePosition(r.TaskID); position > 0 {
+				details = fmt.Sprintf("position %d in queue, %s", position, details)
+			}
+		case "processing":
+			if r.StartedAt != nil {
+				details = fmt.Sprintf("running for %s", formatAge(*r.StartedAt))
+			}
+		}
+
+		sha := r.CommitSHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		sb.WriteString(fmt.Sprintf("| #%d (`%s`) | %s %s | @%s | %s | %s |\n",
+			r.ID, sha,
+			review.GetModeEmoji(models.ReviewMode(r.Mode)), r.Mode,
+			r.RequestedBy, r.Status, details))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nUse `@%s cancel` to cancel your own reviews.\n", i.botUsername))
+	return sb.String(), nil
+}
+
+// cancelReviews aborts the sender's queued and running reviews on a PR or plain issue
+func (i *Inbox) cancelReviews(owner, repo string, prNumber, issueNumber int, sender *gh.User) (string, error) {
+	if sender == nil || sender.Login == "" {
+		return "", fmt.Errorf("cancel command has no sender")
+	}
+
+	reviews, err := i.store.ListActiveReviews(owner, repo, prNumber, issueNumber, sender.Login)
+	if err != nil {
+		return "", fmt.Errorf("failed to list active reviews: %w", err)
+	}
+
+	if len(reviews) == 0 {
+		return fmt.Sprintf("@%s you have no queued or running reviews on this PR.", sender.Login), nil
+	}
+
+	var cancelled []string
+	for _, r := range reviews {
+		if err := i.cancelTask(r); err != nil {
+			log.Warn().Err(err).Uint("review_id", r.ID).Str("task_id", r.TaskID).Msg("Failed to cancel review task")
+			continue
+		}
+
+		completedAt := time.Now()
+		if err := i.store.UpdateReview(r.ID, map[string]interface{}{
+			"status":        "cancelled",
+			"error_message": fmt.Sprintf("cancelled by @%s", sender.Login),
+			"completed_at":  completedAt,
+			"duration_ms":   completedAt.Sub(r.QueuedAt).Milliseconds(),
+		}); err != nil {
+			return "", fmt.Errorf("failed to mark review %d cancelled: %w", r.ID, err)
+		}
+		cancelled = append(cancelled, fmt.Sprintf("#%d (%s)", r.ID, r.Mode))
+	}
+
+	if len(cancelled) == 0 {
+		return fmt.Sprintf("@%s your reviews could not be cancelled; they may have just finished.", sender.Login), nil
+	}
+
+	return fmt.Sprintf("🛑 @%s cancelled %s.", sender.Login, strings.Join(cancelled, ", ")), nil
+}
+
+// cancelTask removes a pending task or signals a running one to stop
+func (i *Inbox) cancelTask(r database.Review) error {
+	if i.inspector == nil || r.TaskID == "" {
+		// Without a task handle the worker still honours the cancelled status
+		return nil
+	}
+
+	err := i.inspector.DeleteTask(i.queue, r.TaskID)
+	if err == nil || errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return nil
+	}
+
+	// Active tasks cannot be deleted; ask the worker to cancel instead
+	return i.inspector.CancelProcessing(r.TaskID)
+}
+
+// queuePosition returns the 1-based position of a pending task, or 0 if unknown
+func (i *Inbox) queuePosition(taskID string) int {
+	if i.inspector == nil || taskID == "" {
+		return 0
+	}
+
+	const pageSize = 100
+	for page := 1; (page-1)*pageSize < maxPositionScan; page++ {
+		pending, err := i.inspector.ListPendingTasks(i.queue, asynq.PageSize(pageSize), asynq.Page(page))
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to list pending tasks")
+			return 0
+		}
+		for idx, info := range pending {
+			if info.ID == taskID {
+				return (page-1)*pageSize + idx + 1
+			}
+		}
+		if len(pending) < pageSize {
+			return 0
+		}
+	}
+
+	return 0
+}
+
+// formatAge renders the time elapsed since t in a compact form
+func formatAge(t time.Time) string {
+	return time.Since(t).Round(time.Second).String()
+}
+
+// approveReviews starts reviews that are waiting for a maintainer's approval
+func (i *Inbox) approveReviews(ctx context.Context, event *gh.WebhookEvent, record *database.WebhookEvent) error {
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+	prNumber := event.PullRequest.Number
+	approver := ""
+	if event.Sender != nil {
+		approver = event.Sender.Login
+	}
+
+	if i.policy != nil {
+		allowed, err := i.policy.CanApprove(ctx, owner, repo, approver)
+		if err != nil {
+			return fmt.Errorf("failed to check approver permission: %w", err)
+		}
+		if !allowed {
+			i.audit(event, record, "denied", "approver lacks write permission", nil)
+			if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "-1"); err != nil {
+				log.Warn().Err(err).Msg("Failed to add -1 reaction")
+			}
+			return nil
+		}
+	}
+
+	pending, err := i.store.ListReviewsByStatus(owner, repo, prNumber, event.IssueNumber(), "awaiting_approval")
+	if err != nil {
+		return fmt.Errorf("failed to list reviews awaiting approval: %w", err)
+	}
+
+	var approved []string
+	for _, r := range pending {
+		origin, err := i.store.FindWebhookEventByReview(r.ID)
+		if err != nil {
+			log.Warn().Err(err).Uint("review_id", r.ID).Msg("Failed to load originating webhook event")
+			continue
+		}
+
+		var original gh.WebhookEvent
+		if err := json.Unmarshal([]byte(origin.Payload), &original); err != nil {
+			log.Warn().Err(err).Uint("review_id", r.ID).Msg("Failed to decode originating webhook event")
+			continue
+		}
+		original.ReviewID = r.ID
+
+		if err := i.store.UpdateReview(r.ID, map[string]interface{}{
+			"status":    "queued",
+			"queued_at": time.Now(),
+		}); err != nil {
+			return fmt.Errorf("failed to queue approved review %d: %w", r.ID, err)
+		}
+
+		if err := i.enqueueReview(&original, r.CommitSHA, r.TaskID, false); err != nil {
+			// Leave the review pending so the retried approval picks it up again
+			if resetErr := i.store.UpdateReview(r.ID, map[string]interface{}{
+				"status": "awaiting_approval",
+			}); resetErr != nil {
+				log.Warn().Err(resetErr).Uint("review_id", r.ID).Msg("Failed to reset review awaiting approval")
+			}
+			return err
+		}
+
+		reviewID := r.ID
+		i.audit(event, record, "approved", fmt.Sprintf("approved review requested by @%s", r.RequestedBy), &reviewID)
+		approved = append(approved, fmt.Sprintf("#%d (%s, requested by @%s)", r.ID, r.Mode, r.RequestedBy))
+	}
+
+	body := fmt.Sprintf("@%s there are no reviews awaiting approval here.", approver)
+	if len(approved) > 0 {
+		body = fmt.Sprintf("✅ @%s approved %s.", approver, strings.Join(approved, ", "))
+	}
+	if err := i.githubClient.CreateComment(ctx, owner, repo, event.ThreadNumber(), body); err != nil {
+		return fmt.Errorf("failed to reply to approve command: %w", err)
+	}
+
+	if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "+1"); err != nil {
+		log.Warn().Err(err).Msg("Failed to add +1 reaction")
+	}
+
+	return nil
+}
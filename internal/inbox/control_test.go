@@ -0,0 +1,421 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/database"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/policy"
+	"github.com/CREVIOS/revo/internal/tasks"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/google/go-github/v60/github"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+type fakeStore struct {
+	reviews map[uint]*database.Review
+	events  []*database.WebhookEvent
+	audits  []*database.AuditLog
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{reviews: map[uint]*database.Review{}}
+}
+
+func (s *fakeStore) CreateWebhookEvent(event *database.WebhookEvent) error {
+	event.ID = uint(len(s.events) + 1)
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *fakeStore) GetWebhookEvent(id uint) (*database.WebhookEvent, error) {
+	for _, event := range s.events {
+		if event.ID == id {
+			return event, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeStore) FindWebhookEventByDelivery(deliveryID string) (*database.WebhookEvent, error) {
+	for _, event := range s.events {
+		if event.DeliveryID == deliveryID {
+			return event, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeStore) FindWebhookEventByReview(reviewID uint) (*database.WebhookEvent, error) {
+	for _, event := range s.events {
+		if event.ReviewID != nil && *event.ReviewID == reviewID {
+			return event, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeStore) UpdateWebhookEvent(id uint, updates map[string]interface{}) error {
+	event, err := s.GetWebhookEvent(id)
+	if err != nil {
+		return err
+	}
+	if reviewID, ok := updates["review_id"].(uint); ok {
+		event.ReviewID = &reviewID
+	}
+	if status, ok := updates["status"].(string); ok {
+		event.Status = status
+	}
+	if processedAt, ok := updates["processed_at"].(time.Time); ok {
+		event.ProcessedAt = &processedAt
+	}
+	return nil
+}
+
+func (s *fakeStore) CreateReview(review *database.Review) error {
+	review.ID = uint(len(s.reviews) + 1)
+	s.reviews[review.ID] = review
+	return nil
+}
+
+func (s *fakeStore) GetReview(id uint) (*database.Review, error) {
+	if review, ok := s.reviews[id]; ok {
+		return review, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeStore) UpdateReview(id uint, updates map[string]interface{}) error {
+	review, err := s.GetReview(id)
+	if err != nil {
+		return err
+	}
+	if status, ok := updates["status"].(string); ok {
+		review.Status = status
+	}
+	if message, ok := updates["error_message"].(string); ok {
+		review.ErrorMessage = message
+	}
+	if requestedAt, ok := updates["approval_requested_at"].(time.Time); ok {
+		review.ApprovalRequestedAt = &requestedAt
+	}
+	return nil
+}
+
+func (s *fakeStore) ListActiveReviews(owner, repo string, prNumber, issueNumber int, requestedBy string) ([]database.Review, error) {
+	var reviews []database.Review
+	for id := uint(1); id <= uint(len(s.reviews)); id++ {
+		r := s.reviews[id]
+		if r.Owner != owner || r.Repo != repo || r.PRNumber != prNumber || r.IssueNumber != issueNumber {
+			continue
+		}
+		if r.Status != "queued" && r.Status != "processing" {
+			continue
+		}
+		if requestedBy != "" && r.RequestedBy != requestedBy {
+			continue
+		}
+		reviews = append(reviews, *r)
+	}
+	return reviews, nil
+}
+
+func (s *fakeStore) ListReviewsByStatus(owner, repo string, prNumber, issueNumber int, status string) ([]database.Review, error) {
+	var reviews []database.Review
+	for id := uint(1); id <= uint(len(s.reviews)); id++ {
+		r := s.reviews[id]
+		if r.Owner == owner && r.Repo == repo && r.PRNumber == prNumber && r.IssueNumber == issueNumber && r.Status == status {
+			reviews = append(reviews, *r)
+		}
+	}
+	return reviews, nil
+}
+
+func (s *fakeStore) UpsertRepository(repo *database.Repository) error {
+	return nil
+}
+
+func (s *fakeStore) CreateAuditLog(entry *database.AuditLog) error {
+	s.audits = append(s.audits, entry)
+	return nil
+}
+
+type fakeGitHub struct {
+	comments   []string
+	reactions  []string
+	commentErr error
+}
+
+func (g *fakeGitHub) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*github.PullRequest, error) {
+	return &github.PullRequest{Head: &github.PullRequestBranch{SHA: github.String("abc123")}}, nil
+}
+
+func (g *fakeGitHub) GetPullRequestFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error) {
+	return nil, nil
+}
+
+func (g *fakeGitHub) CreateComment(ctx context.Context, owner, repo string, number int, body string) error {
+	if g.commentErr != nil {
+		return g.commentErr
+	}
+	g.comments = append(g.comments, body)
+	return nil
+}
+
+func (g *fakeGitHub) ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error {
+	g.comments = append(g.comments, body)
+	return nil
+}
+
+func (g *fakeGitHub) AddReaction(ctx context.Context, owner, repo string, commentID int64, reaction string) error {
+	g.reactions = append(g.reactions, reaction)
+	return nil
+}
+
+type fakeQueue struct {
+	tasks []*asynq.Task
+	err   error
+}
+
+func (q *fakeQueue) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	q.tasks = append(q.tasks, task)
+	return &asynq.TaskInfo{}, nil
+}
+
+type fakePermissions map[string]string
+
+func (p fakePermissions) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	if level, ok := p[user]; ok {
+		return level, nil
+	}
+	return "none", nil
+}
+
+func (p fakePermissions) IsOrgMember(ctx context.Context, owner, repo, org, user string) (bool, error) {
+	return false, nil
+}
+
+// newTestInbox returns an inbox whose maintainer has write access and whose
+// contributor is a first-time contributor held for approval
+func newTestInbox() (*Inbox, *fakeStore, *fakeGitHub, *fakeQueue) {
+	store := newFakeStore()
+	client := &fakeGitHub{}
+	queue := &fakeQueue{}
+	checker := policy.NewChecker(fakePermissions{"maintainer": "write"}, nil, policy.Config{
+		Enabled:         true,
+		MinPermission:   "write",
+		RequireApproval: true,
+	})
+	return &Inbox{
+		store:        store,
+		githubClient: client,
+		asynqClient:  queue,
+		policy:       checker,
+		botUsername:  "techy",
+		queue:        "default",
+	}, store, client, queue
+}
+
+func commandEvent(sender, raw string, command *models.Command) *gh.WebhookEvent {
+	command.Raw = raw
+	return &gh.WebhookEvent{
+		EventType:   "issue_comment",
+		Action:      "created",
+		Repository:  &gh.Repository{Name: "api", FullName: "acme/api", Owner: &gh.User{Login: "acme"}},
+		PullRequest: &gh.PullRequest{Number: 7, Head: &gh.Branch{SHA: "abc123"}},
+		Comment:     &gh.Comment{ID: 100, Body: raw, AuthorAssociation: "FIRST_TIME_CONTRIBUTOR"},
+		Sender:      &gh.User{Login: sender},
+		Command:     command,
+	}
+}
+
+// addPendingReview stores a review awaiting approval with the webhook event that requested it
+func addPendingReview(t *testing.T, store *fakeStore) *database.Review {
+	t.Helper()
+	review := &database.Review{
+		Owner:       "acme",
+		Repo:        "api",
+		PRNumber:    7,
+		CommitSHA:   "abc123",
+		Mode:        "hunt",
+		Status:      "awaiting_approval",
+		QueuedAt:    time.Now(),
+		RequestedBy: "newcomer",
+		TaskID:      "review:acme/api/7:abc123:hunt",
+	}
+	if err := store.CreateReview(review); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(commandEvent("newcomer", "@techy hunt", &models.Command{Mode: models.ModeHunt}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateWebhookEvent(&database.WebhookEvent{Payload: string(payload), ReviewID: &review.ID}); err != nil {
+		t.Fatal(err)
+	}
+	return review
+}
+
+func TestApproveReviews(t *testing.T) {
+	tests := []struct {
+		name       string
+		approver   string
+		pending    bool
+		wantStatus string
+		wantTasks  int
+		wantReply  string
+		wantReact  string
+	}{
+		{
+			name:       "maintainer approves",
+			approver:   "maintainer",
+			pending:    true,
+			wantStatus: "queued",
+			wantTasks:  1,
+			wantReply:  "✅ @maintainer approved #1 (hunt, requested by @newcomer).",
+			wantReact:  "+1",
+		},
+		{
+			name:       "contributor cannot approve",
+			approver:   "newcomer",
+			pending:    true,
+			wantStatus: "awaiting_approval",
+			wantReact:  "-1",
+		},
+		{
+			name:      "nothing to approve",
+			approver:  "maintainer",
+			wantReply: "@maintainer there are no reviews awaiting approval here.",
+			wantReact: "+1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inbox, store, client, queue := newTestInbox()
+			var pending *database.Review
+			if tt.pending {
+				pending = addPendingReview(t, store)
+			}
+
+			event := commandEvent(tt.approver, "@techy approve", &models.Command{Action: models.ActionApprove})
+			if err := inbox.handleCommand(context.Background(), event, &database.WebhookEvent{}); err != nil {
+				t.Fatalf("handleCommand() error = %v", err)
+			}
+
+			if pending != nil && pending.Status != tt.wantStatus {
+				t.Errorf("review status = %q, want %q", pending.Status, tt.wantStatus)
+			}
+			if len(queue.tasks) != tt.wantTasks {
+				t.Fatalf("enqueued %d tasks, want %d", len(queue.tasks), tt.wantTasks)
+			}
+			if tt.wantTasks > 0 {
+				payload, err := tasks.ParseReviewTask(queue.tasks[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if payload.ReviewID != pending.ID || payload.SenderLogin != "newcomer" || payload.Mode != "hunt" {
+					t.Errorf("review task = %+v, want the original request for review %d", payload, pending.ID)
+				}
+			}
+			var reply string
+			if len(client.comments) > 0 {
+				reply = client.comments[len(client.comments)-1]
+			}
+			if reply != tt.wantReply {
+				t.Errorf("reply = %q, want %q", reply, tt.wantReply)
+			}
+			if len(client.reactions) == 0 || client.reactions[len(client.reactions)-1] != tt.wantReact {
+				t.Errorf("reactions = %q, want %q last", client.reactions, tt.wantReact)
+			}
+		})
+	}
+}
+
+func TestApproveReviewsEnqueueFailure(t *testing.T) {
+	inbox, store, client, queue := newTestInbox()
+	pending := addPendingReview(t, store)
+	queue.err = errors.New("redis unavailable")
+
+	event := commandEvent("maintainer", "@techy approve", &models.Command{Action: models.ActionApprove})
+	if err := inbox.handleCommand(context.Background(), event, &database.WebhookEvent{}); !errors.Is(err, queue.err) {
+		t.Fatalf("handleCommand() error = %v, want %v", err, queue.err)
+	}
+	if pending.Status != "awaiting_approval" {
+		t.Errorf("review status = %q, want awaiting_approval so a retry approves it", pending.Status)
+	}
+	if len(client.comments) != 0 {
+		t.Errorf("replies = %q, want none before the review is queued", client.comments)
+	}
+
+	// The retried approve command queues it
+	queue.err = nil
+	if err := inbox.handleCommand(context.Background(), event, &database.WebhookEvent{}); err != nil {
+		t.Fatalf("handleCommand() retry error = %v", err)
+	}
+	if pending.Status != "queued" || len(queue.tasks) != 1 {
+		t.Errorf("review status = %q with %d tasks, want queued with 1", pending.Status, len(queue.tasks))
+	}
+}
+
+func TestCancelReviews(t *testing.T) {
+	inbox, store, client, _ := newTestInbox()
+	mine := &database.Review{Owner: "acme", Repo: "api", PRNumber: 7, Mode: "hunt", Status: "queued", RequestedBy: "maintainer"}
+	running := &database.Review{Owner: "acme", Repo: "api", PRNumber: 7, Mode: "security", Status: "processing", RequestedBy: "maintainer"}
+	theirs := &database.Review{Owner: "acme", Repo: "api", PRNumber: 7, Mode: "hunt", Status: "queued", RequestedBy: "someone"}
+	done := &database.Review{Owner: "acme", Repo: "api", PRNumber: 7, Mode: "hunt", Status: "completed", RequestedBy: "maintainer"}
+	other := &database.Review{Owner: "acme", Repo: "api", PRNumber: 8, Mode: "hunt", Status: "queued", RequestedBy: "maintainer"}
+	for _, r := range []*database.Review{mine, running, theirs, done, other} {
+		if err := store.CreateReview(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	event := commandEvent("maintainer", "@techy cancel", &models.Command{Action: models.ActionCancel})
+	if err := inbox.handleCommand(context.Background(), event, &database.WebhookEvent{}); err != nil {
+		t.Fatalf("handleCommand() error = %v", err)
+	}
+
+	want := map[*database.Review]string{
+		mine:    "cancelled",
+		running: "cancelled",
+		theirs:  "queued",
+		done:    "completed",
+		other:   "queued",
+	}
+	for r, status := range want {
+		if r.Status != status {
+			t.Errorf("review %d status = %q, want %q", r.ID, r.Status, status)
+		}
+	}
+	if mine.ErrorMessage != "cancelled by @maintainer" {
+		t.Errorf("error message = %q", mine.ErrorMessage)
+	}
+	if len(client.comments) != 1 || client.comments[0] != "🛑 @maintainer cancelled #1 (hunt), #2 (security)." {
+		t.Errorf("replies = %q", client.comments)
+	}
+
+	// A second cancel finds nothing left
+	if err := inbox.handleCommand(context.Background(), event, &database.WebhookEvent{}); err != nil {
+		t.Fatalf("handleCommand() error = %v", err)
+	}
+	if reply := client.comments[len(client.comments)-1]; !strings.HasPrefix(reply, "@maintainer you have no queued or running reviews") {
+		t.Errorf("reply = %q", reply)
+	}
+}
+
+func TestCancelReviewsWithoutSender(t *testing.T) {
+	inbox, _, _, _ := newTestInbox()
+	if _, err := inbox.cancelReviews("acme", "api", 7, 0, nil); err == nil {
+		t.Error("cancelReviews() error = nil, want an error for a missing sender")
+	}
+}
@@ -0,0 +1,99 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/CREVIOS/revo/internal/autofix"
+	"github.com/CREVIOS/revo/internal/database"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/rs/zerolog/log"
+)
+
+// fixFinding commits the suggested change of the finding a `fix` command replies to
+func (i *Inbox) fixFinding(ctx context.Context, event *gh.WebhookEvent, record *database.WebhookEvent) error {
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+	prNumber := event.PullRequest.Number
+	if event.Sender == nil || event.Sender.Login == "" {
+		return fmt.Errorf("fix command has no sender")
+	}
+	requester := event.Sender.Login
+
+	if i.policy != nil {
+		allowed, err := i.policy.CanFix(ctx, owner, repo, requester)
+		if err != nil {
+			return fmt.Errorf("failed to check fix permission: %w", err)
+		}
+		if !allowed {
+			i.audit(event, record, "denied", "requester lacks write permission for fix", nil)
+			if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "-1"); err != nil {
+				log.Warn().Err(err).Msg("Failed to add -1 reaction")
+			}
+			return nil
+		}
+	}
+
+	findingID := event.Comment.InReplyToID
+	if event.EventType != "pull_request_review_comment" || findingID == 0 {
+		body := fmt.Sprintf("@%s reply `@%s fix` in the thread of one of my inline findings that has a suggested change.", requester, i.botUsername)
+		if err := i.githubClient.CreateComment(ctx, owner, repo, prNumber, body); err != nil {
+			return fmt.Errorf("failed to reply to fix command: %w", err)
+		}
+		return nil
+	}
+
+	result, err := i.fixer.Fix(ctx, autofix.Request{
+		Owner:     owner,
+		Repo:      repo,
+		PRNumber:  prNumber,
+		CommentID: findingID,
+		Requester: event.Sender,
+	})
+
+	var body string
+	switch {
+	case err == nil && result.StackedPR > 0:
+		body = fmt.Sprintf("✅ @%s the PR branch is in a fork I cannot push to, so I opened #%d with the fix (`%s`) on top of it.",
+			requester, result.StackedPR, shortSHA(result.CommitSHA))
+	case err == nil:
+		body = fmt.Sprintf("✅ @%s committed the fix to `%s` in %s.", requester, result.Branch, shortSHA(result.CommitSHA))
+	case errors.Is(err, autofix.ErrAlreadyApplied):
+		body = fmt.Sprintf("@%s the suggested change is already on the PR branch.", requester)
+	case errors.Is(err, autofix.ErrNoSuggestion), errors.Is(err, autofix.ErrNotFinding), errors.Is(err, autofix.ErrConflict):
+		body = fmt.Sprintf("⚠️ @%s couldn't apply the fix: %v", requester, err)
+	default:
+		return fmt.Errorf("failed to apply fix: %w", err)
+	}
+
+	decision, reason := "fixed", ""
+	if err != nil {
+		decision, reason = "fix_skipped", err.Error()
+	} else {
+		reason = fmt.Sprintf("committed %s to %s", shortSHA(result.CommitSHA), result.Branch)
+	}
+	i.audit(event, record, decision, reason, nil)
+
+	if err := i.githubClient.ReplyToReviewComment(ctx, owner, repo, prNumber, findingID, body); err != nil {
+		return fmt.Errorf("failed to reply to fix command: %w", err)
+	}
+
+	log.Info().
+		Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+		Int("pr", prNumber).
+		Int64("finding_comment_id", findingID).
+		Str("actor", requester).
+		Str("decision", decision).
+		Msg("Handled fix command")
+
+	return nil
+}
+
+// shortSHA abbreviates a commit SHA for replies
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
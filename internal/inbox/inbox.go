@@ -0,0 +1,559 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/autofix"
+	"github.com/CREVIOS/revo/internal/database"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/migrations"
+	"github.com/CREVIOS/revo/internal/policy"
+	"github.com/CREVIOS/revo/internal/tasks"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/google/go-github/v60/github"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Store persists webhook events, reviews and the audit log
+type Store interface {
+	CreateWebhookEvent(event *database.WebhookEvent) error
+	GetWebhookEvent(id uint) (*database.WebhookEvent, error)
+	FindWebhookEventByDelivery(deliveryID string) (*database.WebhookEvent, error)
+	FindWebhookEventByReview(reviewID uint) (*database.WebhookEvent, error)
+	UpdateWebhookEvent(id uint, updates map[string]interface{}) error
+	CreateReview(review *database.Review) error
+	GetReview(id uint) (*database.Review, error)
+	UpdateReview(id uint, updates map[string]interface{}) error
+	ListActiveReviews(owner, repo string, prNumber, issueNumber int, requestedBy string) ([]database.Review, error)
+	ListReviewsByStatus(owner, repo string, prNumber, issueNumber int, status string) ([]database.Review, error)
+	UpsertRepository(repo *database.Repository) error
+	CreateAuditLog(entry *database.AuditLog) error
+}
+
+// GitHub is the part of the GitHub API the inbox replies through
+type GitHub interface {
+	GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*github.PullRequest, error)
+	GetPullRequestFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error)
+	CreateComment(ctx context.Context, owner, repo string, number int, body string) error
+	ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error
+	AddReaction(ctx context.Context, owner, repo string, commentID int64, reaction string) error
+}
+
+// TaskQueue enqueues ingest and review tasks
+type TaskQueue interface {
+	Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+// Inbox durably records webhook commands and turns them into review tasks.
+// Accept runs in the HTTP handler; Process runs in the worker as the
+// "webhook:ingest" task so every downstream step is retried by asynq.
+type Inbox struct {
+	store        Store
+	githubClient GitHub
+	asynqClient  TaskQueue
+	inspector    *asynq.Inspector
+	policy       *policy.Checker
+	fixer        *autofix.Fixer
+	botUsername  string
+	migrations   []string
+	queue        string
+	maxRetry     int
+}
+
+// New creates a new Inbox
+func New(store *database.Store, githubClient *gh.Client, asynqClient *asynq.Client, botUsername, queue string, maxRetry int) *Inbox {
+	return &Inbox{
+		store:        store,
+		githubClient: githubClient,
+		asynqClient:  asynqClient,
+		fixer:        autofix.New(githubClient, store),
+		botUsername:  botUsername,
+		queue:        queue,
+		maxRetry:     maxRetry,
+	}
+}
+
+// SetInspector sets the asynq inspector used by the status and cancel commands
+func (i *Inbox) SetInspector(inspector *asynq.Inspector) {
+	i.inspector = inspector
+}
+
+// SetMigrationPaths sets the globs of migration files whose changes trigger
+// the automatic migration check
+func (i *Inbox) SetMigrationPaths(patterns []string) {
+	i.migrations = patterns
+}
+
+// SetPolicy sets the authorization policy for review requests
+func (i *Inbox) SetPolicy(checker *policy.Checker) {
+	i.policy = checker
+}
+
+// Accept persists a parsed webhook event and enqueues it for ingestion.
+// It returns only after both the database row and the task exist.
+func (i *Inbox) Accept(event *gh.WebhookEvent) error {
+	record, err := i.persist(event)
+	if err != nil {
+		return err
+	}
+
+	if record.ProcessedAt != nil {
+		log.Info().
+			Str("delivery_id", record.DeliveryID).
+			Uint("webhook_event_id", record.ID).
+			Msg("Webhook delivery already processed, ignoring redelivery")
+		return nil
+	}
+
+	task, err := tasks.NewIngestTask(tasks.IngestPayload{WebhookEventID: record.ID})
+	if err != nil {
+		return fmt.Errorf("failed to build ingest task: %w", err)
+	}
+
+	_, err = i.asynqClient.Enqueue(
+		task,
+		asynq.Queue(i.queue),
+		asynq.MaxRetry(i.maxRetry),
+		asynq.TaskID(fmt.Sprintf("ingest:%d", record.ID)),
+	)
+	if err != nil {
+		if errors.Is(err, asynq.ErrDuplicateTask) || errors.Is(err, asynq.ErrTaskIDConflict) {
+			log.Info().
+				Uint("webhook_event_id", record.ID).
+				Msg("Ingest task already enqueued")
+			return nil
+		}
+		_ = i.store.UpdateWebhookEvent(record.ID, map[string]interface{}{
+			"status":        "failed",
+			"error_message": fmt.Sprintf("enqueue ingest task: %v", err),
+		})
+		return fmt.Errorf("failed to enqueue ingest task: %w", err)
+	}
+
+	log.Info().
+		Uint("webhook_event_id", record.ID).
+		Str("delivery_id", record.DeliveryID).
+		Str("repo", event.Repository.FullName).
+		Msg("Webhook event accepted")
+
+	return nil
+}
+
+// persist stores the event, reusing the existing row for redelivered webhooks
+func (i *Inbox) persist(event *gh.WebhookEvent) (*database.WebhookEvent, error) {
+	if event.DeliveryID != "" {
+		existing, err := i.store.FindWebhookEventByDelivery(event.DeliveryID)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up webhook delivery: %w", err)
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	record := &database.WebhookEvent{
+		EventType:  event.EventType,
+		DeliveryID: event.DeliveryID,
+		Owner:      event.Repository.Owner.Login,
+		Repo:       event.Repository.Name,
+		Action:     event.Action,
+		Payload:    string(payload),
+		Status:     "received",
+	}
+	if event.PullRequest != nil {
+		record.PRNumber = event.PullRequest.Number
+	}
+
+	if err := i.store.CreateWebhookEvent(record); err != nil {
+		return nil, fmt.Errorf("failed to persist webhook event: %w", err)
+	}
+
+	return record, nil
+}
+
+// Process handles a "webhook:ingest" task: it acknowledges the command on
+// GitHub, records the review and enqueues the review task.
+func (i *Inbox) Process(ctx context.Context, task *asynq.Task) error {
+	payload, err := tasks.ParseIngestTask(task)
+	if err != nil {
+		return fmt.Errorf("invalid ingest payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	record, err := i.store.GetWebhookEvent(payload.WebhookEventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("webhook event %d not found: %w", payload.WebhookEventID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to load webhook event: %w", err)
+	}
+
+	if record.ProcessedAt != nil {
+		return nil
+	}
+
+	var event gh.WebhookEvent
+	if err := json.Unmarshal([]byte(record.Payload), &event); err != nil {
+		_ = i.store.UpdateWebhookEvent(record.ID, map[string]interface{}{
+			"status":        "failed",
+			"error_message": fmt.Sprintf("decode payload: %v", err),
+		})
+		return fmt.Errorf("failed to decode webhook event %d: %v: %w", record.ID, err, asynq.SkipRetry)
+	}
+	if record.ReviewID != nil {
+		event.ReviewID = *record.ReviewID
+	}
+
+	if err := i.handleCommand(ctx, &event, record); err != nil {
+		_ = i.store.UpdateWebhookEvent(record.ID, map[string]interface{}{
+			"status":        "failed",
+			"error_message": err.Error(),
+			"attempts":      gorm.Expr("attempts + 1"),
+		})
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"status":        "processed",
+		"error_message": "",
+		"processed_at":  time.Now(),
+		"attempts":      gorm.Expr("attempts + 1"),
+	}
+	if event.ReviewID > 0 {
+		updates["review_id"] = event.ReviewID
+	}
+	if err := i.store.UpdateWebhookEvent(record.ID, updates); err != nil {
+		log.Warn().Err(err).Uint("webhook_event_id", record.ID).Msg("Failed to mark webhook event processed")
+	}
+
+	return nil
+}
+
+// handleCommand performs the work that used to run in the webhook goroutine.
+// Every step is safe to repeat when the ingest task is retried.
+func (i *Inbox) handleCommand(ctx context.Context, event *gh.WebhookEvent, record *database.WebhookEvent) error {
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+	prNumber := event.PullRequest.Number
+	commitSHA := ""
+	senderLogin := ""
+	if event.Sender != nil {
+		senderLogin = event.Sender.Login
+	}
+
+	if event.CommandError != "" {
+		return i.replyCommandError(ctx, event)
+	}
+	if event.Command.Action == models.ActionApprove {
+		return i.approveReviews(ctx, event, record)
+	}
+	if event.Command.Action == models.ActionFix {
+		return i.fixFinding(ctx, event, record)
+	}
+	if event.Command.IsControl() {
+		return i.handleControl(ctx, event)
+	}
+	if event.EventType == "pull_request" && event.Command.Mode == models.ModeMigrations {
+		changed, err := i.changesMigrations(ctx, owner, repo, prNumber)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			log.Debug().
+				Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+				Int("pr", prNumber).
+				Msg("No migration files changed, skipping automatic migration check")
+			return nil
+		}
+	}
+
+	decision := policy.Decision{Outcome: policy.Allowed}
+	// Pushes are authorized by the branch configuration, not by the pusher
+	if i.policy != nil && event.ReviewID == 0 && !event.Target.IsPush() {
+		var err error
+		decision, err = i.policy.Authorize(ctx, owner, repo, senderLogin, event.Comment.AuthorAssociation)
+		if err != nil {
+			return fmt.Errorf("failed to authorize request: %w", err)
+		}
+	}
+
+	if decision.Outcome == policy.Denied {
+		log.Info().
+			Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+			Int("pr", prNumber).
+			Str("actor", senderLogin).
+			Str("reason", decision.Reason).
+			Msg("Review request denied by policy")
+		i.audit(event, record, string(decision.Outcome), decision.Reason, nil)
+		if event.Comment.ID != 0 {
+			if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "-1"); err != nil {
+				log.Warn().Err(err).Msg("Failed to add -1 reaction")
+			}
+		}
+		return nil
+	}
+
+	// Automatic runs, e.g. on opened PRs, were not asked for by anyone, so there
+	// is nothing to approve; a maintainer can request the mode instead
+	if decision.Outcome == policy.PendingApproval && event.Comment.ID == 0 {
+		log.Info().
+			Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+			Int("pr", prNumber).
+			Str("actor", senderLogin).
+			Str("mode", string(event.Command.Mode)).
+			Msg("Skipping automatic run for a contributor awaiting approval")
+		i.audit(event, record, string(decision.Outcome), decision.Reason, nil)
+		return nil
+	}
+
+	// Add eyes reaction to acknowledge we've seen the request.
+	// GitHub returns the existing reaction on retries, so this is idempotent.
+	if event.Comment.ID != 0 {
+		if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "eyes"); err != nil {
+			log.Warn().Err(err).Msg("Failed to add eyes reaction")
+		}
+	}
+
+	if event.PullRequest.Head != nil {
+		commitSHA = event.PullRequest.Head.SHA
+	}
+	if commitSHA == "" && event.Target.IsPullRequest() {
+		pr, err := i.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to resolve PR head: %w", err)
+		}
+		commitSHA = pr.GetHead().GetSHA()
+		if event.PullRequest.Title == "" {
+			event.PullRequest.Title = pr.GetTitle()
+		}
+	}
+
+	if err := i.store.UpsertRepository(&database.Repository{
+		Owner:     owner,
+		Name:      repo,
+		FullName:  event.Repository.FullName,
+		IsPrivate: event.Repository.Private,
+		IsActive:  true,
+	}); err != nil {
+		log.Warn().Err(err).Msg("Failed to upsert repository")
+	}
+
+	// Commands with other modes or options on the same commit are separate tasks
+	commandKey := event.Command.Key()
+	taskID := fmt.Sprintf("review:%s/%s/%d", owner, repo, prNumber)
+	if commitSHA != "" {
+		taskID = fmt.Sprintf("%s:%s", taskID, commitSHA)
+	}
+	taskID = fmt.Sprintf("%s:%s", taskID, commandKey)
+	if target := event.Target; !target.IsPullRequest() {
+		taskID = fmt.Sprintf("review:%s/%s:%s:%s:%s", owner, repo, target.Kind, target.Head, commandKey)
+		switch target.Kind {
+		case models.TargetIssue:
+			// Every question on an issue is its own request
+			taskID = fmt.Sprintf("review:%s/%s:%s:%d:%s:%d", owner, repo, target.Kind, target.Issue, event.Command.Mode, event.Comment.ID)
+		case models.TargetCompare:
+			taskID = fmt.Sprintf("review:%s/%s:%s:%s...%s:%s", owner, repo, target.Kind, target.Base, target.Head, commandKey)
+		}
+	}
+
+	createdReview := false
+	if event.ReviewID == 0 {
+		status := "queued"
+		if decision.Outcome == policy.PendingApproval {
+			status = "awaiting_approval"
+		}
+
+		reviewRecord := &database.Review{
+			Owner:       owner,
+			Repo:        repo,
+			PRNumber:    prNumber,
+			PRTitle:     event.PullRequest.Title,
+			CommitSHA:   commitSHA,
+			Mode:        string(event.Command.Mode),
+			Status:      status,
+			QueuedAt:    time.Now(),
+			RequestedBy: senderLogin,
+			TaskID:      taskID,
+		}
+		if target := event.Target; !target.IsPullRequest() {
+			reviewRecord.TargetKind = string(target.Kind)
+			reviewRecord.BaseSHA = target.Base
+			reviewRecord.Ref = target.Ref
+			reviewRecord.IssueNumber = target.Issue
+		}
+		if err := i.store.CreateReview(reviewRecord); err != nil {
+			return fmt.Errorf("failed to create review record: %w", err)
+		}
+		event.ReviewID = reviewRecord.ID
+		createdReview = true
+
+		// Link the review immediately so a retry does not create a second one
+		if err := i.store.UpdateWebhookEvent(record.ID, map[string]interface{}{
+			"review_id": reviewRecord.ID,
+		}); err != nil {
+			log.Warn().Err(err).Msg("Failed to link review to webhook event")
+		}
+
+		i.audit(event, record, string(decision.Outcome), decision.Reason, &reviewRecord.ID)
+	} else if existing, err := i.store.GetReview(event.ReviewID); err == nil && existing.Status == "awaiting_approval" {
+		if existing.ApprovalRequestedAt != nil {
+			// A previous attempt already asked for approval
+			return nil
+		}
+		// A previous attempt created the review but failed to ask for approval
+		decision.Outcome = policy.PendingApproval
+	}
+
+	if decision.Outcome == policy.PendingApproval {
+		body := fmt.Sprintf("⏸️ Thanks @%s! Reviews requested by first-time contributors need a maintainer's go-ahead. A maintainer can reply `@%s approve` to start it.",
+			senderLogin, i.botUsername)
+		if err := i.githubClient.CreateComment(ctx, owner, repo, event.ThreadNumber(), body); err != nil {
+			return fmt.Errorf("failed to request approval: %w", err)
+		}
+		if err := i.store.UpdateReview(event.ReviewID, map[string]interface{}{
+			"approval_requested_at": time.Now(),
+		}); err != nil {
+			log.Warn().Err(err).Uint("review_id", event.ReviewID).Msg("Failed to record approval request")
+		}
+		return nil
+	}
+
+	return i.enqueueReview(event, commitSHA, taskID, createdReview)
+}
+
+// enqueueReview schedules the review task for an event whose review record exists.
+// createdReview reports whether the record was created by this attempt.
+func (i *Inbox) enqueueReview(event *gh.WebhookEvent, commitSHA, taskID string, createdReview bool) error {
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+	prNumber := event.PullRequest.Number
+	senderLogin := ""
+	if event.Sender != nil {
+		senderLogin = event.Sender.Login
+	}
+
+	payload := tasks.ReviewPayload{
+		EventType:   event.EventType,
+		Action:      event.Action,
+		Owner:       owner,
+		Repo:        repo,
+		PRNumber:    prNumber,
+		CommentID:   event.Comment.ID,
+		CommentBody: event.Comment.Body,
+		SenderLogin: senderLogin,
+		Mode:        string(event.Command.Mode),
+		Verbose:     event.Command.Verbose,
+		CommitSHA:   commitSHA,
+		ReviewID:    event.ReviewID,
+		Options:     event.Command.Options,
+		Target:      event.Target,
+	}
+
+	task, err := tasks.NewReviewTask(payload)
+	if err != nil {
+		return fmt.Errorf("failed to build review task: %w", err)
+	}
+
+	_, err = i.asynqClient.Enqueue(
+		task,
+		asynq.Queue(i.queue),
+		asynq.MaxRetry(i.maxRetry),
+		asynq.TaskID(taskID),
+	)
+	if err != nil {
+		if errors.Is(err, asynq.ErrDuplicateTask) || errors.Is(err, asynq.ErrTaskIDConflict) {
+			log.Info().Err(err).Msg("Duplicate review task ignored")
+			if !createdReview {
+				// A previous attempt of this ingest task already enqueued it
+				return nil
+			}
+			completedAt := time.Now()
+			_ = i.store.UpdateReview(event.ReviewID, map[string]interface{}{
+				"status":        "cancelled",
+				"error_message": "duplicate task",
+				"completed_at":  completedAt,
+				"duration_ms":   int64(0),
+			})
+			return nil
+		}
+		return fmt.Errorf("failed to enqueue review task: %w", err)
+	}
+
+	log.Info().
+		Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+		Int("pr", prNumber).
+		Uint("review_id", event.ReviewID).
+		Str("task_id", taskID).
+		Msg("Review task enqueued")
+
+	return nil
+}
+
+// audit records an authorization decision
+func (i *Inbox) audit(event *gh.WebhookEvent, record *database.WebhookEvent, decision, reason string, reviewID *uint) {
+	entry := &database.AuditLog{
+		Owner:    event.Repository.Owner.Login,
+		Repo:     event.Repository.Name,
+		PRNumber: event.PullRequest.Number,
+		Command:  event.Command.Raw,
+		Decision: decision,
+		Reason:   reason,
+		ReviewID: reviewID,
+	}
+	if event.Sender != nil {
+		entry.Actor = event.Sender.Login
+	}
+	if record != nil {
+		entry.WebhookEventID = &record.ID
+	}
+
+	if err := i.store.CreateAuditLog(entry); err != nil {
+		log.Warn().Err(err).Msg("Failed to write audit log")
+	}
+}
+
+// replyCommandError tells the user why their command could not be parsed
+func (i *Inbox) replyCommandError(ctx context.Context, event *gh.WebhookEvent) error {
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+
+	if err := i.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "confused"); err != nil {
+		log.Warn().Err(err).Msg("Failed to add confused reaction")
+	}
+
+	body := fmt.Sprintf("❌ **TechyBot** couldn't understand `%s`: %s\n\nUsage: %s",
+		event.Command.Raw, event.CommandError, gh.CommandUsage(i.botUsername, owner, repo))
+	if err := i.githubClient.CreateComment(ctx, owner, repo, event.ThreadNumber(), body); err != nil {
+		return fmt.Errorf("failed to reply to malformed command: %w", err)
+	}
+
+	return nil
+}
+
+// changesMigrations reports whether a pull request changes a file under the
+// configured migration paths
+func (i *Inbox) changesMigrations(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	if len(i.migrations) == 0 {
+		return false, nil
+	}
+	files, err := i.githubClient.GetPullRequestFiles(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("failed to list PR files: %w", err)
+	}
+	for _, file := range files {
+		if migrations.IsMigration(i.migrations, file.GetFilename()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,60 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+func TestProcessRetriesApprovalRequest(t *testing.T) {
+	inbox, store, client, queue := newTestInbox()
+	event := commandEvent("newcomer", "@techy hunt", &models.Command{Mode: models.ModeHunt})
+	event.DeliveryID = "delivery-1"
+	if err := inbox.Accept(event); err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	ingest := queue.tasks[0]
+	queue.tasks = nil
+
+	// The approval prompt fails to post, so the ingest task is retried
+	client.commentErr = errors.New("github unavailable")
+	if err := inbox.Process(context.Background(), ingest); err == nil {
+		t.Fatal("Process() error = nil, want the comment failure")
+	}
+	review, err := store.GetReview(1)
+	if err != nil {
+		t.Fatalf("review not created: %v", err)
+	}
+	if review.Status != "awaiting_approval" || review.ApprovalRequestedAt != nil {
+		t.Fatalf("review = %q, approval requested at %v; want awaiting approval, not yet requested", review.Status, review.ApprovalRequestedAt)
+	}
+
+	client.commentErr = nil
+	if err := inbox.Process(context.Background(), ingest); err != nil {
+		t.Fatalf("Process() retry error = %v", err)
+	}
+	if len(client.comments) != 1 || !strings.Contains(client.comments[0], "`@techy approve`") {
+		t.Fatalf("comments = %q, want one approval request", client.comments)
+	}
+	if review.ApprovalRequestedAt == nil {
+		t.Error("ApprovalRequestedAt not set after the approval request was posted")
+	}
+	if len(store.reviews) != 1 {
+		t.Errorf("retry created %d reviews, want 1", len(store.reviews))
+	}
+	if len(queue.tasks) != 0 {
+		t.Errorf("enqueued %d review tasks before approval, want 0", len(queue.tasks))
+	}
+
+	// Once asked, a later retry of the same command does not ask again
+	event.ReviewID = review.ID
+	if err := inbox.handleCommand(context.Background(), event, store.events[0]); err != nil {
+		t.Fatalf("handleCommand() error = %v", err)
+	}
+	if len(client.comments) != 1 || len(queue.tasks) != 0 {
+		t.Errorf("comments = %q, tasks = %d; want the approval request only once and no review task", client.comments, len(queue.tasks))
+	}
+}
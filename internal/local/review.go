@@ -0,0 +1,415 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/analyzers"
+	"github.com/CREVIOS/revo/internal/claude"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/internal/prompts"
+	"github.com/CREVIOS/revo/internal/retry"
+	"github.com/CREVIOS/revo/internal/review"
+	"github.com/CREVIOS/revo/internal/sarif"
+	"github.com/CREVIOS/revo/internal/symbols"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// ErrFindings is returned when -fail-on is set and a finding reaches that severity
+var ErrFindings = errors.New("review reported findings at or above the -fail-on severity")
+
+// Result is the JSON output of a local review
+type Result struct {
+	Mode     string    `json:"mode"`
+	Model    string    `json:"model"`
+	Source   string    `json:"source"`
+	Files    int       `json:"files"`
+	Summary  string    `json:"summary"`
+	Findings []Finding `json:"findings"`
+	Skipped  []string  `json:"skipped,omitempty"`
+}
+
+// Finding is a single parsed review comment
+type Finding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Body     string `json:"body"`
+	Source   string `json:"source,omitempty"` // static analyzer the finding confirms
+}
+
+// Run implements `techy review`, reviewing a local diff without GitHub
+func Run(cfg *models.Config, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	mode := fs.String("mode", string(models.ModeReview), "review mode ("+strings.Join(modes.Names("", ""), ", ")+")")
+	base := fs.String("base", "", "review changes since the merge base with this ref (default: uncommitted changes against HEAD)")
+	staged := fs.Bool("staged", false, "review only staged changes")
+	patch := fs.String("patch", "", "read a unified diff from this file instead of git (- for stdin)")
+	dir := fs.String("dir", ".", "git working tree to review")
+	format := fs.String("format", "text", "output format: text, json or sarif")
+	model := fs.String("model", "", "model override (default CLAUDE_MODEL)")
+	minSeverity := fs.String("min-severity", "", "only report findings at error, warning or info and above")
+	failOn := fs.String("fail-on", "", "exit non-zero if a finding is at this severity or above")
+	focus := fs.String("focus", "", "area the review should concentrate on")
+	instructions := fs.String("instructions", "", "extra instructions for the reviewer")
+	title := fs.String("title", "", "title describing the change (default: last commit subject)")
+	promptFile := fs.String("prompt", "", "prompt template file to use instead of the mode's prompt")
+	verbose := fs.Bool("verbose", false, "more detailed analysis")
+	timeout := fs.Duration("timeout", 10*time.Minute, "overall timeout")
+	var files, exclude stringList
+	fs.Var(&files, "files", "only review paths matching this glob (repeatable)")
+	fs.Var(&exclude, "exclude", "skip paths matching this glob (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown format %q (use text, json or sarif)", *format)
+	}
+	if _, ok := modes.Lookup("", "", models.ReviewMode(*mode)); !ok {
+		return fmt.Errorf("unknown mode %q (available: %s)", *mode, strings.Join(modes.Names("", ""), ", "))
+	}
+	for _, severity := range []string{*minSeverity, *failOn} {
+		switch severity {
+		case "", "error", "warning", "info":
+		default:
+			return fmt.Errorf("unknown severity %q (use error, warning or info)", severity)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	diff, source, err := loadDiff(ctx, *patch, *dir, *base, *staged, stdin)
+	if err != nil {
+		return err
+	}
+
+	opts := models.CommandOptions{
+		Files:       files,
+		Exclude:     exclude,
+		Model:       *model,
+		MinSeverity: *minSeverity,
+		Focus:       *focus,
+		Text:        *instructions,
+	}
+	if len(opts.Files) > 0 || len(opts.Exclude) > 0 {
+		diff = gh.FilterDiff(diff, func(filename string) bool {
+			return review.MatchesPathFilters(opts, filename)
+		})
+	}
+	if strings.TrimSpace(diff) == "" {
+		return fmt.Errorf("no changes to review in %s", source)
+	}
+
+	changed := gh.ParseDiff(diff)
+	var contents symbols.ContentSource
+	if *patch == "" {
+		root := absPath(*dir)
+		contents = func(filename string) (string, bool) {
+			data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(filename)))
+			return string(data), err == nil
+		}
+	}
+	var rules symbols.Rules
+	var skipped []models.PRFile
+	if cfg.SkipGeneratedFiles {
+		rules.Patterns = cfg.GeneratedFilePatterns
+		if data, err := os.ReadFile(filepath.Join(*dir, ".gitattributes")); *patch == "" && err == nil {
+			rules.Attributes = symbols.ParseGitattributes(string(data))
+		}
+	}
+	symbols.Annotate(changed, contents, rules)
+	if cfg.SkipGeneratedFiles {
+		changed, diff, skipped = review.SkipGenerated(changed, diff, opts)
+		if len(changed) == 0 {
+			return fmt.Errorf("no changes to review in %s: all changed files are generated or vendored", source)
+		}
+	}
+	var analysis []models.AnalyzerFinding
+	if cfg.AnalyzersEnabled && *patch == "" {
+		analysis = analyzers.NewRunner(analyzers.Config{
+			Enabled:     cfg.Analyzers,
+			Commands:    cfg.AnalyzerCommands,
+			Timeout:     time.Duration(cfg.AnalyzerTimeoutSec) * time.Second,
+			MaxFindings: cfg.AnalyzerMaxFindings,
+		}).Analyze(ctx, absPath(*dir), changed)
+	}
+
+	if cfg.MaxDiffSize > 0 && len(diff) > cfg.MaxDiffSize {
+		diff = gh.TruncateDiff(diff, cfg.MaxDiffSize)
+	}
+
+	if *title == "" && *patch == "" {
+		*title = lastCommitSubject(ctx, *dir)
+	}
+
+	request := &models.ReviewRequest{
+		Owner:    "local",
+		Repo:     filepath.Base(absPath(*dir)),
+		PRNumber: 0,
+		Command: models.Command{
+			Mode:    models.ReviewMode(*mode),
+			Verbose: *verbose,
+			Raw:     fmt.Sprintf("@%s %s", cfg.BotUsername, *mode),
+			Options: opts,
+		},
+		Diff:    diff,
+		PRTitle: *title,
+		Files:   changed,
+
+		SkippedFiles: skipped,
+		Analysis:     analysis,
+	}
+
+	// Let the CLI read the rest of the working tree, as it does in a sandbox checkout
+	if *patch == "" {
+		request.WorkDir = absPath(*dir)
+	}
+
+	if *promptFile != "" {
+		text, err := os.ReadFile(*promptFile)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		request.SystemPrompt, err = prompts.Render(string(text), prompts.Data{
+			Mode:          *mode,
+			Owner:         request.Owner,
+			Repo:          request.Repo,
+			PRTitle:       request.PRTitle,
+			Verbose:       *verbose,
+			Focus:         *focus,
+			Instructions:  *instructions,
+			DefaultPrompt: claude.GetSystemPrompt("", "", request.Command.Mode),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	client := claude.NewClient(cfg.ClaudePath, cfg.ClaudeModel,
+		claude.WithCacheEnabled(false),
+		claude.WithRetryConfig(retry.Config{
+			MaxRetries:     cfg.RetryMaxAttempts,
+			InitialDelay:   time.Duration(cfg.RetryInitialDelay) * time.Millisecond,
+			MaxDelay:       time.Duration(cfg.RetryMaxDelay) * time.Millisecond,
+			Multiplier:     2.0,
+			JitterFraction: 0.3,
+		}),
+	)
+
+	response, err := client.ReviewCode(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to get review from Claude: %w", err)
+	}
+
+	summary, findings := review.ParseStructuredReview(response)
+	analyzers.Tag(findings, analysis)
+	findings = review.FilterBySeverity(findings, *minSeverity)
+
+	usedModel := cfg.ClaudeModel
+	if *model != "" {
+		usedModel = *model
+	}
+	result := Result{
+		Mode:     *mode,
+		Model:    usedModel,
+		Source:   source,
+		Files:    len(changed),
+		Summary:  summary,
+		Findings: make([]Finding, 0, len(findings)),
+	}
+	for _, file := range skipped {
+		result.Skipped = append(result.Skipped, file.Filename)
+	}
+	for _, f := range findings {
+		result.Findings = append(result.Findings, Finding{Path: f.Path, Line: f.Line, Severity: f.Severity, Body: f.Body, Source: f.Source})
+	}
+
+	if err := write(stdout, *format, result, headCommit(ctx, *dir, *patch)); err != nil {
+		return err
+	}
+
+	if *failOn != "" && len(review.FilterBySeverity(findings, *failOn)) > 0 {
+		return ErrFindings
+	}
+	return nil
+}
+
+// loadDiff reads the diff to review from a patch file, stdin or git
+func loadDiff(ctx context.Context, patch, dir, base string, staged bool, stdin io.Reader) (string, string, error) {
+	switch patch {
+	case "":
+	case "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read patch from stdin: %w", err)
+		}
+		return string(data), "stdin", nil
+	default:
+		data, err := os.ReadFile(patch)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read patch: %w", err)
+		}
+		return string(data), patch, nil
+	}
+
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	source := "uncommitted changes"
+	switch {
+	case staged:
+		args = append(args, "--cached")
+		source = "staged changes"
+	case base != "":
+		mergeBase, err := git(ctx, dir, "merge-base", base, "HEAD")
+		if err != nil {
+			return "", "", err
+		}
+		// Compare the working tree with the merge base, like a PR against base
+		args = append(args, strings.TrimSpace(mergeBase))
+		source = "changes since " + base
+	default:
+		args = append(args, "HEAD")
+	}
+
+	diff, err := git(ctx, dir, args...)
+	if err != nil {
+		return "", "", err
+	}
+	return diff, source, nil
+}
+
+// write renders the result in the requested format
+func write(w io.Writer, format string, result Result, commitSHA string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case "sarif":
+		comments := make([]models.ReviewComment, 0, len(result.Findings))
+		for _, f := range result.Findings {
+			comments = append(comments, models.ReviewComment{Path: f.Path, Line: f.Line, Severity: f.Severity, Body: f.Body})
+		}
+		data, err := sarif.FromComments(comments, sarif.Options{
+			Mode:      models.ReviewMode(result.Mode),
+			CommitSHA: commitSHA,
+		}).Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to encode SARIF: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	default:
+		_, err := io.WriteString(w, formatText(result))
+		return err
+	}
+}
+
+// formatText renders findings for a terminal, grep-style locations first
+func formatText(result Result) string {
+	var sb strings.Builder
+
+	if m, ok := modes.Lookup("", "", models.ReviewMode(result.Mode)); ok {
+		sb.WriteString(fmt.Sprintf("%s TechyBot %s of %s (%d files)\n\n", m.Emoji, m.Description, result.Source, result.Files))
+	}
+	if result.Summary != "" {
+		sb.WriteString(result.Summary)
+		sb.WriteString("\n\n")
+	}
+
+	if len(result.Skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("Skipped %d generated, vendored or lock file(s): %s\n\n", len(result.Skipped), strings.Join(result.Skipped, ", ")))
+	}
+
+	if len(result.Findings) == 0 {
+		sb.WriteString("No findings.\n")
+		return sb.String()
+	}
+
+	for _, f := range result.Findings {
+		sb.WriteString(fmt.Sprintf("%s:%d: [%s]\n", f.Path, f.Line, f.Severity))
+		for _, line := range strings.Split(strings.TrimSpace(f.Body), "\n") {
+			sb.WriteString("    ")
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("%d finding(s)\n", len(result.Findings)))
+
+	return sb.String()
+}
+
+// git runs a git command in dir and returns its stdout
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// lastCommitSubject returns the subject of HEAD, or "" outside a repository
+func lastCommitSubject(ctx context.Context, dir string) string {
+	subject, err := git(ctx, dir, "log", "-1", "--format=%s")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(subject)
+}
+
+// headCommit returns the HEAD SHA for SARIF provenance when reviewing a working tree
+func headCommit(ctx context.Context, dir, patch string) string {
+	if patch != "" {
+		return ""
+	}
+	sha, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(sha)
+}
+
+// absPath resolves dir for naming the repository, falling back to dir itself
+func absPath(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// stringList is a repeatable, comma separated flag value
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
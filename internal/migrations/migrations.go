@@ -0,0 +1,219 @@
+package migrations
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// Tool names the migration checks in findings
+const Tool = "migrations"
+
+// check is a deterministic rule over one statement
+type check struct {
+	Rule     string
+	Severity string
+	Pattern  *regexp.Regexp
+	Unless   *regexp.Regexp // the safe form of the statement
+	Message  string
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?i)^CREATE\s+(?:UNLOGGED\s+|TEMP(?:ORARY)?\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."]+)`)
+	tablePattern       = regexp.MustCompile(`(?i)^(?:ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?|CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(?:[\w."]+\s+)?ON\s+(?:ONLY\s+)?|DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?)([\w."]+)`)
+	alterTablePattern  = regexp.MustCompile(`(?i)^ALTER\s+TABLE\b`)
+	lockTimeoutPattern = regexp.MustCompile(`(?i)\bSET\s+(?:LOCAL\s+)?lock_timeout\b`)
+	beginPattern       = regexp.MustCompile(`(?i)^(?:BEGIN|START\s+TRANSACTION)\b`)
+	concurrentlyIndex  = regexp.MustCompile(`(?i)^(?:CREATE\s+(?:UNIQUE\s+)?|DROP\s+)INDEX\s+CONCURRENTLY\b`)
+)
+
+// checks are applied to every statement that changes an existing table
+var checks = []check{
+	{
+		Rule:     "non-concurrent-index",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)^CREATE\s+(?:UNIQUE\s+)?INDEX\b`),
+		Unless:   regexp.MustCompile(`(?i)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+CONCURRENTLY\b`),
+		Message:  "CREATE INDEX without CONCURRENTLY blocks writes to the table until the index is built. Use CREATE INDEX CONCURRENTLY, outside a transaction.",
+	},
+	{
+		Rule:     "non-concurrent-index",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)^DROP\s+INDEX\b`),
+		Unless:   regexp.MustCompile(`(?i)^DROP\s+INDEX\s+CONCURRENTLY\b`),
+		Message:  "DROP INDEX without CONCURRENTLY takes an ACCESS EXCLUSIVE lock on the table. Use DROP INDEX CONCURRENTLY, outside a transaction.",
+	},
+	{
+		Rule:     "drop-column",
+		Severity: "error",
+		Pattern:  regexp.MustCompile(`(?i)\bDROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?[\w"]+`),
+		Unless:   regexp.MustCompile(`(?i)\bDROP\s+(?:CONSTRAINT|DEFAULT|NOT\s+NULL|INDEX|IDENTITY|EXPRESSION)\b`),
+		Message:  "Dropping a column breaks application instances still reading or writing it during the deploy, and the data is gone. Stop using the column in one release and drop it in a later one.",
+	},
+	{
+		Rule:     "drop-table",
+		Severity: "error",
+		Pattern:  regexp.MustCompile(`(?i)^DROP\s+TABLE\b`),
+		Message:  "Dropping a table loses its data and breaks code still using it. Make sure no release in service reads it, and keep a backup.",
+	},
+	{
+		Rule:     "not-null-without-default",
+		Severity: "error",
+		Pattern:  regexp.MustCompile(`(?i)\bADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?[\w"]+\s+[^,]*\bNOT\s+NULL\b`),
+		Unless:   regexp.MustCompile(`(?i)\bADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?[\w"]+\s+[^,]*\bDEFAULT\b|\bADD\s+(?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK|INDEX)\b`),
+		Message:  "Adding a NOT NULL column without a DEFAULT fails on a table that has rows. Add it with a default, or add it nullable, backfill, then set NOT NULL.",
+	},
+	{
+		Rule:     "set-not-null",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)\bALTER\s+(?:COLUMN\s+)?[\w"]+\s+SET\s+NOT\s+NULL\b`),
+		Message:  "SET NOT NULL scans the whole table under an ACCESS EXCLUSIVE lock. Add CHECK (column IS NOT NULL) NOT VALID, VALIDATE CONSTRAINT, then SET NOT NULL, which then skips the scan.",
+	},
+	{
+		Rule:     "table-rewrite",
+		Severity: "error",
+		Pattern:  regexp.MustCompile(`(?i)\bALTER\s+(?:COLUMN\s+)?[\w"]+\s+(?:SET\s+DATA\s+)?TYPE\b`),
+		Message:  "Changing a column's type rewrites the table and its indexes under an ACCESS EXCLUSIVE lock, blocking reads and writes. Add a new column, backfill it in batches and switch over instead.",
+	},
+	{
+		Rule:     "table-rewrite",
+		Severity: "error",
+		Pattern:  regexp.MustCompile(`(?i)\bADD\s+(?:COLUMN\s+)?[\w"]+\s+[^,]*\bDEFAULT\s+\(?\s*(?:now|random|clock_timestamp|statement_timestamp|gen_random_uuid|uuid_generate_v[14]|nextval)\s*\(`),
+		Message:  "Adding a column with a volatile DEFAULT rewrites the whole table under an ACCESS EXCLUSIVE lock. Add the column without the default, set the default, and backfill existing rows in batches.",
+	},
+	{
+		Rule:     "table-rewrite",
+		Severity: "error",
+		Pattern:  regexp.MustCompile(`(?i)^(?:VACUUM\s+(?:\(\s*)?FULL\b|CLUSTER\b|ALTER\s+TABLE\s+.*\bSET\s+TABLESPACE\b)`),
+		Message:  "This rewrites the table under an ACCESS EXCLUSIVE lock, blocking reads and writes until it finishes. Run it in a maintenance window or use pg_repack.",
+	},
+	{
+		Rule:     "locking-constraint",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)\bADD\s+(?:CONSTRAINT\s+[\w"]+\s+)?(?:FOREIGN\s+KEY|CHECK)\b`),
+		Unless:   regexp.MustCompile(`(?i)\bNOT\s+VALID\b`),
+		Message:  "Adding a foreign key or check constraint validates every row while holding a lock. Add it NOT VALID, then VALIDATE CONSTRAINT in a separate statement.",
+	},
+	{
+		Rule:     "locking-constraint",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)\bADD\s+(?:CONSTRAINT\s+[\w"]+\s+)?(?:UNIQUE|PRIMARY\s+KEY)\b`),
+		Unless:   regexp.MustCompile(`(?i)\bUSING\s+INDEX\b`),
+		Message:  "Adding a unique or primary key constraint builds its index while blocking writes. Build a unique index CONCURRENTLY, then ADD CONSTRAINT ... USING INDEX.",
+	},
+	{
+		Rule:     "rename",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+.*\bRENAME\b`),
+		Message:  "Renaming a table or column breaks application instances that use the old name during the deploy. Add the new name alongside the old one and remove the old one in a later release.",
+	},
+	{
+		Rule:     "lock-table",
+		Severity: "warning",
+		Pattern:  regexp.MustCompile(`(?i)^LOCK\s+(?:TABLE\s+)?[\w."]+`),
+		Message:  "An explicit table lock blocks other queries for the rest of the transaction. Keep the transaction short and set lock_timeout.",
+	},
+}
+
+// IsMigration reports whether a changed file is a migration: one matching the
+// configured globs, or any SQL file when there are none
+func IsMigration(patterns []string, filename string) bool {
+	if len(patterns) == 0 {
+		return path.Ext(filename) == ".sql"
+	}
+	return gh.MatchAnyGlob(patterns, filename)
+}
+
+// Check applies the rules to a SQL migration, keeping findings on statements
+// that touch changed lines. A finding is placed on the statement's first
+// changed line.
+func Check(filename, content string, changed map[int]bool) []models.AnalyzerFinding {
+	if path.Ext(filename) != ".sql" {
+		return nil
+	}
+	statements := Split(content)
+
+	created := map[string]bool{}
+	inTransaction := false
+	hasLockTimeout := lockTimeoutPattern.MatchString(content)
+	warnedTimeout := false
+
+	var findings []models.AnalyzerFinding
+	add := func(s Statement, rule, severity, message string) bool {
+		line := changedLine(s, changed)
+		if line == 0 {
+			return false
+		}
+		findings = append(findings, models.AnalyzerFinding{
+			Tool:     Tool,
+			Rule:     rule,
+			Path:     filename,
+			Line:     line,
+			Severity: severity,
+			Message:  message,
+		})
+		return true
+	}
+
+	for _, s := range statements {
+		if match := createTablePattern.FindStringSubmatch(s.SQL); match != nil {
+			created[tableName(match[1])] = true
+			continue
+		}
+		if beginPattern.MatchString(s.SQL) {
+			inTransaction = true
+			continue
+		}
+		if strings.EqualFold(s.SQL, "COMMIT") || strings.EqualFold(s.SQL, "END") {
+			inTransaction = false
+			continue
+		}
+
+		// Tables created in the same migration are empty and unused
+		if match := tablePattern.FindStringSubmatch(s.SQL); match != nil && created[tableName(match[1])] {
+			continue
+		}
+
+		if inTransaction && concurrentlyIndex.MatchString(s.SQL) {
+			add(s, "concurrently-in-transaction", "error",
+				"CREATE/DROP INDEX CONCURRENTLY cannot run inside a transaction block and this migration will fail. Run it in its own migration without a transaction.")
+		}
+		if alterTablePattern.MatchString(s.SQL) && !hasLockTimeout && !warnedTimeout {
+			warnedTimeout = add(s, "missing-lock-timeout", "info",
+				"ALTER TABLE waits for an ACCESS EXCLUSIVE lock behind long-running queries, and every query on the table queues behind it. Set lock_timeout (e.g. SET lock_timeout = '5s') so the migration fails fast and can be retried.")
+		}
+
+		seen := map[string]bool{}
+		for _, c := range checks {
+			if seen[c.Rule] || !c.Pattern.MatchString(s.SQL) || (c.Unless != nil && c.Unless.MatchString(s.SQL)) {
+				continue
+			}
+			// DROP COLUMN is only a column drop inside ALTER TABLE
+			if c.Rule == "drop-column" && !alterTablePattern.MatchString(s.SQL) {
+				continue
+			}
+			seen[c.Rule] = true
+			add(s, c.Rule, c.Severity, c.Message)
+		}
+	}
+	return findings
+}
+
+// changedLine returns the first changed line of a statement, or 0 when none changed
+func changedLine(s Statement, changed map[int]bool) int {
+	for line := s.StartLine; line <= s.EndLine; line++ {
+		if changed[line] {
+			return line
+		}
+	}
+	return 0
+}
+
+// tableName normalizes a table reference, dropping quotes and the schema
+func tableName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, `"`, ""))
+	return name[strings.LastIndex(name, ".")+1:]
+}
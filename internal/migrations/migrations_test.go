@@ -0,0 +1,129 @@
+package migrations
+
+import "testing"
+
+const migration = `CREATE TABLE widgets (id int);
+CREATE INDEX widgets_id ON widgets (id);
+CREATE INDEX users_email ON users (email);
+ALTER TABLE users ADD COLUMN age int NOT NULL;
+ALTER TABLE users DROP COLUMN legacy;
+ALTER TABLE orders ADD CONSTRAINT fk FOREIGN KEY (user_id) REFERENCES users (id) NOT VALID;
+BEGIN;
+CREATE INDEX CONCURRENTLY orders_user ON orders (user_id);
+COMMIT;
+`
+
+func TestCheck(t *testing.T) {
+	type found struct {
+		rule string
+		line int
+	}
+	lines := func(n ...int) map[int]bool {
+		changed := map[int]bool{}
+		for _, line := range n {
+			changed[line] = true
+		}
+		return changed
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		changed  map[int]bool
+		want     []found
+	}{
+		{
+			name:     "whole file",
+			filename: "db/migrations/002.sql",
+			content:  migration,
+			changed:  lines(1, 2, 3, 4, 5, 6, 7, 8, 9),
+			want: []found{
+				{"non-concurrent-index", 3},
+				{"missing-lock-timeout", 4},
+				{"not-null-without-default", 4},
+				{"drop-column", 5},
+				{"concurrently-in-transaction", 8},
+			},
+		},
+		{
+			name:     "only changed statements",
+			filename: "db/migrations/002.sql",
+			content:  migration,
+			changed:  lines(5),
+			want: []found{
+				{"missing-lock-timeout", 5},
+				{"drop-column", 5},
+			},
+		},
+		{
+			name:     "lock timeout set",
+			filename: "002.sql",
+			content:  "SET lock_timeout = '5s';\nALTER TABLE users RENAME COLUMN a TO b;",
+			changed:  lines(1, 2),
+			want:     []found{{"rename", 2}},
+		},
+		{
+			name:     "finding on the first changed line of a statement",
+			filename: "002.sql",
+			content:  "ALTER TABLE users\n  ALTER COLUMN id TYPE bigint;",
+			changed:  lines(2),
+			want: []found{
+				{"missing-lock-timeout", 2},
+				{"table-rewrite", 2},
+			},
+		},
+		{
+			name:     "safe forms",
+			filename: "002.sql",
+			content:  "SET lock_timeout = '5s';\nALTER TABLE users ADD COLUMN age int NOT NULL DEFAULT 0;\nALTER TABLE users DROP CONSTRAINT users_age_check;\nALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE USING INDEX users_email;",
+			changed:  lines(1, 2, 3, 4),
+		},
+		{
+			name:     "not a SQL file",
+			filename: "migrate.go",
+			content:  migration,
+			changed:  lines(3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Check(tt.filename, tt.content, tt.changed)
+			var got []found
+			for _, f := range findings {
+				got = append(got, found{f.Rule, f.Line})
+				if f.Tool != Tool || f.Path != tt.filename || f.Message == "" {
+					t.Errorf("finding %+v is missing its tool, path or message", f)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Check() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("finding %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsMigration(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		filename string
+		want     bool
+	}{
+		{nil, "schema.sql", true},
+		{nil, "a.go", false},
+		{[]string{"db/migrations/*.sql"}, "db/migrations/001_init.sql", true},
+		{[]string{"db/migrations/*.sql"}, "schema.sql", false},
+		{[]string{"migrations/**"}, "migrations/2024/001.rb", true},
+	}
+	for _, tt := range tests {
+		if got := IsMigration(tt.patterns, tt.filename); got != tt.want {
+			t.Errorf("IsMigration(%q, %q) = %v, want %v", tt.patterns, tt.filename, got, tt.want)
+		}
+	}
+}
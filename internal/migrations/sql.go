@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Statement is one SQL statement of a migration file
+type Statement struct {
+	SQL       string // the statement with comments removed and whitespace collapsed
+	StartLine int
+	EndLine   int
+}
+
+var dollarQuote = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// Split breaks a SQL file into statements at semicolons outside quotes,
+// comments and dollar-quoted bodies, recording the lines each spans
+func Split(content string) []Statement {
+	var statements []Statement
+	var current strings.Builder
+	line, start := 1, 0
+
+	flush := func(end int) {
+		sql := strings.Join(strings.Fields(current.String()), " ")
+		if sql != "" {
+			statements = append(statements, Statement{SQL: sql, StartLine: start, EndLine: end})
+		}
+		current.Reset()
+		start = 0
+	}
+	write := func(s string) {
+		if start == 0 && strings.TrimSpace(s) != "" {
+			start = line
+		}
+		current.WriteString(s)
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			current.WriteByte(' ')
+			line++
+
+		case c == '-' && strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end - 1
+			}
+
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			}
+			line += strings.Count(content[i:i+2+end], "\n")
+			current.WriteByte(' ')
+			i += end + 3
+
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(content) && content[end] != c {
+				end++
+			}
+			write(content[i:min(end+1, len(content))])
+			line += strings.Count(content[i:min(end, len(content))], "\n")
+			i = end
+
+		case c == '$' && dollarQuote.MatchString(content[i:]):
+			tag := dollarQuote.FindString(content[i:])
+			end := strings.Index(content[i+len(tag):], tag)
+			if end < 0 {
+				end = len(content) - i - len(tag)
+			} else {
+				end += len(tag)
+			}
+			body := content[i:min(i+len(tag)+end, len(content))]
+			write(body)
+			line += strings.Count(body, "\n")
+			i += len(body) - 1
+
+		case c == ';':
+			flush(line)
+
+		default:
+			write(string(c))
+		}
+	}
+	flush(line)
+	return statements
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Statement
+	}{
+		{
+			name:    "empty",
+			content: ";;\n",
+		},
+		{
+			name:    "one per line",
+			content: "SELECT 1;\nSELECT 2;",
+			want: []Statement{
+				{SQL: "SELECT 1", StartLine: 1, EndLine: 1},
+				{SQL: "SELECT 2", StartLine: 2, EndLine: 2},
+			},
+		},
+		{
+			name:    "no trailing semicolon",
+			content: "\n\nSELECT 1\n",
+			want:    []Statement{{SQL: "SELECT 1", StartLine: 3, EndLine: 4}},
+		},
+		{
+			name:    "line comments and whitespace",
+			content: "-- header; not a statement\nCREATE TABLE t (\n  id int -- pk;\n);\n",
+			want:    []Statement{{SQL: "CREATE TABLE t ( id int )", StartLine: 2, EndLine: 4}},
+		},
+		{
+			name:    "block comment spanning lines",
+			content: "/* one;\ntwo */ SELECT 1;",
+			want:    []Statement{{SQL: "SELECT 1", StartLine: 2, EndLine: 2}},
+		},
+		{
+			name:    "semicolons in quotes",
+			content: "INSERT INTO t VALUES ('a;b', \"c;d\");\nSELECT 2;",
+			want: []Statement{
+				{SQL: `INSERT INTO t VALUES ('a;b', "c;d")`, StartLine: 1, EndLine: 1},
+				{SQL: "SELECT 2", StartLine: 2, EndLine: 2},
+			},
+		},
+		{
+			name:    "dollar-quoted body",
+			content: "CREATE FUNCTION f() RETURNS void AS $body$\nBEGIN\n  DELETE FROM t;\nEND;\n$body$ LANGUAGE plpgsql;\nSELECT 1;",
+			want: []Statement{
+				{SQL: "CREATE FUNCTION f() RETURNS void AS $body$ BEGIN DELETE FROM t; END; $body$ LANGUAGE plpgsql", StartLine: 1, EndLine: 5},
+				{SQL: "SELECT 1", StartLine: 6, EndLine: 6},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package modes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Mode describes a review mode that can be requested with @bot <name>
+type Mode struct {
+	Name         models.ReviewMode     `json:"name"`
+	Emoji        string                `json:"emoji"`
+	Description  string                `json:"description"`
+	SystemPrompt string                `json:"-"` // empty for built-in modes, whose prompts live in the claude package
+	Defaults     models.CommandOptions `json:"defaults"`
+	Verbose      bool                  `json:"verbose"`
+	Owner        string                `json:"owner,omitempty"` // scope; empty for every repository
+	Repo         string                `json:"repo,omitempty"`
+	BuiltIn      bool                  `json:"built_in"`
+	IssueOnly    bool                  `json:"issue_only,omitempty"` // runs on plain issues instead of pull requests
+}
+
+// builtins are the modes shipped with the bot, in help order
+var builtins = []Mode{
+	{Name: models.ModeReview, Emoji: "📝", Description: "Code Review", BuiltIn: true},
+	{Name: models.ModeHunt, Emoji: "🐛", Description: "Bug Hunt", BuiltIn: true},
+	{Name: models.ModeSecurity, Emoji: "🔒", Description: "Security Audit", BuiltIn: true},
+	{Name: models.ModePerformance, Emoji: "⚡", Description: "Performance Analysis", BuiltIn: true},
+	{Name: models.ModeAnalyze, Emoji: "🔬", Description: "Deep Analysis", BuiltIn: true},
+	{Name: models.ModeSummarize, Emoji: "📋", Description: "PR Summary", BuiltIn: true},
+	{Name: models.ModeTests, Emoji: "🧪", Description: "Test Generation", BuiltIn: true},
+	{Name: models.ModeMigrations, Emoji: "🗄️", Description: "Migration Safety", BuiltIn: true},
+	{Name: models.ModeTriage, Emoji: "🏷️", Description: "Issue Triage", BuiltIn: true, IssueOnly: true},
+	{Name: models.ModeExplain, Emoji: "💡", Description: "Code Explanation", BuiltIn: true, IssueOnly: true},
+}
+
+// reservedNames are command words that cannot be used for custom modes
+var reservedNames = map[string]bool{
+	"help":    true,
+	"status":  true,
+	"cancel":  true,
+	"approve": true,
+	"fix":     true,
+	"verbose": true,
+}
+
+// modelAliases lists the short model names accepted by the Claude Code CLI
+var modelAliases = map[string]bool{
+	"opus":   true,
+	"sonnet": true,
+	"haiku":  true,
+}
+
+// DefaultRefreshInterval is how often processes reload custom modes from the database
+const DefaultRefreshInterval = time.Minute
+
+// defaultEmoji is used for custom modes that do not set one
+const defaultEmoji = "🧩"
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+var (
+	mu     sync.RWMutex
+	custom []Mode
+	// disabled maps stored custom modes that cannot be loaded to the reason,
+	// e.g. a name later taken by a built-in mode
+	disabled map[uint]string
+)
+
+// Store lists the custom modes stored in the database
+type Store interface {
+	ListActiveCustomModes() ([]database.CustomMode, error)
+}
+
+// Load replaces the custom modes with the active ones stored in the database
+func Load(store Store) error {
+	if store == nil {
+		return nil
+	}
+
+	records, err := store.ListActiveCustomModes()
+	if err != nil {
+		return fmt.Errorf("failed to load custom modes: %w", err)
+	}
+
+	loaded := make([]Mode, 0, len(records))
+	rejected := map[uint]string{}
+	for _, r := range records {
+		if err := ValidateName(r.Name); err != nil {
+			rejected[r.ID] = err.Error()
+			continue
+		}
+		loaded = append(loaded, fromRecord(r))
+	}
+
+	mu.Lock()
+	previous := disabled
+	custom = loaded
+	disabled = rejected
+	mu.Unlock()
+
+	// Report each disabled mode once rather than on every refresh
+	for _, r := range records {
+		if reason, ok := rejected[r.ID]; ok && previous[r.ID] != reason {
+			log.Error().
+				Uint("custom_mode_id", r.ID).
+				Str("mode", r.Name).
+				Str("reason", reason).
+				Msg("Custom mode is disabled until it is renamed")
+		}
+	}
+
+	return nil
+}
+
+// StartRefresh reloads custom modes every interval until ctx is done,
+// so processes pick up modes created through another instance's API.
+func StartRefresh(ctx context.Context, store Store, interval time.Duration) {
+	if store == nil || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := Load(store); err != nil {
+					log.Warn().Err(err).Msg("Failed to refresh custom modes")
+				}
+			}
+		}
+	}()
+}
+
+// Lookup resolves a mode name for a repository.
+// Built-in modes win, then repository, owner and global custom modes in that order.
+func Lookup(owner, repo string, name models.ReviewMode) (Mode, bool) {
+	name = models.ReviewMode(strings.ToLower(string(name)))
+	for _, m := range builtins {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return lookupCustom(name, owner, repo)
+}
+
+// Get resolves a mode name regardless of repository scope, preferring global modes.
+// It is meant for display purposes where no repository is known.
+func Get(name models.ReviewMode) (Mode, bool) {
+	if m, ok := Lookup("", "", name); ok {
+		return m, true
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, m := range custom {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Mode{}, false
+}
+
+// Disabled returns why a stored custom mode is not in use, or "" if it is loaded
+func Disabled(id uint) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return disabled[id]
+}
+
+// Available returns the modes usable on a repository, built-in modes first
+func Available(owner, repo string) []Mode {
+	available := append([]Mode(nil), builtins...)
+
+	mu.RLock()
+	seen := map[models.ReviewMode]bool{}
+	var scoped []Mode
+	for _, m := range custom {
+		if seen[m.Name] || scopeRank(m, owner, repo) < 0 {
+			continue
+		}
+		resolved, _ := lookupCustom(m.Name, owner, repo)
+		seen[m.Name] = true
+		scoped = append(scoped, resolved)
+	}
+	mu.RUnlock()
+
+	sort.Slice(scoped, func(i, j int) bool { return scoped[i].Name < scoped[j].Name })
+	return append(available, scoped...)
+}
+
+// Names returns the command words of the modes usable on a repository
+func Names(owner, repo string) []string {
+	available := Available(owner, repo)
+	names := make([]string, 0, len(available))
+	for _, m := range available {
+		names = append(names, string(m.Name))
+	}
+	return names
+}
+
+// ApplyDefaults fills options the command left unset from the mode's defaults
+func ApplyDefaults(cmd *models.Command, m Mode) {
+	if len(cmd.Options.Files) == 0 {
+		cmd.Options.Files = m.Defaults.Files
+	}
+	if len(cmd.Options.Exclude) == 0 {
+		cmd.Options.Exclude = m.Defaults.Exclude
+	}
+	if cmd.Options.Model == "" {
+		cmd.Options.Model = m.Defaults.Model
+	}
+	if cmd.Options.MinSeverity == "" {
+		cmd.Options.MinSeverity = m.Defaults.MinSeverity
+	}
+	if cmd.Options.Focus == "" {
+		cmd.Options.Focus = m.Defaults.Focus
+	}
+	if m.Verbose {
+		cmd.Verbose = true
+	}
+}
+
+// ValidateName checks that name can be used as a custom mode command word
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("mode name %q must be lowercase letters, digits or dashes and start with a letter", name)
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("mode name %q is reserved for a bot command", name)
+	}
+	for _, m := range builtins {
+		if string(m.Name) == name {
+			return fmt.Errorf("mode name %q is taken by a built-in mode; choose another name", name)
+		}
+	}
+	return nil
+}
+
+// ValidModel reports whether a lowercase model name is a CLI alias or a full claude-* model id
+func ValidModel(model string) bool {
+	return modelAliases[model] || strings.HasPrefix(model, "claude-")
+}
+
+// Validate checks a custom mode record before it is stored
+func Validate(r *database.CustomMode) error {
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	if err := ValidateName(r.Name); err != nil {
+		return err
+	}
+	if r.Repo != "" && r.Owner == "" {
+		return fmt.Errorf("repo requires owner")
+	}
+	if strings.TrimSpace(r.Description) == "" {
+		return fmt.Errorf("description is required")
+	}
+	if strings.TrimSpace(r.SystemPrompt) == "" {
+		return fmt.Errorf("system_prompt is required")
+	}
+	r.DefaultModel = strings.ToLower(strings.TrimSpace(r.DefaultModel))
+	if r.DefaultModel != "" && !ValidModel(r.DefaultModel) {
+		return fmt.Errorf("default_model must be opus, sonnet, haiku or a full claude-* model id")
+	}
+	switch r.DefaultMinSeverity {
+	case "", "error", "warning", "info":
+	default:
+		return fmt.Errorf("default_min_severity must be error, warning or info")
+	}
+	return nil
+}
+
+// lookupCustom resolves the most specific custom mode; callers hold mu
+func lookupCustom(name models.ReviewMode, owner, repo string) (Mode, bool) {
+	best, bestRank := Mode{}, -1
+	for _, m := range custom {
+		if m.Name != name {
+			continue
+		}
+		if rank := scopeRank(m, owner, repo); rank > bestRank {
+			best, bestRank = m, rank
+		}
+	}
+	return best, bestRank >= 0
+}
+
+// scopeRank scores how specifically a mode applies to owner/repo, or -1 if it does not
+func scopeRank(m Mode, owner, repo string) int {
+	switch {
+	case m.Owner == "":
+		return 0
+	case !strings.EqualFold(m.Owner, owner):
+		return -1
+	case m.Repo == "":
+		return 1
+	case strings.EqualFold(m.Repo, repo):
+		return 2
+	default:
+		return -1
+	}
+}
+
+// fromRecord converts a stored custom mode into a registry entry
+func fromRecord(r database.CustomMode) Mode {
+	emoji := r.Emoji
+	if emoji == "" {
+		emoji = defaultEmoji
+	}
+
+	return Mode{
+		Name:         models.ReviewMode(r.Name),
+		Emoji:        emoji,
+		Description:  r.Description,
+		SystemPrompt: r.SystemPrompt,
+		Defaults: models.CommandOptions{
+			Files:       splitList(r.DefaultFiles),
+			Exclude:     splitList(r.DefaultExclude),
+			Model:       r.DefaultModel,
+			MinSeverity: r.DefaultMinSeverity,
+			Focus:       r.DefaultFocus,
+		},
+		Verbose: r.DefaultVerbose,
+		Owner:   r.Owner,
+		Repo:    r.Repo,
+	}
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,104 @@
+package modes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+type fakeStore []database.CustomMode
+
+func (s fakeStore) ListActiveCustomModes() ([]database.CustomMode, error) {
+	return s, nil
+}
+
+func TestLoadDisablesBuiltInNames(t *testing.T) {
+	t.Cleanup(func() { _ = Load(fakeStore(nil)) })
+
+	store := fakeStore{
+		{ID: 1, Name: "a11y", Description: "Accessibility Review", SystemPrompt: "Check WCAG."},
+		{ID: 2, Name: "migrations", Description: "Our migration review", SystemPrompt: "Check migrations."},
+		{ID: 3, Name: "help", Description: "Help", SystemPrompt: "Help."},
+	}
+	if err := Load(store); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if m, ok := Lookup("acme", "api", "a11y"); !ok || m.BuiltIn {
+		t.Errorf("Lookup(a11y) = %+v, %v, want the custom mode", m, ok)
+	}
+	if m, ok := Lookup("acme", "api", models.ModeMigrations); !ok || !m.BuiltIn {
+		t.Errorf("Lookup(migrations) = %+v, %v, want the built-in mode", m, ok)
+	}
+
+	if reason := Disabled(1); reason != "" {
+		t.Errorf("Disabled(a11y) = %q, want \"\"", reason)
+	}
+	if reason := Disabled(2); !strings.Contains(reason, "built-in") {
+		t.Errorf("Disabled(migrations) = %q, want the built-in conflict", reason)
+	}
+	if reason := Disabled(3); !strings.Contains(reason, "reserved") {
+		t.Errorf("Disabled(help) = %q, want the reserved word", reason)
+	}
+
+	// Renaming the mode makes it available
+	store[1].Name = "db-migrations"
+	if err := Load(store); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if reason := Disabled(2); reason != "" {
+		t.Errorf("Disabled(db-migrations) = %q after renaming, want \"\"", reason)
+	}
+	if _, ok := Lookup("acme", "api", "db-migrations"); !ok {
+		t.Error("Lookup(db-migrations) = false after renaming")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() database.CustomMode {
+		return database.CustomMode{Name: " A11y ", Description: "Accessibility Review", SystemPrompt: "Check WCAG."}
+	}
+
+	mode := valid()
+	mode.DefaultModel = " Opus "
+	if err := Validate(&mode); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if mode.Name != "a11y" || mode.DefaultModel != "opus" {
+		t.Errorf("Name = %q, DefaultModel = %q, want them normalized to a11y and opus", mode.Name, mode.DefaultModel)
+	}
+
+	mode = valid()
+	mode.DefaultModel = "claude-sonnet-4-5"
+	if err := Validate(&mode); err != nil {
+		t.Errorf("Validate() with a full model id error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(m *database.CustomMode)
+		message string
+	}{
+		{"built-in name", func(m *database.CustomMode) { m.Name = "migrations" }, "taken by a built-in mode"},
+		{"reserved name", func(m *database.CustomMode) { m.Name = "approve" }, "reserved"},
+		{"invalid name", func(m *database.CustomMode) { m.Name = "9lives" }, "must be lowercase"},
+		{"repo without owner", func(m *database.CustomMode) { m.Repo = "api" }, "repo requires owner"},
+		{"no description", func(m *database.CustomMode) { m.Description = " " }, "description is required"},
+		{"no prompt", func(m *database.CustomMode) { m.SystemPrompt = "" }, "system_prompt is required"},
+		{"unknown model", func(m *database.CustomMode) { m.DefaultModel = "gpt-4" }, "default_model"},
+		{"model id without the claude prefix", func(m *database.CustomMode) { m.DefaultModel = "sonnet-4-5" }, "default_model"},
+		{"unknown severity", func(m *database.CustomMode) { m.DefaultMinSeverity = "high" }, "default_min_severity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := valid()
+			tt.change(&mode)
+			err := Validate(&mode)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Outcome is the result of an authorization check
+type Outcome string
+
+const (
+	Allowed         Outcome = "allowed"
+	Denied          Outcome = "denied"
+	PendingApproval Outcome = "pending_approval"
+)
+
+// Decision explains whether a user may trigger a review
+type Decision struct {
+	Outcome Outcome
+	Reason  string
+}
+
+// Config holds the global policy defaults
+type Config struct {
+	Enabled         bool
+	MinPermission   string
+	AllowOrgMembers bool
+	RequireApproval bool
+}
+
+// permissionRank orders repository roles from least to most privileged
+var permissionRank = map[string]int{
+	"none":     0,
+	"read":     1,
+	"triage":   2,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+// firstTimeAssociations are comment author associations treated as first-time contributors
+var firstTimeAssociations = map[string]bool{
+	"FIRST_TIME_CONTRIBUTOR": true,
+	"FIRST_TIMER":            true,
+	"NONE":                   true,
+}
+
+// Permissions looks up a user's access to a repository on GitHub
+type Permissions interface {
+	GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error)
+	IsOrgMember(ctx context.Context, owner, repo, org, user string) (bool, error)
+}
+
+// SettingsStore loads the per-repository policy overrides
+type SettingsStore interface {
+	GetRepository(owner, name string) (*database.Repository, error)
+}
+
+// Checker decides who may trigger reviews on a repository
+type Checker struct {
+	githubClient Permissions
+	store        SettingsStore
+	config       Config
+}
+
+// NewChecker creates a new policy checker
+func NewChecker(githubClient Permissions, store SettingsStore, cfg Config) *Checker {
+	if _, ok := permissionRank[cfg.MinPermission]; !ok {
+		cfg.MinPermission = "write"
+	}
+	return &Checker{
+		githubClient: githubClient,
+		store:        store,
+		config:       cfg,
+	}
+}
+
+// Authorize checks whether actor may trigger a review on owner/repo.
+// association is the comment's author_association from the webhook payload.
+func (c *Checker) Authorize(ctx context.Context, owner, repo, actor, association string) (Decision, error) {
+	if !c.config.Enabled {
+		return Decision{Outcome: Allowed, Reason: "authorization disabled"}, nil
+	}
+	if actor == "" {
+		return Decision{Outcome: Denied, Reason: "unknown requester"}, nil
+	}
+
+	settings := c.settingsFor(owner, repo)
+
+	if containsLogin(settings.deniedUsers, actor) {
+		return Decision{Outcome: Denied, Reason: "user is on the repository denylist"}, nil
+	}
+	if containsLogin(settings.allowedUsers, actor) {
+		return Decision{Outcome: Allowed, Reason: "user is on the repository allowlist"}, nil
+	}
+
+	permission, err := c.githubClient.GetPermissionLevel(ctx, owner, repo, actor)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	if permissionRank[permission] >= permissionRank[settings.minPermission] {
+		reason := fmt.Sprintf("user has %s permission", permission)
+		if settings.requireApproval && firstTimeAssociations[association] && permissionRank[permission] < permissionRank["write"] {
+			return Decision{Outcome: PendingApproval, Reason: reason + "; first-time contributor requires maintainer approval"}, nil
+		}
+		return Decision{Outcome: Allowed, Reason: reason}, nil
+	}
+
+	if settings.allowOrgMembers {
+		member, err := c.githubClient.IsOrgMember(ctx, owner, repo, owner, actor)
+		if err != nil {
+			// Owner may be a user account rather than an organization
+			log.Debug().Err(err).Str("org", owner).Msg("Org membership check failed")
+		} else if member {
+			return Decision{Outcome: Allowed, Reason: fmt.Sprintf("user is a member of %s", owner)}, nil
+		}
+	}
+
+	if settings.requireApproval && firstTimeAssociations[association] {
+		return Decision{Outcome: PendingApproval, Reason: fmt.Sprintf("user has %s permission; first-time contributor requires maintainer approval", permission)}, nil
+	}
+
+	return Decision{
+		Outcome: Denied,
+		Reason:  fmt.Sprintf("user has %s permission, %s required", permission, settings.minPermission),
+	}, nil
+}
+
+// CanApprove reports whether actor may approve reviews awaiting maintainer approval
+func (c *Checker) CanApprove(ctx context.Context, owner, repo, actor string) (bool, error) {
+	if containsLogin(c.settingsFor(owner, repo).deniedUsers, actor) {
+		return false, nil
+	}
+
+	permission, err := c.githubClient.GetPermissionLevel(ctx, owner, repo, actor)
+	if err != nil {
+		return false, err
+	}
+	return permissionRank[permission] >= permissionRank["write"], nil
+}
+
+// CanFix reports whether actor may have the bot commit a finding's suggested
+// change; it takes the same write permission as approving reviews
+func (c *Checker) CanFix(ctx context.Context, owner, repo, actor string) (bool, error) {
+	return c.CanApprove(ctx, owner, repo, actor)
+}
+
+// repoSettings is the effective policy for one repository
+type repoSettings struct {
+	allowedUsers    []string
+	deniedUsers     []string
+	minPermission   string
+	allowOrgMembers bool
+	requireApproval bool
+}
+
+// settingsFor merges per-repository overrides with the global defaults
+func (c *Checker) settingsFor(owner, repo string) repoSettings {
+	settings := repoSettings{
+		minPermission:   c.config.MinPermission,
+		allowOrgMembers: c.config.AllowOrgMembers,
+		requireApproval: c.config.RequireApproval,
+	}
+
+	if c.store == nil {
+		return settings
+	}
+
+	record, err := c.store.GetRepository(owner, repo)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn().Err(err).Str("repo", owner+"/"+repo).Msg("Failed to load repository policy, using defaults")
+		}
+		return settings
+	}
+
+	settings.allowedUsers = splitLogins(record.AllowedUsers)
+	settings.deniedUsers = splitLogins(record.DeniedUsers)
+	if _, ok := permissionRank[record.MinPermission]; ok {
+		settings.minPermission = record.MinPermission
+	}
+	if record.AllowOrgMembers != nil {
+		settings.allowOrgMembers = *record.AllowOrgMembers
+	}
+	if record.RequireApproval != nil {
+		settings.requireApproval = *record.RequireApproval
+	}
+
+	return settings
+}
+
+// splitLogins parses a comma or whitespace separated list of GitHub logins
+func splitLogins(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+}
+
+// containsLogin reports whether login is in the list (case-insensitive, optional @)
+func containsLogin(logins []string, login string) bool {
+	for _, l := range logins {
+		if strings.EqualFold(strings.TrimPrefix(l, "@"), login) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"gorm.io/gorm"
+)
+
+type fakePermissions struct {
+	levels  map[string]string
+	members map[string]bool
+	err     error
+}
+
+func (f *fakePermissions) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if level, ok := f.levels[user]; ok {
+		return level, nil
+	}
+	return "none", nil
+}
+
+func (f *fakePermissions) IsOrgMember(ctx context.Context, owner, repo, org, user string) (bool, error) {
+	return f.members[user], nil
+}
+
+type fakeSettings struct {
+	repo *database.Repository
+}
+
+func (f *fakeSettings) GetRepository(owner, name string) (*database.Repository, error) {
+	if f.repo == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return f.repo, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	yes, no := true, false
+	permissions := &fakePermissions{
+		levels: map[string]string{
+			"admin":      "admin",
+			"writer":     "write",
+			"triager":    "triage",
+			"reader":     "read",
+			"listed":     "read",
+			"maintainer": "maintain",
+		},
+		members: map[string]bool{"member": true},
+	}
+	defaults := Config{Enabled: true, MinPermission: "write"}
+
+	tests := []struct {
+		name        string
+		config      Config
+		repo        *database.Repository
+		actor       string
+		association string
+		want        Outcome
+	}{
+		{"disabled allows anyone", Config{}, nil, "stranger", "NONE", Allowed},
+		{"empty actor", defaults, nil, "", "OWNER", Denied},
+		{"write permission", defaults, nil, "writer", "COLLABORATOR", Allowed},
+		{"admin permission", defaults, nil, "admin", "OWNER", Allowed},
+		{"read permission below minimum", defaults, nil, "reader", "CONTRIBUTOR", Denied},
+		{"no permission", defaults, nil, "stranger", "NONE", Denied},
+		{"org member allowed", Config{Enabled: true, MinPermission: "write", AllowOrgMembers: true}, nil, "member", "MEMBER", Allowed},
+		{"org membership off", defaults, nil, "member", "MEMBER", Denied},
+		{"first-time contributor pending", Config{Enabled: true, MinPermission: "write", RequireApproval: true}, nil, "stranger", "FIRST_TIME_CONTRIBUTOR", PendingApproval},
+		{"first timer pending", Config{Enabled: true, MinPermission: "write", RequireApproval: true}, nil, "stranger", "FIRST_TIMER", PendingApproval},
+		{"returning contributor denied", Config{Enabled: true, MinPermission: "write", RequireApproval: true}, nil, "reader", "CONTRIBUTOR", Denied},
+		{"first-time reader pending under a read minimum", Config{Enabled: true, MinPermission: "read", RequireApproval: true}, nil, "reader", "NONE", PendingApproval},
+		{"returning reader allowed under a read minimum", Config{Enabled: true, MinPermission: "read", RequireApproval: true}, nil, "reader", "CONTRIBUTOR", Allowed},
+		{"first-time writer is not held", Config{Enabled: true, MinPermission: "read", RequireApproval: true}, nil, "writer", "FIRST_TIME_CONTRIBUTOR", Allowed},
+		{"allowlist", defaults, &database.Repository{AllowedUsers: "someone, @Listed"}, "listed", "NONE", Allowed},
+		{"denylist beats permission", defaults, &database.Repository{DeniedUsers: "admin"}, "admin", "OWNER", Denied},
+		{"denylist beats allowlist", defaults, &database.Repository{AllowedUsers: "listed", DeniedUsers: "listed"}, "listed", "NONE", Denied},
+		{"repository minimum", defaults, &database.Repository{MinPermission: "triage"}, "triager", "CONTRIBUTOR", Allowed},
+		{"invalid repository minimum keeps the default", defaults, &database.Repository{MinPermission: "owner"}, "triager", "CONTRIBUTOR", Denied},
+		{"repository enables org members", defaults, &database.Repository{AllowOrgMembers: &yes}, "member", "MEMBER", Allowed},
+		{"repository requires approval", defaults, &database.Repository{RequireApproval: &yes}, "stranger", "NONE", PendingApproval},
+		{"repository turns approval off", Config{Enabled: true, MinPermission: "write", RequireApproval: true}, &database.Repository{RequireApproval: &no}, "stranger", "NONE", Denied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(permissions, &fakeSettings{repo: tt.repo}, tt.config)
+			got, err := checker.Authorize(context.Background(), "acme", "api", tt.actor, tt.association)
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+			if got.Outcome != tt.want {
+				t.Errorf("Authorize() = %+v, want %s", got, tt.want)
+			}
+			if got.Reason == "" {
+				t.Error("Reason is empty")
+			}
+		})
+	}
+}
+
+func TestAuthorizeError(t *testing.T) {
+	lookup := errors.New("github unavailable")
+	checker := NewChecker(&fakePermissions{err: lookup}, &fakeSettings{}, Config{Enabled: true})
+	if _, err := checker.Authorize(context.Background(), "acme", "api", "writer", "OWNER"); !errors.Is(err, lookup) {
+		t.Errorf("Authorize() error = %v, want %v", err, lookup)
+	}
+}
+
+func TestCanApprove(t *testing.T) {
+	permissions := &fakePermissions{levels: map[string]string{"writer": "write", "triager": "triage"}}
+	tests := []struct {
+		name  string
+		repo  *database.Repository
+		actor string
+		want  bool
+	}{
+		{"write permission", nil, "writer", true},
+		{"triage permission", nil, "triager", false},
+		{"no permission", nil, "stranger", false},
+		{"allowlist does not grant approval", &database.Repository{AllowedUsers: "stranger"}, "stranger", false},
+		{"denylist", &database.Repository{DeniedUsers: "Writer"}, "writer", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(permissions, &fakeSettings{repo: tt.repo}, Config{Enabled: true})
+			got, err := checker.CanApprove(context.Background(), "acme", "api", tt.actor)
+			if err != nil {
+				t.Fatalf("CanApprove() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanApprove(%q) = %v, want %v", tt.actor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsLogin(t *testing.T) {
+	logins := splitLogins("alice, @Bob\ncarol\tdave")
+	if strings.Join(logins, "|") != "alice|@Bob|carol|dave" {
+		t.Fatalf("splitLogins() = %q", logins)
+	}
+	for _, login := range []string{"alice", "bob", "DAVE"} {
+		if !containsLogin(logins, login) {
+			t.Errorf("containsLogin(%q) = false, want true", login)
+		}
+	}
+	if containsLogin(logins, "eve") {
+		t.Error(`containsLogin("eve") = true, want false`)
+	}
+}
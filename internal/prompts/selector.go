@@ -0,0 +1,230 @@
+package prompts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/CREVIOS/revo/internal/claude"
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// DefaultVersion identifies the prompt compiled into the bot (or stored on a custom mode)
+const DefaultVersion = "default"
+
+// Data is the value templates are executed with
+type Data struct {
+	Mode         string
+	Owner        string
+	Repo         string
+	PRNumber     int
+	PRTitle      string
+	Verbose      bool
+	Focus        string
+	Instructions string
+	// DefaultPrompt is the mode's built-in prompt, so templates can extend it
+	DefaultPrompt string
+}
+
+// Selection is the system prompt chosen for a review
+type Selection struct {
+	Prompt  string
+	Version string
+}
+
+// Selector picks the system prompt template for a review
+type Selector struct {
+	store *database.Store
+}
+
+// NewSelector creates a new prompt selector
+func NewSelector(store *database.Store) *Selector {
+	return &Selector{store: store}
+}
+
+// Select chooses and renders the prompt for request.
+// A repository pin wins; otherwise the PR, issue or commit is bucketed into
+// the active rollouts.
+// Any failure falls back to the default prompt so reviews are never blocked.
+func (s *Selector) Select(request *models.ReviewRequest) Selection {
+	mode := string(request.Command.Mode)
+	defaultPrompt := claude.GetSystemPrompt(request.Owner, request.Repo, request.Command.Mode)
+	fallback := Selection{Prompt: defaultPrompt, Version: DefaultVersion}
+
+	if s.store == nil {
+		return fallback
+	}
+
+	tmpl, err := s.choose(request.Owner, request.Repo, RolloutKey(request), mode)
+	if err != nil {
+		log.Warn().Err(err).Str("mode", mode).Msg("Failed to select prompt template, using default")
+		return fallback
+	}
+	if tmpl == nil {
+		return fallback
+	}
+
+	prompt, err := Render(tmpl.Template, Data{
+		Mode:          mode,
+		Owner:         request.Owner,
+		Repo:          request.Repo,
+		PRNumber:      request.PRNumber,
+		PRTitle:       request.PRTitle,
+		Verbose:       request.Command.Verbose,
+		Focus:         request.Command.Options.Focus,
+		Instructions:  request.Command.Options.Text,
+		DefaultPrompt: defaultPrompt,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("version", VersionLabel(mode, tmpl.Version)).Msg("Failed to render prompt template, using default")
+		return fallback
+	}
+
+	return Selection{Prompt: prompt, Version: VersionLabel(mode, tmpl.Version)}
+}
+
+// choose returns the template for a review, or nil for the default prompt
+func (s *Selector) choose(owner, repo, key, mode string) (*database.PromptTemplate, error) {
+	if pin, ok, err := s.pinnedVersion(owner, repo, mode); err != nil {
+		return nil, err
+	} else if ok {
+		if pin == DefaultVersion {
+			return nil, nil
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(pin, "v"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt pin %q for mode %s", pin, mode)
+		}
+		return s.store.GetPromptTemplate(mode, version)
+	}
+
+	templates, err := s.store.ListActivePromptTemplates(mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+
+	return pick(templates, Bucket(owner, repo, key)), nil
+}
+
+// pick returns the template whose share of the rollout covers bucket, giving
+// each active template the next RolloutPercent buckets, or nil for the default prompt
+func pick(templates []database.PromptTemplate, bucket int) *database.PromptTemplate {
+	cumulative := 0
+	for i := range templates {
+		cumulative += templates[i].RolloutPercent
+		if bucket < cumulative {
+			return &templates[i]
+		}
+	}
+	return nil
+}
+
+// ValidateRollout checks that a template's rollout keeps the mode's active
+// rollouts within 100 percent. active lists the mode's active templates;
+// the template with id, if stored, is counted with percent instead.
+func ValidateRollout(mode string, active []database.PromptTemplate, id uint, percent int, isActive bool) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("rollout_percent must be between 0 and 100")
+	}
+	if !isActive {
+		return nil
+	}
+
+	total := percent
+	for _, t := range active {
+		if id == 0 || t.ID != id {
+			total += t.RolloutPercent
+		}
+	}
+	if total > 100 {
+		return fmt.Errorf("active rollouts for %s would total %d%%, which exceeds 100%%", mode, total)
+	}
+	return nil
+}
+
+// pinnedVersion reads the repository's pin for mode from its prompt_pins setting
+func (s *Selector) pinnedVersion(owner, repo, mode string) (string, bool, error) {
+	record, err := s.store.GetRepository(owner, repo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to load repository: %w", err)
+	}
+
+	for _, pair := range strings.Split(record.PromptPins, ",") {
+		name, version, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && strings.EqualFold(strings.TrimSpace(name), mode) {
+			return strings.TrimSpace(version), true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// Render executes a prompt template with data
+func Render(text string, data Data) (string, error) {
+	tmpl, err := Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Parse compiles a prompt template
+func Parse(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Validate checks that a template parses and only references known fields
+func Validate(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("template is required")
+	}
+	_, err := Render(text, Data{Mode: "review", Owner: "owner", Repo: "repo", PRNumber: 1, DefaultPrompt: "prompt"})
+	return err
+}
+
+// RolloutKey identifies what a review covers within its repository: the PR
+// number, or for other targets the issue number or the commits
+func RolloutKey(request *models.ReviewRequest) string {
+	target := request.Target
+	switch {
+	case target.IsPullRequest():
+		return fmt.Sprintf("#%d", request.PRNumber)
+	case target.Kind == models.TargetIssue:
+		return fmt.Sprintf(":issue#%d", target.Issue)
+	case target.Kind == models.TargetCompare:
+		return fmt.Sprintf(":%s@%s...%s", target.Kind, target.Base, target.Head)
+	default:
+		return fmt.Sprintf(":%s@%s", target.Kind, target.Head)
+	}
+}
+
+// Bucket maps a review's rollout key to a stable number in [0, 100) for percentage rollouts
+func Bucket(owner, repo, key string) int {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s/%s%s", strings.ToLower(owner), strings.ToLower(repo), key)
+	return int(h.Sum32() % 100)
+}
+
+// VersionLabel formats the version recorded on reviews, e.g. hunt@v3
+func VersionLabel(mode string, version int) string {
+	return fmt.Sprintf("%s@v%d", mode, version)
+}
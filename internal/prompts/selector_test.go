@@ -0,0 +1,143 @@
+package prompts
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"testing"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+func TestRolloutKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		request models.ReviewRequest
+		want    string
+	}{
+		{"pull request", models.ReviewRequest{PRNumber: 12}, "#12"},
+		{"pull request target", models.ReviewRequest{PRNumber: 12, Target: &models.ReviewTarget{Kind: models.TargetPullRequest}}, "#12"},
+		{"issue", models.ReviewRequest{Target: &models.ReviewTarget{Kind: models.TargetIssue, Issue: 5}}, ":issue#5"},
+		{"pushed commit", models.ReviewRequest{Target: &models.ReviewTarget{Kind: models.TargetCommit, Head: "abc123", Ref: "main"}}, ":commit@abc123"},
+		{"compare", models.ReviewRequest{Target: &models.ReviewTarget{Kind: models.TargetCompare, Base: "aaa", Head: "bbb"}}, ":compare@aaa...bbb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RolloutKey(&tt.request); got != tt.want {
+				t.Errorf("RolloutKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucket(t *testing.T) {
+	// Pull requests keep the buckets of "owner/repo#number", so running experiments do not reshuffle
+	h := fnv.New32a()
+	h.Write([]byte("acme/api#12"))
+	if got, want := Bucket("acme", "api", "#12"), int(h.Sum32()%100); got != want {
+		t.Errorf("Bucket(acme/api#12) = %d, want %d", got, want)
+	}
+	if got := Bucket("acme", "api", "#12"); got != Bucket("ACME", "Api", "#12") {
+		t.Errorf("Bucket() depends on the case of the repository: %d", got)
+	}
+
+	counts := make([]int, 10)
+	for pr := 1; pr <= 1000; pr++ {
+		bucket := Bucket("acme", "api", RolloutKey(&models.ReviewRequest{PRNumber: pr}))
+		if bucket < 0 || bucket >= 100 {
+			t.Fatalf("Bucket(#%d) = %d, want [0, 100)", pr, bucket)
+		}
+		counts[bucket/10]++
+	}
+	for decile, n := range counts {
+		if n < 50 || n > 150 {
+			t.Errorf("decile %d has %d of 1000 PRs, want roughly 100", decile, n)
+		}
+	}
+
+	// Reviews without a PR no longer share one bucket
+	targets := map[int]bool{}
+	for i := 1; i <= 50; i++ {
+		issue := &models.ReviewRequest{Target: &models.ReviewTarget{Kind: models.TargetIssue, Issue: i}}
+		commit := &models.ReviewRequest{Target: &models.ReviewTarget{Kind: models.TargetCommit, Head: fmt.Sprintf("%040x", i)}}
+		targets[Bucket("acme", "api", RolloutKey(issue))] = true
+		targets[Bucket("acme", "api", RolloutKey(commit))] = true
+	}
+	if len(targets) < 20 {
+		t.Errorf("issues and commits fell into %d buckets, want them spread out", len(targets))
+	}
+}
+
+func TestPick(t *testing.T) {
+	templates := []database.PromptTemplate{
+		{Version: 1, RolloutPercent: 10},
+		{Version: 2, RolloutPercent: 0},
+		{Version: 3, RolloutPercent: 25},
+	}
+	tests := []struct {
+		bucket int
+		want   int // version; 0 for the default prompt
+	}{
+		{0, 1},
+		{9, 1},
+		{10, 3},
+		{34, 3},
+		{35, 0},
+		{99, 0},
+	}
+	for _, tt := range tests {
+		got := pick(templates, tt.bucket)
+		version := 0
+		if got != nil {
+			version = got.Version
+		}
+		if version != tt.want {
+			t.Errorf("pick(bucket %d) = v%d, want v%d", tt.bucket, version, tt.want)
+		}
+	}
+
+	if got := pick(nil, 0); got != nil {
+		t.Errorf("pick(nil) = %+v, want nil", got)
+	}
+	full := []database.PromptTemplate{{Version: 1, RolloutPercent: 60}, {Version: 2, RolloutPercent: 40}}
+	if got := pick(full, 99); got == nil || got.Version != 2 {
+		t.Errorf("pick(bucket 99) of a full rollout = %+v, want v2", got)
+	}
+}
+
+func TestValidateRollout(t *testing.T) {
+	active := []database.PromptTemplate{
+		{ID: 1, RolloutPercent: 30},
+		{ID: 2, RolloutPercent: 50},
+	}
+	tests := []struct {
+		name     string
+		id       uint
+		percent  int
+		isActive bool
+		message  string
+	}{
+		{name: "new template fits", percent: 20, isActive: true},
+		{name: "new template exceeds", percent: 21, isActive: true, message: "would total 101%"},
+		{name: "inactive template is not counted", percent: 90, isActive: false},
+		{name: "updated template replaces its own share", id: 2, percent: 70, isActive: true},
+		{name: "updated template exceeds", id: 1, percent: 51, isActive: true, message: "would total 101%"},
+		{name: "negative", percent: -1, isActive: false, message: "between 0 and 100"},
+		{name: "over 100", percent: 101, isActive: true, message: "between 0 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRollout("hunt", active, tt.id, tt.percent, tt.isActive)
+			if tt.message == "" {
+				if err != nil {
+					t.Errorf("ValidateRollout() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("ValidateRollout() error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/internal/symbols"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultAuditMaxFiles bounds the files read in one audit when not configured
+const defaultAuditMaxFiles = 200
+
+// dependencyFocus steers the security prompt towards manifests in dependency audits
+const dependencyFocus = "the declared dependencies: versions with known vulnerabilities, unmaintained or deprecated packages, unpinned or overly broad version ranges, and licenses that may be incompatible with the project"
+
+// manifests are the dependency manifests read by dependency audits
+var manifests = map[string]bool{
+	"go.mod": true, "package.json": true, "requirements.txt": true, "pyproject.toml": true,
+	"Pipfile": true, "setup.py": true, "Cargo.toml": true, "Gemfile": true, "composer.json": true,
+	"pom.xml": true, "build.gradle": true, "build.gradle.kts": true,
+}
+
+// auditExtensions are the source files read by security audits
+var auditExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".java": true,
+	".kt": true, ".rb": true, ".php": true, ".rs": true, ".c": true, ".cc": true, ".cpp": true,
+	".h": true, ".cs": true, ".swift": true, ".scala": true, ".sh": true, ".sql": true,
+}
+
+// SetAuditMaxFiles bounds the files read in one scheduled audit
+func (r *Reviewer) SetAuditMaxFiles(limit int) {
+	r.auditMaxFiles = limit
+}
+
+// IsManifest reports whether path is a dependency manifest
+func IsManifest(p string) bool {
+	base := path.Base(p)
+	return manifests[base] || (strings.HasPrefix(base, "requirements") && path.Ext(base) == ".txt")
+}
+
+// AuditFiles selects the files under dirs that an audit of kind reads, leaving
+// out generated and vendored files
+func AuditFiles(tree []string, kind string, dirs []string, rules symbols.Rules) []string {
+	var selected []string
+	for _, p := range tree {
+		if !underAny(p, dirs) {
+			continue
+		}
+		if kind == models.AuditDependencies {
+			if !IsManifest(p) {
+				continue
+			}
+		} else if !auditExtensions[path.Ext(p)] {
+			continue
+		}
+		if rules.IsGenerated(p) {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	sort.Strings(selected)
+	return selected
+}
+
+// underAny reports whether p is inside one of dirs; no dirs means the whole tree
+func underAny(p string, dirs []string) bool {
+	if len(dirs) == 0 {
+		return true
+	}
+	for _, dir := range dirs {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fileAsDiff presents a file's full contents as an added file, so the review
+// prompts and the line numbers of their findings work unchanged
+func fileAsDiff(p, content string) (string, models.PRFile) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var patch strings.Builder
+	patch.WriteString(fmt.Sprintf("@@ -0,0 +1,%d @@\n", len(lines)))
+	for _, line := range lines {
+		patch.WriteString("+" + line + "\n")
+	}
+
+	file := models.PRFile{
+		Filename:  p,
+		Status:    "added",
+		Additions: len(lines),
+		Changes:   len(lines),
+		Patch:     strings.TrimSuffix(patch.String(), "\n"),
+	}
+	diff := fmt.Sprintf("diff --git a/%s b/%s\nnew file mode 100644\n--- /dev/null\n+++ b/%s\n%s\n", p, p, p, file.Patch)
+	return diff, file
+}
+
+// auditChunk is the part of an audit sent to Claude in one request
+type auditChunk struct {
+	diff  string
+	files []models.PRFile
+}
+
+// ProcessAudit audits the files under dirs at the head of the default branch,
+// sending them to Claude in chunks of whole files, and opens an issue with the
+// findings. The audit is stored as a review with an audit target.
+func (r *Reviewer) ProcessAudit(ctx context.Context, owner, repo, kind string, dirs []string) error {
+	processStart := time.Now()
+	mode := models.ModeSecurity
+	scope := "the repository"
+	if len(dirs) > 0 {
+		scope = "`" + strings.Join(dirs, "`, `") + "`"
+	}
+
+	log.Info().
+		Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+		Str("kind", kind).
+		Strs("paths", dirs).
+		Msg("Processing scheduled audit")
+
+	branch, sha, err := r.githubClient.GetDefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to resolve default branch: %w", err)
+	}
+	target := &models.ReviewTarget{Kind: models.TargetAudit, Head: sha, Ref: branch, Paths: dirs}
+	title := fmt.Sprintf("Scheduled %s audit of %s on %s", kind, scope, branch)
+
+	var reviewID uint
+	if r.store != nil {
+		record := &database.Review{
+			Owner:       owner,
+			Repo:        repo,
+			PRTitle:     title,
+			CommitSHA:   sha,
+			Mode:        string(mode),
+			Status:      "processing",
+			QueuedAt:    processStart,
+			StartedAt:   &processStart,
+			RequestedBy: "schedule",
+			TargetKind:  string(models.TargetAudit),
+			Ref:         branch,
+		}
+		if err := r.store.CreateReview(record); err != nil {
+			log.Warn().Err(err).Msg("Failed to record audit")
+		} else {
+			reviewID = record.ID
+		}
+	}
+
+	fail := func(message string, err error) error {
+		if r.store != nil && reviewID > 0 {
+			completedAt := time.Now()
+			if updateErr := r.store.UpdateReview(reviewID, map[string]interface{}{
+				"status":        "failed",
+				"error_message": fmt.Sprintf("%s: %v", message, err),
+				"completed_at":  completedAt,
+				"duration_ms":   completedAt.Sub(processStart).Milliseconds(),
+			}); updateErr != nil {
+				log.Warn().Err(updateErr).Msg("Failed to update audit status to failed")
+			}
+		}
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
+	tree, err := r.githubClient.ListTree(ctx, owner, repo, sha)
+	if err != nil {
+		return fail("Failed to list repository files", err)
+	}
+	var rules symbols.Rules
+	if r.skipGenerated {
+		rules = r.generatedRules(ctx, owner, repo, sha)
+	}
+	paths := AuditFiles(tree, kind, dirs, rules)
+	if len(paths) == 0 {
+		return fail("No files to audit", fmt.Errorf("no %s files under %s", kind, scope))
+	}
+	limit := r.auditMaxFiles
+	if limit <= 0 {
+		limit = defaultAuditMaxFiles
+	}
+	var notes []string
+	if len(paths) > limit {
+		notes = append(notes, fmt.Sprintf("Only the first %d of %d files were audited.", limit, len(paths)))
+		paths = paths[:limit]
+	}
+
+	// Chunk by file so every request sees whole files
+	var chunks []auditChunk
+	var current auditChunk
+	var tooLarge []string
+	auditedFiles := 0
+	for _, p := range paths {
+		content, err := r.githubClient.GetFileContent(ctx, owner, repo, p, sha)
+		if err != nil {
+			log.Warn().Err(err).Str("path", p).Msg("Failed to fetch file for audit")
+			continue
+		}
+		if content == "" || (kind != models.AuditDependencies && symbols.HasGeneratedHeader(content)) {
+			continue
+		}
+		diff, file := fileAsDiff(p, content)
+		if len(diff) > r.maxDiffSize {
+			tooLarge = append(tooLarge, p)
+			continue
+		}
+		if len(current.diff)+len(diff) > r.maxDiffSize && len(current.files) > 0 {
+			chunks = append(chunks, current)
+			current = auditChunk{}
+		}
+		current.diff += diff
+		current.files = append(current.files, file)
+		auditedFiles++
+	}
+	if len(current.files) > 0 {
+		chunks = append(chunks, current)
+	}
+	if len(tooLarge) > 0 {
+		notes = append(notes, fmt.Sprintf("Skipped %d file(s) larger than the diff size limit: `%s`", len(tooLarge), strings.Join(tooLarge, "`, `")))
+	}
+	if len(chunks) == 0 {
+		return fail("No files to audit", fmt.Errorf("none of the %d selected files could be read", len(paths)))
+	}
+
+	command := models.Command{Mode: mode, Raw: "schedule " + kind}
+	if kind == models.AuditDependencies {
+		command.Options.Focus = dependencyFocus
+	}
+
+	var summaries []string
+	var findings []models.ReviewComment
+	for i, chunk := range chunks {
+		request := &models.ReviewRequest{
+			Owner:   owner,
+			Repo:    repo,
+			Command: command,
+			Diff:    chunk.diff,
+			PRTitle: title,
+			Files:   chunk.files,
+			Target:  target,
+		}
+		if r.promptSelector != nil {
+			selection := r.promptSelector.Select(request)
+			request.SystemPrompt = selection.Prompt
+			request.PromptVersion = selection.Version
+		}
+
+		if r.rateLimiter != nil {
+			if err := r.rateLimiter.Wait(ctx); err != nil {
+				return fail("Rate limit wait cancelled", err)
+			}
+		}
+		response, err := r.claudeClient.ReviewCode(ctx, request)
+		if r.rateLimiter != nil {
+			r.rateLimiter.Release()
+		}
+		if err != nil {
+			return fail(fmt.Sprintf("Failed to audit part %d of %d", i+1, len(chunks)), err)
+		}
+
+		summary, comments := ParseStructuredReview(response)
+		if summary = strings.TrimSpace(summary); summary != "" {
+			if len(chunks) > 1 {
+				summary = fmt.Sprintf("#### Part %d of %d\n\n%s", i+1, len(chunks), summary)
+			}
+			summaries = append(summaries, summary)
+		}
+		findings = append(findings, comments...)
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Audited %d file(s) under %s at `%s` (%s).\n\n", auditedFiles, scope, shortSHA(sha), branch))
+	for _, note := range notes {
+		body.WriteString(note + "\n\n")
+	}
+	body.WriteString(strings.Join(summaries, "\n\n"))
+	if list := formatTargetFindings(owner, repo, sha, findings); list != "" {
+		body.WriteString("\n\n" + list)
+	}
+	review := TruncateForGitHub(FormatReview(body.String(), mode), maxCommentSize)
+
+	issue, err := r.githubClient.CreateIssue(ctx, owner, repo, fmt.Sprintf("%s %s", GetModeEmoji(mode), title), review)
+	if err != nil {
+		return fail("Failed to open audit issue", err)
+	}
+	r.storeComments(reviewID, mode, findings)
+
+	log.Info().
+		Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+		Int("issue", issue).
+		Int("files", auditedFiles).
+		Int("findings", len(findings)).
+		Msg("Scheduled audit posted")
+
+	if r.store != nil && reviewID > 0 {
+		completedAt := time.Now()
+		if err := r.store.UpdateReview(reviewID, map[string]interface{}{
+			"status":          "completed",
+			"completed_at":    completedAt,
+			"duration_ms":     completedAt.Sub(processStart).Milliseconds(),
+			"files_changed":   auditedFiles,
+			"bugs_found":      len(findings),
+			"comments_posted": 1,
+			"review_body":     review,
+		}); err != nil {
+			log.Warn().Err(err).Msg("Failed to update audit metrics")
+		}
+	}
+
+	return nil
+}
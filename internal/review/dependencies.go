@@ -0,0 +1,151 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CREVIOS/revo/internal/deps"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// maxDependencyRows bounds the packages listed in the dependency section
+const maxDependencyRows = 50
+
+// dependencyChanges fetches each changed manifest at base and head and
+// returns the package changes between them
+func (r *Reviewer) dependencyChanges(ctx context.Context, owner, repo, base, head string, files []models.PRFile) []models.DependencyChange {
+	var manifests []deps.Manifest
+	for _, file := range files {
+		if !deps.Supported(file.Filename) {
+			continue
+		}
+		manifest := deps.Manifest{Path: file.Filename}
+		if file.Status != "added" {
+			if base == "" {
+				continue
+			}
+			previous := file.Filename
+			if file.PreviousName != "" {
+				previous = file.PreviousName
+			}
+			content, err := r.githubClient.GetFileContent(ctx, owner, repo, previous, base)
+			if err != nil {
+				log.Warn().Err(err).Str("path", previous).Msg("Failed to fetch manifest before the change")
+				continue
+			}
+			manifest.Old = content
+		}
+		if file.Status != "removed" {
+			content, err := r.githubClient.GetFileContent(ctx, owner, repo, file.Filename, head)
+			if err != nil {
+				log.Warn().Err(err).Str("path", file.Filename).Msg("Failed to fetch manifest after the change")
+				continue
+			}
+			manifest.New = content
+		}
+		manifests = append(manifests, manifest)
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+	return r.dependencies.Check(manifests)
+}
+
+// FormatDependencyChanges renders the package changes as a table with their
+// licenses and known vulnerabilities, returning "" when there are none
+func FormatDependencyChanges(changes []models.DependencyChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	manifests := map[string]bool{}
+	vulnerable, disallowed := 0, 0
+	for _, change := range changes {
+		manifests[change.Manifest] = true
+		if len(change.Vulnerabilities) > 0 {
+			vulnerable++
+		}
+		if change.Disallowed {
+			disallowed++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### 📦 Dependency Changes\n\n")
+	sb.WriteString(fmt.Sprintf("%d package change(s) in %d manifest(s)", len(changes), len(manifests)))
+	if vulnerable > 0 {
+		sb.WriteString(fmt.Sprintf("; ⚠️ %d with known vulnerabilities", vulnerable))
+	}
+	if disallowed > 0 {
+		sb.WriteString(fmt.Sprintf("; ⛔ %d with a license not on the allowlist", disallowed))
+	}
+	sb.WriteString(".\n\n")
+
+	sb.WriteString("| Package | Manifest | Change | License | Known vulnerabilities |\n")
+	sb.WriteString("|---------|----------|--------|---------|-----------------------|\n")
+	for i, change := range changes {
+		if i == maxDependencyRows {
+			sb.WriteString(fmt.Sprintf("\n_…and %d more._\n", len(changes)-maxDependencyRows))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |\n",
+			change.Name, change.Manifest, formatVersionChange(change), formatLicense(change), formatVulnerabilities(change)))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatVersionChange describes how a package's version changed
+func formatVersionChange(change models.DependencyChange) string {
+	switch change.Change {
+	case "added":
+		return fmt.Sprintf("added `%s`", change.NewVersion)
+	case "removed":
+		return fmt.Sprintf("removed `%s`", change.OldVersion)
+	}
+	return fmt.Sprintf("%s `%s` → `%s`", change.Change, change.OldVersion, change.NewVersion)
+}
+
+// formatLicense shows a package's license, marking one the allowlist does not permit
+func formatLicense(change models.DependencyChange) string {
+	switch {
+	case change.Change == "removed":
+		return "—"
+	case change.License == "":
+		return "unknown"
+	case change.Disallowed:
+		return fmt.Sprintf("⛔ %s (not allowed)", change.License)
+	}
+	return change.License
+}
+
+// formatVulnerabilities links the advisories affecting a package's new version
+func formatVulnerabilities(change models.DependencyChange) string {
+	switch {
+	case change.Change == "removed":
+		return "—"
+	case len(change.Vulnerabilities) == 0 && change.Checked:
+		return "none known"
+	case len(change.Vulnerabilities) == 0 && !change.Pinned:
+		return "range, not checked"
+	case len(change.Vulnerabilities) == 0:
+		return "—"
+	}
+
+	items := make([]string, 0, len(change.Vulnerabilities))
+	for _, vuln := range change.Vulnerabilities {
+		item := fmt.Sprintf("⚠️ [%s](https://osv.dev/vulnerability/%s)", vuln.ID, vuln.ID)
+		var details []string
+		if vuln.Severity != "" {
+			details = append(details, vuln.Severity)
+		}
+		if vuln.Fixed != "" {
+			details = append(details, "fixed in `"+vuln.Fixed+"`")
+		} else {
+			details = append(details, "no fix")
+		}
+		items = append(items, item+" ("+strings.Join(details, ", ")+")")
+	}
+	return strings.Join(items, "<br>")
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/internal/symbols"
 	"github.com/CREVIOS/revo/pkg/models"
 )
 
@@ -199,10 +201,62 @@ func ParseStructuredReview(review string) (summary string, comments []models.Rev
 		})
 	}
 
+	for i := range comments {
+		comments[i].Severity = InferSeverity(comments[i].Body)
+	}
+
 	summary = strings.TrimSpace(summaryBuilder.String())
 	return
 }
 
+// InferSeverity derives error, warning or info from the markers Claude uses in a comment
+func InferSeverity(body string) string {
+	lower := strings.ToLower(body)
+	switch {
+	case strings.Contains(body, "🔴"), strings.Contains(body, "🐛"),
+		strings.Contains(lower, "**critical**"), strings.Contains(lower, "**high**"),
+		strings.Contains(lower, "**error**"), strings.Contains(lower, "**bug**"):
+		return "error"
+	case strings.Contains(body, "🔵"), strings.Contains(lower, "**low**"),
+		strings.Contains(lower, "**info**"), strings.Contains(lower, "**nit**"),
+		strings.Contains(lower, "**suggestion**"):
+		return "info"
+	default:
+		return "warning"
+	}
+}
+
+// severityRank orders severities so they can be compared
+func severityRank(severity string) int {
+	switch severity {
+	case "error":
+		return 3
+	case "warning":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// FilterBySeverity drops comments below the minimum severity.
+// An empty minimum keeps every comment.
+func FilterBySeverity(comments []models.ReviewComment, minSeverity string) []models.ReviewComment {
+	if minSeverity == "" {
+		return comments
+	}
+
+	minRank := severityRank(minSeverity)
+	filtered := make([]models.ReviewComment, 0, len(comments))
+	for _, comment := range comments {
+		if severityRank(comment.Severity) >= minRank {
+			filtered = append(filtered, comment)
+		}
+	}
+	return filtered
+}
+
 // TruncateForGitHub truncates content to fit GitHub's comment size limit
 func TruncateForGitHub(content string, maxLength int) string {
 	if maxLength <= 0 {
@@ -225,6 +279,14 @@ func TruncateForGitHub(content string, maxLength int) string {
 	return content[:truncateAt] + "\n\n---\n⚠️ *Review truncated due to length. Some findings may not be shown.*"
 }
 
+// appendSection adds a section to the end of a comment, when there is one
+func appendSection(body, section string) string {
+	if section == "" {
+		return body
+	}
+	return strings.TrimSpace(body) + "\n\n" + section
+}
+
 // CollapsibleSection wraps content in a collapsible details section
 func CollapsibleSection(summary, content string) string {
 	return fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s\n\n</details>", summary, content)
@@ -251,3 +313,103 @@ func FormatFileSummary(files []models.PRFile) string {
 
 	return sb.String()
 }
+
+// FormatSkippedFiles lists the generated and vendored files left out of a review
+func FormatSkippedFiles(files []models.PRFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+	noun := "files"
+	if len(files) == 1 {
+		noun = "file"
+	}
+	return CollapsibleSection(
+		fmt.Sprintf("Skipped %d generated, vendored or lock %s", len(files), noun),
+		FormatFileSummary(files))
+}
+
+// FormatFindingsBySymbol groups findings under the function or type their
+// line falls in, returning "" when no finding could be placed
+func FormatFindingsBySymbol(files []models.PRFile, comments []models.ReviewComment) string {
+	type group struct {
+		label    string
+		comments []models.ReviewComment
+	}
+	var groups []*group
+	byLabel := map[string]*group{}
+	placed := false
+
+	for _, c := range comments {
+		name, kind := symbols.SymbolAt(files, c.Path, c.Line)
+		label := fmt.Sprintf("`%s` (outside any function)", c.Path)
+		if name != "" {
+			label = fmt.Sprintf("%s `%s` in `%s`", kind, name, c.Path)
+			placed = true
+		}
+		g, ok := byLabel[label]
+		if !ok {
+			g = &group{label: label}
+			byLabel[label] = g
+			groups = append(groups, g)
+		}
+		g.comments = append(g.comments, c)
+	}
+	if !placed {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Findings by symbol\n\n")
+	for _, g := range groups {
+		sb.WriteString(fmt.Sprintf("- %s\n", g.label))
+		for _, c := range g.comments {
+			sb.WriteString(fmt.Sprintf("  - line %d: %s\n", c.Line, firstLine(FormatInlineComment(c.Body, c.Severity))))
+		}
+	}
+	return sb.String()
+}
+
+// firstLine returns the first non-empty line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// FormatHelp renders the reply to `@bot help` on owner/repo
+func FormatHelp(botUsername, owner, repo string) string {
+	var sb strings.Builder
+
+	sb.WriteString("## 🤖 TechyBot Help\n\n")
+	sb.WriteString("### Review modes\n\n")
+	sb.WriteString("| Command | Mode |\n")
+	sb.WriteString("|---------|------|\n")
+	for _, mode := range modes.Available(owner, repo) {
+		sb.WriteString(fmt.Sprintf("| `@%s %s` | %s %s |\n", botUsername, mode.Name, mode.Emoji, mode.Description))
+	}
+
+	sb.WriteString("\n`triage` and `explain` run on issues; the other modes run on pull requests.\n")
+
+	sb.WriteString("\n### Options\n\n")
+	sb.WriteString("| Option | Description |\n")
+	sb.WriteString("|--------|-------------|\n")
+	sb.WriteString("| `verbose` / `--verbose` | More detailed analysis |\n")
+	sb.WriteString("| `--files GLOB` | Only review matching paths (repeatable, comma separated) |\n")
+	sb.WriteString("| `--exclude GLOB` | Skip matching paths |\n")
+	sb.WriteString("| `--model NAME` | Use `opus`, `sonnet`, `haiku` or a full model id |\n")
+	sb.WriteString("| `--min-severity LEVEL` | Only post `error`, `warning` or `info` findings and above |\n")
+	sb.WriteString("| `--focus \"TEXT\"` | Area to concentrate on |\n")
+	sb.WriteString("\nAny other text after the mode is passed to the reviewer as extra instructions.\n")
+
+	sb.WriteString("\n### Other commands\n\n")
+	sb.WriteString(fmt.Sprintf("- `@%s help` - show this message\n", botUsername))
+	sb.WriteString(fmt.Sprintf("- `@%s status` - show queued and running reviews for this PR\n", botUsername))
+	sb.WriteString(fmt.Sprintf("- `@%s cancel` - cancel your in-flight reviews on this PR\n", botUsername))
+	sb.WriteString(fmt.Sprintf("- `@%s approve` - start reviews waiting for maintainer approval (write access required)\n", botUsername))
+	sb.WriteString(fmt.Sprintf("- `@%s fix` - reply to a finding to commit its suggested change to the PR branch (write access required)\n", botUsername))
+
+	return sb.String()
+}
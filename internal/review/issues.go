@@ -0,0 +1,237 @@
+package review
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CREVIOS/revo/internal/sandbox"
+	"github.com/CREVIOS/revo/internal/symbols"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// maxIssueSearches bounds the code searches run for one issue command; GitHub
+// allows few code searches per minute
+const maxIssueSearches = 5
+
+// maxIssueFiles bounds the candidate files whose contents are sent with an issue
+const maxIssueFiles = 5
+
+var (
+	codeSpanPattern   = regexp.MustCompile("`([^`\\n]{3,80})`")
+	filePathPattern   = regexp.MustCompile(`[\w./-]*\w\.(?:go|py|js|jsx|ts|tsx|java|kt|rb|rs|php|cs|swift|c|cc|cpp|h|sql|sh|ya?ml|toml)\b`)
+	identifierPattern = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*\b`)
+	labelsLinePattern = regexp.MustCompile(`(?m)^LABELS:[ \t]*(.*)$`)
+)
+
+// IssueKeywords picks the terms of an issue that look like code, to search
+// the repository with: file names, then identifiers in code spans, then
+// camelCase, PascalCase and snake_case words. File names are returned as
+// filename: qualifiers.
+func IssueKeywords(text string) []string {
+	seen := map[string]bool{}
+	var keywords []string
+	add := func(term string) {
+		key := strings.ToLower(term)
+		if len(term) < 4 || seen[key] || len(keywords) == maxIssueSearches {
+			return
+		}
+		seen[key] = true
+		keywords = append(keywords, term)
+	}
+
+	for _, p := range filePathPattern.FindAllString(text, -1) {
+		add("filename:" + path.Base(p))
+	}
+	var spans []string
+	for _, match := range codeSpanPattern.FindAllStringSubmatch(text, -1) {
+		spans = append(spans, match[1])
+	}
+	for _, source := range []string{strings.Join(spans, " "), text} {
+		for _, word := range identifierPattern.FindAllString(source, -1) {
+			// Search for the name a call or selector ends in
+			word = word[strings.LastIndex(word, ".")+1:]
+			if source == text && !looksLikeCode(word) {
+				continue
+			}
+			add(word)
+		}
+	}
+	return keywords
+}
+
+// looksLikeCode reports whether a word is written like an identifier rather than prose
+func looksLikeCode(word string) bool {
+	if strings.Contains(strings.Trim(word, "_"), "_") {
+		return true
+	}
+	for i := 1; i < len(word); i++ {
+		if word[i] >= 'A' && word[i] <= 'Z' && word[i-1] >= 'a' && word[i-1] <= 'z' {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSuggestedLabels replaces the LABELS line of a triage with the
+// suggested labels that exist in the repository. When the repository has no
+// labels the suggestions are kept as proposals.
+func FormatSuggestedLabels(response string, labels []string) string {
+	match := labelsLinePattern.FindStringSubmatchIndex(response)
+	if match == nil {
+		return response
+	}
+
+	existing := map[string]string{}
+	for _, label := range labels {
+		existing[strings.ToLower(label)] = label
+	}
+
+	var kept []string
+	for _, item := range strings.Split(response[match[2]:match[3]], ",") {
+		item = strings.TrimSpace(strings.Trim(strings.TrimSpace(item), "`\"'"))
+		if item == "" || strings.EqualFold(item, "none") {
+			continue
+		}
+		if len(labels) == 0 {
+			kept = append(kept, "`"+item+"`")
+		} else if name, ok := existing[strings.ToLower(item)]; ok {
+			kept = append(kept, "`"+name+"`")
+		}
+	}
+
+	line := strings.Join(kept, " ")
+	switch {
+	case len(kept) == 0:
+		line = "_None of the repository's labels fit._"
+	case len(labels) == 0:
+		line = "The repository has no labels yet; proposed: " + line
+	}
+	return response[:match[0]] + line + response[match[1]:]
+}
+
+// processIssue answers a triage or explain command on a plain issue. It searches
+// the repository at the default branch for the code the issue refers to and
+// returns the posted comment and the number of candidate files sent.
+func (r *Reviewer) processIssue(ctx context.Context, owner, repo string, number int, command models.Command) (string, int, error) {
+	issue, err := r.githubClient.GetIssue(ctx, owner, repo, number)
+	if err != nil {
+		return "", 0, err
+	}
+	branch, sha, err := r.githubClient.GetDefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var labels []string
+	if command.Mode == models.ModeTriage {
+		if labels, err = r.githubClient.ListLabels(ctx, owner, repo); err != nil {
+			log.Warn().Err(err).Msg("Failed to list labels, suggesting labels without them")
+		}
+	}
+
+	// Rank files by how many of the issue's terms they match
+	hits := map[string][]string{}
+	keywords := IssueKeywords(issue.GetTitle() + "\n" + issue.GetBody() + "\n" + command.Options.Text)
+	for _, keyword := range keywords {
+		paths, err := r.githubClient.SearchCode(ctx, owner, repo, keyword, 10)
+		if err != nil {
+			log.Warn().Err(err).Str("keyword", keyword).Msg("Code search failed")
+			continue
+		}
+		for _, p := range paths {
+			if !symbols.IsGeneratedPath(p) {
+				hits[p] = append(hits[p], strings.TrimPrefix(keyword, "filename:"))
+			}
+		}
+	}
+	ranked := make([]string, 0, len(hits))
+	for p := range hits {
+		ranked = append(ranked, p)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if len(hits[ranked[i]]) != len(hits[ranked[j]]) {
+			return len(hits[ranked[i]]) > len(hits[ranked[j]])
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	var contextFiles []models.ContextFile
+	size := 0
+	for _, p := range ranked {
+		if len(contextFiles) == maxIssueFiles {
+			break
+		}
+		content, err := r.githubClient.GetFileContent(ctx, owner, repo, p, sha)
+		if err != nil || content == "" || size+len(content) > r.maxDiffSize {
+			continue
+		}
+		size += len(content)
+		contextFiles = append(contextFiles, models.ContextFile{
+			Path:    p,
+			Content: content,
+			Reason:  "matches `" + strings.Join(hits[p], "`, `") + "`",
+		})
+	}
+
+	request := &models.ReviewRequest{
+		Owner:        owner,
+		Repo:         repo,
+		Command:      command,
+		PRTitle:      issue.GetTitle(),
+		PRBody:       issue.GetBody(),
+		ContextFiles: contextFiles,
+		Target:       &models.ReviewTarget{Kind: models.TargetIssue, Head: sha, Ref: branch, Issue: number},
+		Labels:       labels,
+	}
+
+	// Let the CLI explore beyond the search results when checkouts are enabled
+	if r.sandbox != nil {
+		var workspace *sandbox.Workspace
+		workspace, err = r.sandbox.Checkout(ctx, owner, repo, sha)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to check out repository, answering from search results")
+		}
+		defer workspace.Close()
+		if workspace != nil {
+			request.WorkDir = workspace.Dir
+			request.TmpDir = workspace.TmpDir
+		}
+	}
+
+	if r.promptSelector != nil {
+		selection := r.promptSelector.Select(request)
+		request.SystemPrompt = selection.Prompt
+		request.PromptVersion = selection.Version
+	}
+
+	if r.rateLimiter != nil {
+		if err := r.rateLimiter.Wait(ctx); err != nil {
+			return "", 0, err
+		}
+		defer r.rateLimiter.Release()
+	}
+
+	response, err := r.claudeClient.ReviewCode(ctx, request)
+	if err != nil {
+		return "", 0, err
+	}
+	if command.Mode == models.ModeTriage {
+		response = FormatSuggestedLabels(response, labels)
+	}
+
+	// Triage is kept current in one comment; every explanation answers its own question
+	body := TruncateForGitHub(FormatReview(response, command.Mode), maxCommentSize)
+	if command.Mode == models.ModeTriage {
+		err = r.postComment(ctx, owner, repo, number, command.Mode, sha, body)
+	} else {
+		err = r.githubClient.CreateComment(ctx, owner, repo, number, body)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return body, len(contextFiles), nil
+}
@@ -0,0 +1,87 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/migrations"
+	"github.com/CREVIOS/revo/internal/symbols"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// SetMigrationPaths sets the globs of migration files the migrations mode
+// reviews; none reviews every changed SQL file
+func (r *Reviewer) SetMigrationPaths(patterns []string) {
+	r.migrationPaths = patterns
+}
+
+// migrationFiles narrows the files and diff to the changed migrations
+func (r *Reviewer) migrationFiles(files []models.PRFile, diff string) ([]models.PRFile, string) {
+	var kept []models.PRFile
+	for _, file := range files {
+		if migrations.IsMigration(r.migrationPaths, file.Filename) {
+			kept = append(kept, file)
+		}
+	}
+	diff = gh.FilterDiff(diff, func(filename string) bool {
+		return migrations.IsMigration(r.migrationPaths, filename)
+	})
+	return kept, diff
+}
+
+// checkMigrations runs the migration rules over the changed SQL files, reading
+// them from source or, failing that, from GitHub at head
+func (r *Reviewer) checkMigrations(ctx context.Context, owner, repo, head string, files []models.PRFile, source symbols.ContentSource) []models.AnalyzerFinding {
+	var checks []models.AnalyzerFinding
+	for _, file := range files {
+		if file.Status == "removed" {
+			continue
+		}
+		content, ok := source(file.Filename)
+		if !ok {
+			var err error
+			content, err = r.githubClient.GetFileContent(ctx, owner, repo, file.Filename, head)
+			if err != nil {
+				log.Warn().Err(err).Str("path", file.Filename).Msg("Failed to fetch migration")
+				continue
+			}
+		}
+		checks = append(checks, migrations.Check(file.Filename, content, gh.GetChangedLineNumbers(file.Patch))...)
+	}
+	return checks
+}
+
+// MigrationComments returns a finding for each migration check the model did
+// not comment on, so every rule hit is posted inline
+func MigrationComments(comments []models.ReviewComment, checks []models.AnalyzerFinding) []models.ReviewComment {
+	covered := map[string]bool{}
+	for _, c := range comments {
+		covered[fmt.Sprintf("%s:%d", c.Path, c.Line)] = true
+	}
+
+	var added []models.ReviewComment
+	for _, check := range checks {
+		key := fmt.Sprintf("%s:%d", check.Path, check.Line)
+		if covered[key] {
+			continue
+		}
+		covered[key] = true
+		emoji := "🟡"
+		switch check.Severity {
+		case "error":
+			emoji = "🔴"
+		case "info":
+			emoji = "🔵"
+		}
+		added = append(added, models.ReviewComment{
+			Path:     check.Path,
+			Line:     check.Line,
+			Severity: check.Severity,
+			Source:   migrations.Tool,
+			Body:     fmt.Sprintf("[%s %s] %s %s", check.Tool, check.Rule, emoji, check.Message),
+		})
+	}
+	return added
+}
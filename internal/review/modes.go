@@ -4,12 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/CREVIOS/revo/internal/analyzers"
 	"github.com/CREVIOS/revo/internal/claude"
 	contextaware "github.com/CREVIOS/revo/internal/context"
 	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/internal/deps"
 	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/internal/prompts"
+	"github.com/CREVIOS/revo/internal/sandbox"
+	"github.com/CREVIOS/revo/internal/sarif"
+	"github.com/CREVIOS/revo/internal/secrets"
+	"github.com/CREVIOS/revo/internal/symbols"
 	"github.com/CREVIOS/revo/pkg/models"
 	"github.com/google/go-github/v60/github"
 	"github.com/rs/zerolog/log"
@@ -23,6 +34,23 @@ type Reviewer struct {
 	contextAnalyzer ContextAnalyzer
 	rateLimiter     RateLimiter
 	store           ReviewStore
+	promptSelector  PromptSelector
+	fileContext     FileContextGatherer
+	sandbox         Sandbox
+	verifier        Verifier
+	analyzer        StaticAnalyzer
+	dependencies    DependencyChecker
+	summaryTarget   string
+	testsDelivery   string
+	compareOutput   string
+	auditMaxFiles   int
+	migrationPaths  []string
+	stickyComments  bool
+	secretScan      bool
+	sarifModes      []string
+
+	skipGenerated     bool
+	generatedPatterns []string
 }
 
 // ContextAnalyzer interface for gathering PR context
@@ -30,14 +58,46 @@ type ContextAnalyzer interface {
 	GatherContext(ctx context.Context, owner, repo string, prNumber int) (contextaware.PRContextBuilder, error)
 }
 
+// FileContextGatherer fetches full file contents to send alongside the diff
+type FileContextGatherer interface {
+	GatherFiles(ctx context.Context, owner, repo, ref string, files []models.PRFile) ([]models.ContextFile, error)
+}
+
+// Sandbox checks out the PR head for the CLI to explore
+type Sandbox interface {
+	Checkout(ctx context.Context, owner, repo, sha string) (*sandbox.Workspace, error)
+}
+
+// Verifier reproduces findings by running generated tests in the checkout
+type Verifier interface {
+	Verify(ctx context.Context, ws *sandbox.Workspace, request *models.ReviewRequest, comments []models.ReviewComment) []models.ReviewComment
+}
+
+// StaticAnalyzer runs static analysis tools in the checkout
+type StaticAnalyzer interface {
+	Analyze(ctx context.Context, dir string, files []models.PRFile) []models.AnalyzerFinding
+}
+
+// DependencyChecker lists and checks the package changes in dependency manifests
+type DependencyChecker interface {
+	Check(manifests []deps.Manifest) []models.DependencyChange
+}
+
 // RateLimiter interface for rate limiting
 type RateLimiter interface {
 	Wait(ctx context.Context) error
 	Release()
 }
 
+// PromptSelector chooses the system prompt version for a review
+type PromptSelector interface {
+	Select(request *models.ReviewRequest) prompts.Selection
+}
+
 // ReviewStore provides persistence hooks for review lifecycle events.
 type ReviewStore interface {
+	CreateReview(review *database.Review) error
+	GetReview(id uint) (*database.Review, error)
 	UpdateReview(id uint, updates map[string]interface{}) error
 	CreateReviewComment(comment *database.ReviewComment) error
 }
@@ -48,6 +108,10 @@ func NewReviewer(githubClient *gh.Client, claudeClient *claude.Client, maxDiffSi
 		githubClient: githubClient,
 		claudeClient: claudeClient,
 		maxDiffSize:  maxDiffSize,
+
+		skipGenerated:  true,
+		stickyComments: true,
+		secretScan:     true,
 	}
 }
 
@@ -56,6 +120,42 @@ func (r *Reviewer) SetContextAnalyzer(analyzer ContextAnalyzer) {
 	r.contextAnalyzer = analyzer
 }
 
+// SetFileContext sets the gatherer for full file contents
+func (r *Reviewer) SetFileContext(gatherer FileContextGatherer) {
+	r.fileContext = gatherer
+}
+
+// SetSandbox sets the repository checkout manager
+func (r *Reviewer) SetSandbox(sb Sandbox) {
+	r.sandbox = sb
+}
+
+// SetVerifier sets the bug verifier; it needs a sandbox to run in
+func (r *Reviewer) SetVerifier(verifier Verifier) {
+	r.verifier = verifier
+}
+
+// SetAnalyzer sets the static analysis runner; it needs a sandbox to run in
+func (r *Reviewer) SetAnalyzer(analyzer StaticAnalyzer) {
+	r.analyzer = analyzer
+}
+
+// SetDependencyChecker enables the dependency section for reviews that change manifests
+func (r *Reviewer) SetDependencyChecker(checker DependencyChecker) {
+	r.dependencies = checker
+}
+
+// SetSummaryTarget chooses where `summarize` writes: the sticky comment or the PR body
+func (r *Reviewer) SetSummaryTarget(target string) {
+	r.summaryTarget = target
+}
+
+// SetStickyComments edits one comment per mode on re-runs, with a history of
+// past runs, instead of posting a new comment each time
+func (r *Reviewer) SetStickyComments(enabled bool) {
+	r.stickyComments = enabled
+}
+
 // SetRateLimiter sets the rate limiter
 func (r *Reviewer) SetRateLimiter(limiter RateLimiter) {
 	r.rateLimiter = limiter
@@ -66,6 +166,23 @@ func (r *Reviewer) SetStore(store ReviewStore) {
 	r.store = store
 }
 
+// SetPromptSelector sets the selector for versioned prompt templates
+func (r *Reviewer) SetPromptSelector(selector PromptSelector) {
+	r.promptSelector = selector
+}
+
+// SetSARIFUpload uploads findings to GitHub code scanning after reviews in these modes
+func (r *Reviewer) SetSARIFUpload(modes []string) {
+	r.sarifModes = modes
+}
+
+// SetGeneratedFiles controls whether generated, vendored and lock files are left
+// out of reviews, with extra glob patterns on top of the built-in list
+func (r *Reviewer) SetGeneratedFiles(skip bool, patterns []string) {
+	r.skipGenerated = skip
+	r.generatedPatterns = patterns
+}
+
 // ProcessReview handles a complete review request from webhook to GitHub comment
 func (r *Reviewer) ProcessReview(ctx context.Context, event *gh.WebhookEvent) error {
 	owner := event.Repository.Owner.Login
@@ -81,6 +198,14 @@ func (r *Reviewer) ProcessReview(ctx context.Context, event *gh.WebhookEvent) er
 		Msg("Processing review request")
 
 	if r.store != nil && reviewID > 0 {
+		// Honour `@techy cancel` issued while the task was queued or before a retry
+		if existing, err := r.store.GetReview(reviewID); err == nil && existing.Status == "cancelled" {
+			log.Info().
+				Uint("review_id", reviewID).
+				Msg("Review was cancelled, skipping")
+			return nil
+		}
+
 		if err := r.store.UpdateReview(reviewID, map[string]interface{}{
 			"status":     "processing",
 			"started_at": processStart,
@@ -106,65 +231,213 @@ func (r *Reviewer) ProcessReview(ctx context.Context, event *gh.WebhookEvent) er
 				log.Warn().Err(updateErr).Msg("Failed to update review status to failed")
 			}
 		}
-		return r.postError(ctx, owner, repo, prNumber, event.Comment.ID, message, err)
+		return r.postError(ctx, owner, repo, event.ThreadNumber(), event.Comment.ID, message, err)
 	}
 
-	// Fetch PR details
-	pr, err := r.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
-	if err != nil {
-		return fail("Failed to fetch PR details", err)
-	}
-	if event.PullRequest != nil && event.PullRequest.Head != nil {
-		expectedSHA := event.PullRequest.Head.SHA
-		if expectedSHA != "" && pr.GetHead().GetSHA() != expectedSHA {
-			if r.store != nil && reviewID > 0 {
-				completedAt := time.Now()
-				_ = r.store.UpdateReview(reviewID, map[string]interface{}{
-					"status":        "cancelled",
-					"error_message": fmt.Sprintf("stale commit: expected %s, got %s", expectedSHA, pr.GetHead().GetSHA()),
-					"completed_at":  completedAt,
-					"duration_ms":   completedAt.Sub(processStart).Milliseconds(),
-				})
+	target := event.Target
+	if target != nil && target.Kind == models.TargetIssue {
+		review, contextFiles, err := r.processIssue(ctx, owner, repo, target.Issue, *event.Command)
+		if err != nil {
+			return fail(fmt.Sprintf("Failed to %s the issue", event.Command.Mode), err)
+		}
+		if err := r.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "rocket"); err != nil {
+			log.Warn().Err(err).Msg("Failed to add rocket reaction")
+		}
+		if r.store != nil && reviewID > 0 {
+			completedAt := time.Now()
+			if err := r.store.UpdateReview(reviewID, map[string]interface{}{
+				"status":          "completed",
+				"completed_at":    completedAt,
+				"duration_ms":     completedAt.Sub(processStart).Milliseconds(),
+				"files_changed":   contextFiles,
+				"comments_posted": 1,
+				"review_body":     review,
+			}); err != nil {
+				log.Warn().Err(err).Msg("Failed to update review metrics")
 			}
-			log.Info().
-				Str("expected_sha", expectedSHA).
-				Str("current_sha", pr.GetHead().GetSHA()).
-				Msg("Skipping stale review task")
-			return nil
 		}
+		return nil
 	}
 
-	// Fetch PR diff
-	diff, err := r.githubClient.GetPullRequestDiff(ctx, owner, repo, prNumber)
-	if err != nil {
-		return fail("Failed to fetch PR diff", err)
+	var pr *github.PullRequest
+	var diff string
+	var ghFiles []*github.CommitFile
+	var err error
+	if target.IsPullRequest() {
+		// Fetch PR details
+		pr, err = r.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+		if err != nil {
+			return fail("Failed to fetch PR details", err)
+		}
+		if event.PullRequest != nil && event.PullRequest.Head != nil {
+			expectedSHA := event.PullRequest.Head.SHA
+			if expectedSHA != "" && pr.GetHead().GetSHA() != expectedSHA {
+				if r.store != nil && reviewID > 0 {
+					completedAt := time.Now()
+					_ = r.store.UpdateReview(reviewID, map[string]interface{}{
+						"status":        "cancelled",
+						"error_message": fmt.Sprintf("stale commit: expected %s, got %s", expectedSHA, pr.GetHead().GetSHA()),
+						"completed_at":  completedAt,
+						"duration_ms":   completedAt.Sub(processStart).Milliseconds(),
+					})
+				}
+				log.Info().
+					Str("expected_sha", expectedSHA).
+					Str("current_sha", pr.GetHead().GetSHA()).
+					Msg("Skipping stale review task")
+				return nil
+			}
+		}
+
+		// Fetch PR diff
+		diff, err = r.githubClient.GetPullRequestDiff(ctx, owner, repo, prNumber)
+		if err != nil {
+			return fail("Failed to fetch PR diff", err)
+		}
+
+		// Fetch PR files
+		ghFiles, err = r.githubClient.GetPullRequestFiles(ctx, owner, repo, prNumber)
+		if err != nil {
+			return fail("Failed to fetch PR files", err)
+		}
+	} else {
+		// Summaries and generated tests are written to a pull request
+		if event.Command.Mode == models.ModeSummarize || event.Command.Mode == models.ModeTests {
+			return fail("Unsupported review target", fmt.Errorf("%s runs on pull requests only", event.Command.Mode))
+		}
+		pr, diff, ghFiles, err = r.fetchTarget(ctx, owner, repo, target)
+		if err != nil {
+			return fail(fmt.Sprintf("Failed to fetch %s", target.Kind), err)
+		}
 	}
 
-	// Truncate diff if too large
-	if len(diff) > r.maxDiffSize {
-		log.Warn().
-			Int("original_size", len(diff)).
-			Int("max_size", r.maxDiffSize).
-			Msg("Diff exceeds max size, truncating")
-		diff = gh.TruncateDiff(diff, r.maxDiffSize)
+	// Find committed secrets and redact them before anything is sent to Claude
+	var leaked []secrets.Finding
+	if r.secretScan {
+		leaked = secrets.ScanDiff(diff)
+		if len(leaked) > 0 {
+			log.Warn().
+				Int("secrets", len(secrets.Reported(leaked))).
+				Msg("Redacted secrets from the diff")
+			diff = secrets.Redact(diff, leaked)
+		}
 	}
 
-	// Fetch PR files
-	ghFiles, err := r.githubClient.GetPullRequestFiles(ctx, owner, repo, prNumber)
-	if err != nil {
-		return fail("Failed to fetch PR files", err)
+	// Restrict the review to the requested paths
+	opts := event.Command.Options
+	if len(opts.Files) > 0 || len(opts.Exclude) > 0 {
+		diff = gh.FilterDiff(diff, func(filename string) bool {
+			return MatchesPathFilters(opts, filename)
+		})
 	}
+
 	files := gh.ConvertGitHubFiles(ghFiles)
+	if len(opts.Files) > 0 || len(opts.Exclude) > 0 {
+		filtered := files[:0]
+		for _, file := range files {
+			if MatchesPathFilters(opts, file.Filename) {
+				filtered = append(filtered, file)
+			}
+		}
+		files = filtered
+		if len(files) == 0 {
+			return fail("No files to review", fmt.Errorf("no changed files match --files %v / --exclude %v", opts.Files, opts.Exclude))
+		}
+	}
+
+	// The migrations mode reviews the changed migrations only
+	if event.Command.Mode == models.ModeMigrations {
+		files, diff = r.migrationFiles(files, diff)
+		if len(files) == 0 {
+			return fail("No files to review", errors.New("no migration files changed"))
+		}
+	}
+
+	// Leave out vendored, generated and lock files unless asked for by --files
+	var rules symbols.Rules
+	var skipped []models.PRFile
+	if r.skipGenerated {
+		rules = r.generatedRules(ctx, owner, repo, pr.GetHead().GetSHA())
+		symbols.Annotate(files, nil, rules)
+		files, diff, skipped = SkipGenerated(files, diff, opts)
+		if len(files) == 0 {
+			return fail("No files to review", errors.New("all changed files are generated or vendored"))
+		}
+	}
 
 	// Gather context (existing comments, reviews) for smarter analysis
 	var prContext contextaware.PRContextBuilder
-	if r.contextAnalyzer != nil {
+	if r.contextAnalyzer != nil && target.IsPullRequest() {
 		prContext, err = r.contextAnalyzer.GatherContext(ctx, owner, repo, prNumber)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to gather PR context, continuing without it")
 		}
 	}
 
+	// Fetch full file contents so the model sees the code around each hunk
+	var contextFiles []models.ContextFile
+	if r.fileContext != nil {
+		contextFiles, err = r.fileContext.GatherFiles(ctx, owner, repo, pr.GetHead().GetSHA(), files)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to gather file contents, continuing with the diff only")
+		}
+	}
+
+	// Check out the head commit so the CLI can read beyond the diff
+	var workspace *sandbox.Workspace
+	if r.sandbox != nil {
+		workspace, err = r.sandbox.Checkout(ctx, owner, repo, pr.GetHead().GetSHA())
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to check out repository, reviewing without it")
+		}
+		defer workspace.Close()
+		redactWorkspace(workspace, leaked)
+	}
+
+	// Annotate hunks with the functions and types they change; file contents
+	// can reveal more generated files
+	symbols.Annotate(files, contentSource(workspace, contextFiles), rules)
+	if r.skipGenerated {
+		var more []models.PRFile
+		files, diff, more = SkipGenerated(files, diff, opts)
+		skipped = append(skipped, more...)
+	}
+
+	// Run static analyzers on the checkout for the model to confirm or dismiss
+	var analysis []models.AnalyzerFinding
+	if r.analyzer != nil && workspace != nil && event.Command.Mode != models.ModeSummarize && event.Command.Mode != models.ModeTests {
+		analysis = r.analyzer.Analyze(ctx, workspace.Dir, files)
+	}
+
+	// Diff the changed manifests so dependency facts do not come from the model
+	var dependencies []models.DependencyChange
+	if r.dependencies != nil && event.Command.Mode != models.ModeSummarize && event.Command.Mode != models.ModeTests {
+		dependencies = r.dependencyChanges(ctx, owner, repo, pr.GetBase().GetSHA(), pr.GetHead().GetSHA(), files)
+	}
+
+	// Flag dangerous migration statements for the model to judge in context
+	var migrationChecks []models.AnalyzerFinding
+	if event.Command.Mode == models.ModeMigrations {
+		migrationChecks = r.checkMigrations(ctx, owner, repo, pr.GetHead().GetSHA(), files, contentSource(workspace, contextFiles))
+	}
+
+	// Find the changed functions no existing test refers to
+	var existingTests map[string]string
+	var testTargets []models.TestTarget
+	if event.Command.Mode == models.ModeTests {
+		existingTests = r.existingTests(ctx, owner, repo, pr.GetHead().GetSHA(), workspace, files)
+		testTargets = UntestedFunctions(files, existingTests)
+	}
+
+	// Truncate diff if too large
+	if len(diff) > r.maxDiffSize {
+		log.Warn().
+			Int("original_size", len(diff)).
+			Int("max_size", r.maxDiffSize).
+			Msg("Diff exceeds max size, truncating")
+		diff = gh.TruncateDiff(diff, r.maxDiffSize)
+	}
+
 	// Build review request
 	request := &models.ReviewRequest{
 		Owner:       owner,
@@ -175,90 +448,195 @@ func (r *Reviewer) ProcessReview(ctx context.Context, event *gh.WebhookEvent) er
 		CommentBody: event.Comment.Body,
 		Diff:        diff,
 		PRTitle:     pr.GetTitle(),
-		PRBody:      pr.GetBody(),
+		PRBody:      StripSummary(pr.GetBody()),
 		Files:       files,
 		PRContext:   prContext,
+		Target:      target,
+
+		ContextFiles: contextFiles,
+		SkippedFiles: skipped,
+		Analysis:     analysis,
+		TestTargets:  testTargets,
+		Dependencies: dependencies,
+		Migrations:   migrationChecks,
 	}
-
-	// Apply rate limiting before calling Claude Code CLI
-	if r.rateLimiter != nil {
-		log.Debug().Msg("Waiting for rate limiter")
-		if err := r.rateLimiter.Wait(ctx); err != nil {
-			return fail("Rate limit wait cancelled", err)
+	if workspace != nil {
+		request.WorkDir = workspace.Dir
+		request.TmpDir = workspace.TmpDir
+	}
+	// The prompt and its cache key are built from the request, so neither holds the secrets
+	redactRequest(request, leaked)
+
+	// Pick the prompt template variant and record it for experiment analysis
+	if r.promptSelector != nil {
+		selection := r.promptSelector.Select(request)
+		request.SystemPrompt = selection.Prompt
+		request.PromptVersion = selection.Version
+		if r.store != nil && reviewID > 0 {
+			if err := r.store.UpdateReview(reviewID, map[string]interface{}{
+				"prompt_version": selection.Version,
+			}); err != nil {
+				log.Warn().Err(err).Msg("Failed to record prompt version")
+			}
 		}
-		defer r.rateLimiter.Release()
 	}
 
-	// Get review from Claude
-	review, err := r.claudeClient.ReviewCode(ctx, request)
-	if err != nil {
-		return fail("Failed to get review from Claude", err)
+	var review string
+	if event.Command.Mode == models.ModeTests && len(testTargets) == 0 {
+		// Nothing to generate, so there is no need to call Claude
+		review = "All changed functions already have tests that refer to them."
+	} else {
+		// Apply rate limiting before calling Claude Code CLI
+		if r.rateLimiter != nil {
+			log.Debug().Msg("Waiting for rate limiter")
+			if err := r.rateLimiter.Wait(ctx); err != nil {
+				return fail("Rate limit wait cancelled", err)
+			}
+			defer r.rateLimiter.Release()
+		}
+
+		// Get review from Claude
+		review, err = r.claudeClient.ReviewCode(ctx, request)
+		if err != nil {
+			return fail("Failed to get review from Claude", err)
+		}
 	}
 
-	// Parse review for inline comments
-	summary, inlineComments := ParseStructuredReview(review)
 	commentsPosted := 0
-	bugsFound := len(inlineComments)
-
-	// Post inline comments if any were found
-	if len(inlineComments) > 0 {
-		// Get HEAD commit SHA for the PR
-		headSHA := pr.GetHead().GetSHA()
-
-		// Create GitHub draft review comments
-		draftComments := make([]*github.DraftReviewComment, 0, len(inlineComments))
-		for _, comment := range inlineComments {
-			draftComments = append(draftComments, &github.DraftReviewComment{
-				Path: github.String(comment.Path),
-				Line: github.Int(comment.Line),
-				Body: github.String(comment.Body),
-			})
+	bugsFound := 0
+	var inlineComments []models.ReviewComment
+	// Runs without inline comments carry the secret findings in their body
+	secretSection := FormatSecretFindings(leaked)
+	if event.Command.Mode == models.ModeSummarize {
+		// Summaries replace the previous one instead of adding inline comments
+		review = FormatSummary(appendSection(review, secretSection), files, skipped)
+		if err := r.postSummary(ctx, owner, repo, pr, review); err != nil {
+			return fail("Failed to post summary", err)
 		}
-
-		// Create a review with all inline comments
-		reviewBody := fmt.Sprintf("## %s TechyBot %s\n\n%s\n\n---\n<sub>🤖 Powered by Claude Code CLI | Triggered by `@%s %s`</sub>",
-			GetModeEmoji(event.Command.Mode),
-			GetModeDescription(event.Command.Mode),
-			summary,
-			"techy",
-			string(event.Command.Mode))
-
-		if err := r.githubClient.CreateReview(ctx, owner, repo, prNumber, headSHA, reviewBody, draftComments); err != nil {
-			log.Warn().Err(err).Msg("Failed to post review with inline comments, falling back to regular comment")
-			// Fallback to regular comment if review posting fails
-			formattedReview := FormatReview(review, event.Command.Mode)
-			if err := r.githubClient.CreateComment(ctx, owner, repo, prNumber, formattedReview); err != nil {
-				return fail("Failed to post review", err)
+		commentsPosted = 1
+	} else if event.Command.Mode == models.ModeTests {
+		if len(testTargets) == 0 {
+			review = FormatReview(appendSection(review, secretSection), event.Command.Mode)
+			if err := r.postComment(ctx, owner, repo, prNumber, event.Command.Mode, pr.GetHead().GetSHA(), review); err != nil {
+				return fail("Failed to post tests", err)
 			}
 			commentsPosted = 1
 		} else {
-			commentsPosted = len(inlineComments)
+			review, commentsPosted, err = r.deliverTests(ctx, owner, repo, pr, review, existingTests, secretSection)
+			if err != nil {
+				return fail("Failed to post tests", err)
+			}
+		}
+	} else {
+		// Parse review for inline comments
+		var summary string
+		summary, inlineComments = ParseStructuredReview(review)
+		if note := FormatSkippedFiles(skipped); note != "" {
+			summary = strings.TrimSpace(summary) + "\n\n" + note
+			review = strings.TrimSpace(review) + "\n\n" + note
 		}
+		if section := FormatDependencyChanges(dependencies); section != "" {
+			summary = strings.TrimSpace(summary) + "\n\n" + section
+			review = strings.TrimSpace(review) + "\n\n" + section
+		}
+		analyzers.Tag(inlineComments, analysis)
+		analyzers.Tag(inlineComments, migrationChecks)
+		inlineComments = append(inlineComments, MigrationComments(inlineComments, migrationChecks)...)
+		inlineComments = FilterBySeverity(inlineComments, opts.MinSeverity)
+		if r.verifier != nil && workspace != nil && len(inlineComments) > 0 {
+			inlineComments = r.verifier.Verify(ctx, workspace, request, inlineComments)
+		}
+		inlineComments = append(SecretComments(leaked), inlineComments...)
+		bugsFound = len(inlineComments)
 
-		if r.store != nil && reviewID > 0 && commentsPosted == len(inlineComments) {
+		if !target.IsPullRequest() {
+			if grouped := FormatFindingsBySymbol(files, inlineComments); grouped != "" {
+				summary = strings.TrimSpace(summary) + "\n\n" + grouped
+			}
+			commentsPosted, err = r.postTargetReview(ctx, owner, repo, event.Command.Mode, target, files, summary, inlineComments)
+			if err != nil {
+				return fail("Failed to post review", err)
+			}
+			r.storeComments(reviewID, event.Command.Mode, inlineComments)
+		} else if len(inlineComments) > 0 {
+			// Post inline comments if any were found
+			// Get HEAD commit SHA for the PR
+			headSHA := pr.GetHead().GetSHA()
+
+			// Create GitHub draft review comments
+			draftComments := make([]*github.DraftReviewComment, 0, len(inlineComments))
 			for _, comment := range inlineComments {
-				_ = r.store.CreateReviewComment(&database.ReviewComment{
-					ReviewID: reviewID,
-					FilePath: comment.Path,
-					Line:     comment.Line,
-					Severity: comment.Severity,
-					Category: string(event.Command.Mode),
-					Body:     comment.Body,
+				draftComments = append(draftComments, &github.DraftReviewComment{
+					Path: github.String(comment.Path),
+					Line: github.Int(comment.Line),
+					Body: github.String(comment.Body),
 				})
 			}
+
+			if grouped := FormatFindingsBySymbol(files, inlineComments); grouped != "" {
+				summary = strings.TrimSpace(summary) + "\n\n" + grouped
+			}
+
+			// Create a review with all inline comments
+			reviewBody := fmt.Sprintf("## %s TechyBot %s\n\n%s\n\n---\n<sub>🤖 Powered by Claude Code CLI | Triggered by `@%s %s`</sub>",
+				GetModeEmoji(event.Command.Mode),
+				GetModeDescription(event.Command.Mode),
+				summary,
+				"techy",
+				string(event.Command.Mode))
+			if r.stickyComments {
+				reviewBody = StickyMarker(event.Command.Mode) + "\n" + reviewBody
+			}
+
+			if err := r.githubClient.CreateReview(ctx, owner, repo, prNumber, headSHA, reviewBody, draftComments); err != nil {
+				log.Warn().Err(err).Msg("Failed to post review with inline comments, falling back to regular comment")
+				// Fallback to regular comment if review posting fails
+				formattedReview := FormatReview(appendSection(review, secretSection), event.Command.Mode)
+				if err := r.postComment(ctx, owner, repo, prNumber, event.Command.Mode, headSHA, formattedReview); err != nil {
+					return fail("Failed to post review", err)
+				}
+				commentsPosted = 1
+			} else {
+				commentsPosted = len(inlineComments)
+				if r.stickyComments {
+					r.supersedeReviews(ctx, owner, repo, prNumber, event.Command.Mode, true)
+					// Point an earlier sticky comment of this mode at the new review
+					pointer := FormatReview(fmt.Sprintf("The latest run on `%s` posted %d inline comment(s) in a review on this PR.", shortSHA(headSHA), len(inlineComments)), event.Command.Mode)
+					if err := r.updateSticky(ctx, owner, repo, prNumber, event.Command.Mode, headSHA, pointer, false); err != nil {
+						log.Warn().Err(err).Msg("Failed to update sticky comment")
+					}
+				}
+			}
+
+			if commentsPosted == len(inlineComments) {
+				r.storeComments(reviewID, event.Command.Mode, inlineComments)
+			}
+		} else {
+			// No inline comments found, post as regular comment
+			formattedReview := FormatReview(review, event.Command.Mode)
+			if err := r.postComment(ctx, owner, repo, prNumber, event.Command.Mode, pr.GetHead().GetSHA(), formattedReview); err != nil {
+				return fail("Failed to post review", err)
+			}
+			commentsPosted = 1
+			if r.stickyComments {
+				r.supersedeReviews(ctx, owner, repo, prNumber, event.Command.Mode, false)
+			}
 		}
-	} else {
-		// No inline comments found, post as regular comment
-		formattedReview := FormatReview(review, event.Command.Mode)
-		if err := r.githubClient.CreateComment(ctx, owner, repo, prNumber, formattedReview); err != nil {
-			return fail("Failed to post review", err)
+	}
+
+	if r.shouldUploadSARIF(event.Command.Mode) {
+		ref := fmt.Sprintf("refs/pull/%d/head", prNumber)
+		if !target.IsPullRequest() {
+			ref = "refs/heads/" + target.Ref
 		}
-		commentsPosted = 1
+		r.uploadSARIF(ctx, owner, repo, ref, pr.GetHead().GetSHA(), event.Command.Mode, inlineComments)
 	}
 
 	// Add checkmark reaction to indicate success
-	if err := r.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "rocket"); err != nil {
-		log.Warn().Err(err).Msg("Failed to add rocket reaction")
+	if event.Comment.ID != 0 {
+		if err := r.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "rocket"); err != nil {
+			log.Warn().Err(err).Msg("Failed to add rocket reaction")
+		}
 	}
 
 	log.Info().
@@ -276,8 +654,8 @@ func (r *Reviewer) ProcessReview(ctx context.Context, event *gh.WebhookEvent) er
 			"files_changed":   len(files),
 			"bugs_found":      bugsFound,
 			"comments_posted": commentsPosted,
-			"review_body":     review,
-			"pr_title":        pr.GetTitle(),
+			"review_body":     secrets.Redact(review, leaked),
+			"pr_title":        secrets.Redact(pr.GetTitle(), leaked),
 			"commit_sha":      pr.GetHead().GetSHA(),
 		})
 		if updateErr != nil {
@@ -288,10 +666,127 @@ func (r *Reviewer) ProcessReview(ctx context.Context, event *gh.WebhookEvent) er
 	return nil
 }
 
+// storeComments records posted findings for the review
+func (r *Reviewer) storeComments(reviewID uint, mode models.ReviewMode, comments []models.ReviewComment) {
+	if r.store == nil || reviewID == 0 {
+		return
+	}
+	for _, comment := range comments {
+		_ = r.store.CreateReviewComment(&database.ReviewComment{
+			ReviewID: reviewID,
+			FilePath: comment.Path,
+			Line:     comment.Line,
+			Severity: comment.Severity,
+			Category: string(mode),
+			Body:     comment.Body,
+
+			Verification:       comment.Verification,
+			VerificationOutput: comment.VerificationOutput,
+			Source:             comment.Source,
+		})
+	}
+}
+
+// SkipGenerated drops files marked as generated from the file list and diff,
+// keeping any the user named explicitly with --files, and returns the dropped files
+func SkipGenerated(files []models.PRFile, diff string, opts models.CommandOptions) ([]models.PRFile, string, []models.PRFile) {
+	var skipped []models.PRFile
+	names := map[string]bool{}
+	kept := make([]models.PRFile, 0, len(files))
+	for _, file := range files {
+		if file.Generated && !(len(opts.Files) > 0 && gh.MatchAnyGlob(opts.Files, file.Filename)) {
+			skipped = append(skipped, file)
+			names[file.Filename] = true
+			continue
+		}
+		kept = append(kept, file)
+	}
+	if len(skipped) == 0 {
+		return kept, diff, nil
+	}
+
+	log.Info().Int("files", len(skipped)).Msg("Skipping generated and vendored files")
+	return kept, gh.FilterDiff(diff, func(filename string) bool { return !names[filename] }), skipped
+}
+
+// generatedRules combines the configured patterns with the linguist attributes
+// in the repository's .gitattributes at ref
+func (r *Reviewer) generatedRules(ctx context.Context, owner, repo, ref string) symbols.Rules {
+	rules := symbols.Rules{Patterns: r.generatedPatterns}
+	content, err := r.githubClient.GetFileContent(ctx, owner, repo, ".gitattributes", ref)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read .gitattributes, using built-in patterns")
+		return rules
+	}
+	if content != "" {
+		rules.Attributes = symbols.ParseGitattributes(content)
+	}
+	return rules
+}
+
+// contentSource reads post-change file contents from the checkout, falling
+// back to fetched context files
+func contentSource(workspace *sandbox.Workspace, contextFiles []models.ContextFile) symbols.ContentSource {
+	return func(filename string) (string, bool) {
+		if workspace != nil {
+			if data, err := os.ReadFile(filepath.Join(workspace.Dir, filepath.FromSlash(filename))); err == nil {
+				return string(data), true
+			}
+		}
+		for _, file := range contextFiles {
+			if file.Path == filename {
+				return file.Content, true
+			}
+		}
+		return "", false
+	}
+}
+
+// MatchesPathFilters applies the --files and --exclude options to a path
+func MatchesPathFilters(opts models.CommandOptions, filename string) bool {
+	if len(opts.Files) > 0 && !gh.MatchAnyGlob(opts.Files, filename) {
+		return false
+	}
+	return !gh.MatchAnyGlob(opts.Exclude, filename)
+}
+
+// shouldUploadSARIF reports whether findings of this mode go to code scanning
+func (r *Reviewer) shouldUploadSARIF(mode models.ReviewMode) bool {
+	for _, m := range r.sarifModes {
+		if m == string(mode) {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadSARIF sends the review's findings to GitHub code scanning. Failures are
+// logged only, since the review itself has already been posted.
+func (r *Reviewer) uploadSARIF(ctx context.Context, owner, repo, ref, headSHA string, mode models.ReviewMode, comments []models.ReviewComment) {
+	encoded, err := sarif.FromComments(comments, sarif.Options{
+		Mode:          mode,
+		RepositoryURI: fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+		CommitSHA:     headSHA,
+	}).Encode()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to encode SARIF")
+		return
+	}
+
+	if _, err := r.githubClient.UploadSARIF(ctx, owner, repo, ref, headSHA, encoded); err != nil {
+		log.Warn().Err(err).Msg("Failed to upload SARIF to code scanning")
+	}
+}
+
 // postError posts an error message as a comment and adds a confused reaction
 func (r *Reviewer) postError(ctx context.Context, owner, repo string, prNumber int, commentID int64, message string, err error) error {
 	log.Error().Err(err).Str("message", message).Msg("Review processing failed")
 
+	// Reviews of pushed commits have no request comment, issue or PR to report to
+	if prNumber == 0 {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
 	// Add confused reaction
 	if reactionErr := r.githubClient.AddReaction(ctx, owner, repo, commentID, "confused"); reactionErr != nil {
 		log.Warn().Err(reactionErr).Msg("Failed to add confused reaction")
@@ -308,36 +803,16 @@ func (r *Reviewer) postError(ctx context.Context, owner, repo string, prNumber i
 
 // GetModeDescription returns a human-readable description of the review mode
 func GetModeDescription(mode models.ReviewMode) string {
-	switch mode {
-	case models.ModeHunt:
-		return "Bug Hunt"
-	case models.ModeSecurity:
-		return "Security Audit"
-	case models.ModePerformance:
-		return "Performance Analysis"
-	case models.ModeAnalyze:
-		return "Deep Analysis"
-	case models.ModeReview:
-		return "Code Review"
-	default:
-		return "Code Review"
+	if m, ok := modes.Get(mode); ok {
+		return m.Description
 	}
+	return "Code Review"
 }
 
 // GetModeEmoji returns an emoji for the review mode
 func GetModeEmoji(mode models.ReviewMode) string {
-	switch mode {
-	case models.ModeHunt:
-		return "🐛"
-	case models.ModeSecurity:
-		return "🔒"
-	case models.ModePerformance:
-		return "⚡"
-	case models.ModeAnalyze:
-		return "🔬"
-	case models.ModeReview:
-		return "📝"
-	default:
-		return "📝"
+	if m, ok := modes.Get(mode); ok {
+		return m.Emoji
 	}
+	return "📝"
 }
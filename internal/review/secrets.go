@@ -0,0 +1,108 @@
+package review
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CREVIOS/revo/internal/sandbox"
+	"github.com/CREVIOS/revo/internal/secrets"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// secretSource tags the findings of the secret scan
+const secretSource = "secret scan"
+
+// SetSecretScan turns the secret scan and redaction of diffs before prompting on or off
+func (r *Reviewer) SetSecretScan(enabled bool) {
+	r.secretScan = enabled
+}
+
+// SecretComments turns the secrets found in a diff into high-severity findings.
+// The comments name the kind of secret but never include it.
+func SecretComments(findings []secrets.Finding) []models.ReviewComment {
+	var comments []models.ReviewComment
+	for _, f := range secrets.Reported(findings) {
+		comments = append(comments, models.ReviewComment{
+			Path:     f.Path,
+			Line:     f.Line,
+			Severity: "error",
+			Source:   secretSource,
+			Body: fmt.Sprintf("🔑 **%s committed** (`%s`)\n\n"+
+				"This line adds what looks like a credential; it was redacted before the change was sent for review. "+
+				"Treat it as leaked: revoke and rotate it, since removing it in a later commit leaves it in the git history, "+
+				"and load it from the environment or a secret manager instead.", f.Description, f.Rule),
+		})
+	}
+	return comments
+}
+
+// FormatSecretFindings renders the secret findings as a section of a comment,
+// so they are posted when a run delivers no inline comments
+func FormatSecretFindings(findings []secrets.Finding) string {
+	comments := SecretComments(findings)
+	if len(comments) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### 🔑 Secrets\n\n")
+	sb.WriteString("These lines add what look like credentials. They were redacted before the change was sent for review. " +
+		"Revoke and rotate them, since removing them in a later commit leaves them in the git history.\n\n")
+	for _, c := range comments {
+		title, _, _ := strings.Cut(c.Body, "\n")
+		sb.WriteString(fmt.Sprintf("- 🔴 `%s:%d` %s\n", c.Path, c.Line, title))
+	}
+	return sb.String()
+}
+
+// redactRequest removes the secrets from everything a review request sends to Claude
+func redactRequest(request *models.ReviewRequest, findings []secrets.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	request.Diff = secrets.Redact(request.Diff, findings)
+	request.PRTitle = secrets.Redact(request.PRTitle, findings)
+	request.PRBody = secrets.Redact(request.PRBody, findings)
+	for i := range request.Files {
+		request.Files[i].Patch = secrets.Redact(request.Files[i].Patch, findings)
+	}
+	for i := range request.ContextFiles {
+		request.ContextFiles[i].Content = secrets.Redact(request.ContextFiles[i].Content, findings)
+	}
+	for i := range request.Analysis {
+		request.Analysis[i].Message = secrets.Redact(request.Analysis[i].Message, findings)
+	}
+}
+
+// redactWorkspace rewrites the files of the checkout that hold secrets, so the
+// CLI cannot read them back
+func redactWorkspace(ws *sandbox.Workspace, findings []secrets.Finding) {
+	if ws == nil {
+		return
+	}
+	byPath := map[string][]secrets.Finding{}
+	for _, f := range findings {
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	for p, found := range byPath {
+		file := filepath.Join(ws.Dir, filepath.FromSlash(p))
+		if !strings.HasPrefix(file, filepath.Clean(ws.Dir)+string(filepath.Separator)) {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			log.Warn().Err(err).Str("path", p).Msg("Failed to read file to redact")
+			continue
+		}
+		if err := os.WriteFile(file, []byte(secrets.Redact(string(content), found)), info.Mode()); err != nil {
+			log.Warn().Err(err).Str("path", p).Msg("Failed to redact file in checkout")
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package review
+
+import (
+	"time"
+
+	"github.com/CREVIOS/revo/internal/analyzers"
+	"github.com/CREVIOS/revo/internal/claude"
+	contextaware "github.com/CREVIOS/revo/internal/context"
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/internal/deps"
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/prompts"
+	"github.com/CREVIOS/revo/internal/sandbox"
+	"github.com/CREVIOS/revo/internal/verify"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// NewFromConfig creates a reviewer with the features the configuration turns
+// on. The server and the worker both build their reviewer with it.
+func NewFromConfig(cfg *models.Config, githubClient *gh.Client, claudeClient *claude.Client, store *database.Store, contextAnalyzer ContextAnalyzer, rateLimiter RateLimiter) *Reviewer {
+	r := NewReviewer(githubClient, claudeClient, cfg.MaxDiffSize)
+	r.SetContextAnalyzer(contextAnalyzer)
+	r.SetRateLimiter(rateLimiter)
+	r.SetStore(store)
+	r.SetPromptSelector(prompts.NewSelector(store))
+	if cfg.ContextFilesEnabled {
+		r.SetFileContext(contextaware.NewFileContextGatherer(githubClient, contextaware.FileContextConfig{
+			MaxTokens:      cfg.ContextMaxTokens,
+			MaxFileSize:    cfg.ContextMaxFileSize,
+			IncludeImports: cfg.ContextIncludeImports,
+		}))
+	}
+	if cfg.SandboxEnabled {
+		r.SetSandbox(sandbox.NewManager(githubClient, sandbox.Config{
+			BaseDir:      cfg.SandboxDir,
+			CloneTimeout: time.Duration(cfg.SandboxCloneTimeoutSec) * time.Second,
+			MaxSizeMB:    cfg.SandboxMaxSizeMB,
+		}))
+	}
+	if cfg.VerifyEnabled {
+		if !cfg.SandboxEnabled {
+			log.Warn().Msg("VERIFY_ENABLED needs SANDBOX_ENABLED, bug verification is off")
+		} else {
+			r.SetVerifier(verify.NewVerifier(claudeClient, verify.Config{
+				Mode:        cfg.VerifyMode,
+				MinSeverity: cfg.VerifyMinSeverity,
+				MaxFindings: cfg.VerifyMaxFindings,
+				Runner: verify.RunnerConfig{
+					DockerPath:      cfg.DockerPath,
+					Images:          verify.ParseImages(cfg.VerifyImages),
+					Timeout:         time.Duration(cfg.VerifyTimeoutSec) * time.Second,
+					MemoryMB:        cfg.VerifyMemoryMB,
+					CPUs:            cfg.VerifyCPUs,
+					Network:         cfg.VerifyNetwork,
+					GoModCache:      cfg.VerifyGoModCache,
+					DownloadNetwork: cfg.VerifyDownloadNetwork,
+				},
+			}))
+		}
+	}
+	if cfg.AnalyzersEnabled {
+		if !cfg.SandboxEnabled {
+			log.Warn().Msg("ANALYZERS_ENABLED needs SANDBOX_ENABLED, static analysis is off")
+		} else {
+			r.SetAnalyzer(analyzers.NewRunner(analyzers.Config{
+				Enabled:     cfg.Analyzers,
+				Commands:    cfg.AnalyzerCommands,
+				Timeout:     time.Duration(cfg.AnalyzerTimeoutSec) * time.Second,
+				MaxFindings: cfg.AnalyzerMaxFindings,
+			}))
+		}
+	}
+	if cfg.SARIFUploadEnabled {
+		r.SetSARIFUpload(cfg.SARIFUploadModes)
+	}
+	r.SetGeneratedFiles(cfg.SkipGeneratedFiles, cfg.GeneratedFilePatterns)
+	r.SetSummaryTarget(cfg.SummaryTarget)
+	r.SetStickyComments(cfg.StickyComments)
+	r.SetTestsDelivery(cfg.TestsDelivery)
+	r.SetCompareOutput(cfg.CompareReviewOutput)
+	r.SetAuditMaxFiles(cfg.AuditMaxFiles)
+	r.SetSecretScan(cfg.SecretScan)
+	r.SetMigrationPaths(cfg.MigrationPaths)
+	if cfg.DependencyReview {
+		checker, err := deps.NewChecker(deps.Config{
+			OSVDir:           cfg.OSVDir,
+			LicenseFile:      cfg.LicenseFile,
+			LicenseAllowlist: cfg.LicenseAllowlist,
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to load dependency databases, dependency review is off")
+		} else {
+			r.SetDependencyChecker(checker)
+		}
+	}
+	return r
+}
@@ -0,0 +1,177 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Hidden markers that let later runs find and rewrite the bot's own posts
+const (
+	historyMarker    = "<!-- techy:history -->"
+	historyEndMarker = "<!-- techy:history:end -->"
+	entryMarker      = "<!-- techy:entry -->"
+	supersededMarker = "<!-- techy:superseded -->"
+)
+
+// maxHistory bounds the past runs kept in a sticky comment
+const maxHistory = 10
+
+// maxCommentSize stays under GitHub's 65536 character limit for comment bodies
+const maxCommentSize = 60000
+
+var runPattern = regexp.MustCompile(`<!-- techy:run sha=(\w*) at=(\S*) -->\n?`)
+
+// StickyMarker identifies the sticky comment and review bodies of a mode
+func StickyMarker(mode models.ReviewMode) string {
+	return fmt.Sprintf("<!-- techy:review:%s -->", mode)
+}
+
+// BuildStickyComment renders the latest result above a collapsible history
+// of past runs, taken from the previous body of the comment
+func BuildStickyComment(mode models.ReviewMode, previous, latest, sha string, at time.Time) string {
+	entries := historyEntries(mode, previous)
+	if len(entries) > maxHistory {
+		entries = entries[:maxHistory]
+	}
+
+	for {
+		body := renderSticky(mode, latest, sha, at, entries)
+		if len(body) <= maxCommentSize || len(entries) == 0 {
+			return TruncateForGitHub(body, maxCommentSize)
+		}
+		entries = entries[:len(entries)-1]
+	}
+}
+
+// renderSticky lays out the marker, run metadata, latest result and history
+func renderSticky(mode models.ReviewMode, latest, sha string, at time.Time, entries []string) string {
+	var sb strings.Builder
+	sb.WriteString(StickyMarker(mode))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("<!-- techy:run sha=%s at=%s -->\n", sha, at.UTC().Format(time.RFC3339)))
+	sb.WriteString(strings.TrimSpace(latest))
+
+	if len(entries) > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(historyMarker)
+		sb.WriteString(fmt.Sprintf("\n<details>\n<summary>📜 Previous runs (%d)</summary>\n\n", len(entries)))
+		sb.WriteString(strings.Join(entries, "\n\n"))
+		sb.WriteString("\n")
+		sb.WriteString(historyEndMarker)
+		sb.WriteString("\n</details>")
+	}
+	return sb.String()
+}
+
+// historyEntries turns the previous latest result into the newest history
+// entry, followed by the entries already in the history
+func historyEntries(mode models.ReviewMode, previous string) []string {
+	if previous == "" {
+		return nil
+	}
+
+	current, history, _ := strings.Cut(previous, historyMarker)
+	sha, at := "", ""
+	if match := runPattern.FindStringSubmatch(current); match != nil {
+		sha, at = match[1], match[2]
+	}
+	current = runPattern.ReplaceAllString(strings.Replace(current, StickyMarker(mode), "", 1), "")
+
+	var entries []string
+	if content := strings.TrimSpace(current); content != "" {
+		entries = append(entries, entryMarker+"\n"+CollapsibleSection(runLabel(sha, at), content))
+	}
+
+	history, _, _ = strings.Cut(history, historyEndMarker)
+	parts := strings.Split(history, entryMarker)
+	for _, part := range parts[1:] {
+		if part = strings.TrimSpace(part); part != "" {
+			entries = append(entries, entryMarker+"\n"+part)
+		}
+	}
+	return entries
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// runLabel describes a past run by commit and time
+func runLabel(sha, at string) string {
+	sha = shortSHA(sha)
+	label := "Earlier run"
+	if sha != "" {
+		label = fmt.Sprintf("Run on <code>%s</code>", sha)
+	}
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		label += " at " + t.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	return label
+}
+
+// postComment posts a review result that has no inline comments. With sticky
+// comments on, it replaces the mode's previous comment and keeps it as history.
+func (r *Reviewer) postComment(ctx context.Context, owner, repo string, prNumber int, mode models.ReviewMode, sha, body string) error {
+	if !r.stickyComments {
+		return r.githubClient.CreateComment(ctx, owner, repo, prNumber, body)
+	}
+	return r.updateSticky(ctx, owner, repo, prNumber, mode, sha, body, true)
+}
+
+// updateSticky rewrites the mode's sticky comment, creating it if asked to
+func (r *Reviewer) updateSticky(ctx context.Context, owner, repo string, prNumber int, mode models.ReviewMode, sha, latest string, create bool) error {
+	existing, err := r.githubClient.FindComment(ctx, owner, repo, prNumber, StickyMarker(mode))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		if !create {
+			return nil
+		}
+		return r.githubClient.CreateComment(ctx, owner, repo, prNumber, BuildStickyComment(mode, "", latest, sha, time.Now()))
+	}
+
+	body := BuildStickyComment(mode, existing.GetBody(), latest, sha, time.Now())
+	return r.githubClient.EditComment(ctx, owner, repo, existing.GetID(), body)
+}
+
+// supersedeReviews collapses the bodies of the bot's earlier reviews in a
+// mode, leaving the most recent one as it is when keepLatest is set
+func (r *Reviewer) supersedeReviews(ctx context.Context, owner, repo string, prNumber int, mode models.ReviewMode, keepLatest bool) {
+	reviews, err := r.githubClient.ListReviews(ctx, owner, repo, prNumber)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list reviews to supersede")
+		return
+	}
+
+	marker := StickyMarker(mode)
+	var ours []int
+	for i, rv := range reviews {
+		if rv.GetUser().GetType() == "Bot" && strings.Contains(rv.GetBody(), marker) && !strings.Contains(rv.GetBody(), supersededMarker) {
+			ours = append(ours, i)
+		}
+	}
+	if keepLatest && len(ours) > 0 {
+		ours = ours[:len(ours)-1]
+	}
+
+	for _, i := range ours {
+		rv := reviews[i]
+		previous := strings.TrimSpace(strings.Replace(rv.GetBody(), marker, "", 1))
+		body := fmt.Sprintf("%s\n%s\n_Superseded by a newer TechyBot %s run._\n\n%s",
+			supersededMarker, marker, GetModeDescription(mode), CollapsibleSection("Previous results", previous))
+		if err := r.githubClient.UpdateReviewBody(ctx, owner, repo, prNumber, rv.GetID(), TruncateForGitHub(body, maxCommentSize)); err != nil {
+			log.Warn().Err(err).Int64("review_id", rv.GetID()).Msg("Failed to collapse superseded review")
+		}
+	}
+}
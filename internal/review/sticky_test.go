@@ -0,0 +1,144 @@
+package review
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+func TestBuildStickyComment(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	first := BuildStickyComment(models.ModeHunt, "", "first result", "aaaaaaaaaa", at)
+	second := BuildStickyComment(models.ModeHunt, first, "second result", "bbbbbbbbbb", at.Add(time.Hour))
+	third := BuildStickyComment(models.ModeHunt, second, "third result", "cccccccccc", at.Add(2*time.Hour))
+
+	tests := []struct {
+		name       string
+		body       string
+		contains   []string
+		excludes   []string
+		wantPrefix string
+		entries    int
+	}{
+		{
+			name:       "first run has no history",
+			body:       first,
+			wantPrefix: StickyMarker(models.ModeHunt) + "\n<!-- techy:run sha=aaaaaaaaaa at=2025-03-01T12:30:00Z -->\nfirst result",
+			excludes:   []string{historyMarker, "Previous runs"},
+		},
+		{
+			name:     "second run keeps the first as history",
+			body:     second,
+			contains: []string{"second result", "Previous runs (1)", "Run on <code>aaaaaaa</code> at 2025-03-01 12:30 UTC", "first result"},
+			entries:  1,
+		},
+		{
+			name:     "third run lists history newest first",
+			body:     third,
+			contains: []string{"third result", "Previous runs (2)"},
+			entries:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantPrefix != "" && !strings.HasPrefix(tt.body, tt.wantPrefix) {
+				t.Errorf("body = %q, want prefix %q", tt.body, tt.wantPrefix)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(tt.body, s) {
+					t.Errorf("body does not contain %q:\n%s", s, tt.body)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(tt.body, s) {
+					t.Errorf("body contains %q:\n%s", s, tt.body)
+				}
+			}
+			if got := strings.Count(tt.body, entryMarker); got != tt.entries {
+				t.Errorf("entries = %d, want %d", got, tt.entries)
+			}
+			if got := strings.Count(tt.body, StickyMarker(models.ModeHunt)); got != 1 {
+				t.Errorf("sticky markers = %d, want 1", got)
+			}
+		})
+	}
+
+	if i, j := strings.Index(third, "bbbbbbb"), strings.Index(third, "aaaaaaa"); i < 0 || j < 0 || i > j {
+		t.Errorf("history is not newest first:\n%s", third)
+	}
+}
+
+func TestBuildStickyCommentLimits(t *testing.T) {
+	at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	body := ""
+	for i := 0; i < maxHistory+5; i++ {
+		body = BuildStickyComment(models.ModeReview, body, "result", "abc", at)
+	}
+	if got := strings.Count(body, entryMarker); got != maxHistory {
+		t.Errorf("entries = %d, want %d", got, maxHistory)
+	}
+
+	large := strings.Repeat("line of review output\n", maxCommentSize/3/22)
+	body = ""
+	for i := 0; i < 4; i++ {
+		body = BuildStickyComment(models.ModeReview, body, large, "abc", at)
+	}
+	if len(body) > maxCommentSize {
+		t.Errorf("len(body) = %d, want at most %d", len(body), maxCommentSize)
+	}
+	if got := strings.Count(body, entryMarker); got != 1 {
+		t.Errorf("entries = %d, want the oldest dropped to 1", got)
+	}
+	if !strings.Contains(body, historyEndMarker) {
+		t.Error("history was truncated instead of dropping whole entries")
+	}
+}
+
+func TestHistoryEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		want     []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "unknown sha and time",
+			previous: StickyMarker(models.ModeReview) + "\nold result",
+			want:     []string{entryMarker + "\n" + CollapsibleSection("Earlier run", "old result")},
+		},
+		{
+			name:     "marker only",
+			previous: StickyMarker(models.ModeReview) + "\n<!-- techy:run sha=abc at=bad -->\n",
+		},
+		{
+			name: "existing history follows the latest",
+			previous: StickyMarker(models.ModeReview) + "\n<!-- techy:run sha=0123456789 at=bad -->\nnew\n\n" +
+				historyMarker + "\n<details>\n<summary>📜 Previous runs (1)</summary>\n\n" +
+				entryMarker + "\nolder\n" + historyEndMarker + "\n</details>",
+			want: []string{
+				entryMarker + "\n" + CollapsibleSection("Run on <code>0123456</code>", "new"),
+				entryMarker + "\nolder",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := historyEntries(models.ModeReview, tt.previous)
+			if len(got) != len(tt.want) {
+				t.Fatalf("historyEntries() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("entry %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/google/go-github/v60/github"
+	"github.com/rs/zerolog/log"
+)
+
+// Summary targets
+const (
+	SummaryTargetComment = "comment" // a sticky comment updated in place
+	SummaryTargetBody    = "body"    // the PR description, when it is empty or already holds a summary
+)
+
+// Markers delimit generated summaries so re-runs replace them
+const (
+	summaryCommentMarker = "<!-- techy:summary -->"
+	summaryStartMarker   = "<!-- techy:summary:start -->"
+	summaryEndMarker     = "<!-- techy:summary:end -->"
+)
+
+// FormatSummary adds the file walkthrough table to Claude's PR summary
+func FormatSummary(summary string, files, skipped []models.PRFile) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %s TechyBot %s\n\n", GetModeEmoji(models.ModeSummarize), GetModeDescription(models.ModeSummarize)))
+	sb.WriteString(strings.TrimSpace(summary))
+	sb.WriteString("\n\n### File Walkthrough\n\n")
+	sb.WriteString(FormatFileSummary(files))
+	if note := FormatSkippedFiles(skipped); note != "" {
+		sb.WriteString("\n")
+		sb.WriteString(note)
+	}
+	sb.WriteString("\n\n---\n<sub>🤖 Generated by TechyBot | Re-run with `@techy summarize` to update</sub>")
+	return sb.String()
+}
+
+// StripSummary removes a generated summary from a PR description
+func StripSummary(body string) string {
+	start := strings.Index(body, summaryStartMarker)
+	end := strings.Index(body, summaryEndMarker)
+	if start < 0 || end < start {
+		return body
+	}
+	return strings.TrimSpace(body[:start] + body[end+len(summaryEndMarker):])
+}
+
+// postSummary writes the summary into the PR body when configured and the
+// body has no text of its own, and otherwise into the sticky summary comment
+func (r *Reviewer) postSummary(ctx context.Context, owner, repo string, pr *github.PullRequest, summary string) error {
+	if r.summaryTarget == SummaryTargetBody && strings.TrimSpace(StripSummary(pr.GetBody())) == "" {
+		body := summaryStartMarker + "\n" + summary + "\n" + summaryEndMarker
+		err := r.githubClient.UpdatePullRequestBody(ctx, owner, repo, pr.GetNumber(), body)
+		if err == nil {
+			return nil
+		}
+		log.Warn().Err(err).Msg("Failed to update PR description, posting the summary as a comment")
+	}
+
+	return r.githubClient.UpsertComment(ctx, owner, repo, pr.GetNumber(), summaryCommentMarker, summaryCommentMarker+"\n"+summary)
+}
@@ -0,0 +1,171 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/google/go-github/v60/github"
+	"github.com/rs/zerolog/log"
+)
+
+// Where compare-range reviews are posted
+const (
+	CompareOutputIssue = "issue" // an issue with the summary and findings
+	CompareOutputCheck = "check" // a check run on the head commit with findings as annotations
+)
+
+// SetCompareOutput chooses where reviews of a compare range are posted: an issue or a check run
+func (r *Reviewer) SetCompareOutput(output string) {
+	r.compareOutput = output
+}
+
+// fetchTarget loads a commit or compare range the way a pull request is loaded.
+// The returned pull request is a stand-in carrying the title, body and head.
+func (r *Reviewer) fetchTarget(ctx context.Context, owner, repo string, target *models.ReviewTarget) (*github.PullRequest, string, []*github.CommitFile, error) {
+	head := &github.PullRequestBranch{SHA: github.String(target.Head), Ref: github.String(target.Ref)}
+
+	switch target.Kind {
+	case models.TargetCommit:
+		commit, err := r.githubClient.GetCommit(ctx, owner, repo, target.Head)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		diff, err := r.githubClient.GetCommitDiff(ctx, owner, repo, target.Head)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		title, body, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+		pr := &github.PullRequest{Title: github.String(title), Body: github.String(strings.TrimSpace(body)), Head: head}
+		if len(commit.Parents) > 0 {
+			pr.Base = &github.PullRequestBranch{SHA: commit.Parents[0].SHA}
+		}
+		return pr, diff, commit.Files, nil
+
+	case models.TargetCompare:
+		comparison, err := r.githubClient.CompareCommits(ctx, owner, repo, target.Base, target.Head)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		diff, err := r.githubClient.GetCompareDiff(ctx, owner, repo, target.Base, target.Head)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		var body strings.Builder
+		body.WriteString("Commits:\n")
+		for _, commit := range comparison.Commits {
+			message, _, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+			body.WriteString(fmt.Sprintf("- %s %s\n", shortSHA(commit.GetSHA()), message))
+		}
+		title := fmt.Sprintf("%d commit(s) pushed to %s", comparison.GetTotalCommits(), target.Ref)
+		base := &github.PullRequestBranch{SHA: github.String(target.Base)}
+		pr := &github.PullRequest{Title: github.String(title), Body: github.String(body.String()), Head: head, Base: base}
+		return pr, diff, comparison.Files, nil
+	}
+
+	return nil, "", nil, fmt.Errorf("unsupported review target %q", target.Kind)
+}
+
+// postTargetReview posts a review of a commit as commit comments, and a review
+// of a compare range as an issue or check run. It returns the comments posted.
+func (r *Reviewer) postTargetReview(ctx context.Context, owner, repo string, mode models.ReviewMode, target *models.ReviewTarget, files []models.PRFile, summary string, comments []models.ReviewComment) (int, error) {
+	if target.Kind == models.TargetCommit {
+		patches := map[string]string{}
+		for _, file := range files {
+			patches[file.Filename] = file.Patch
+		}
+
+		posted := 0
+		var unplaced []models.ReviewComment
+		for _, comment := range comments {
+			position := gh.DiffPosition(patches[comment.Path], comment.Line)
+			if position == 0 {
+				unplaced = append(unplaced, comment)
+				continue
+			}
+			body := FormatInlineComment(comment.Body, comment.Severity)
+			if err := r.githubClient.CreateCommitComment(ctx, owner, repo, target.Head, comment.Path, position, body); err != nil {
+				log.Warn().Err(err).Str("path", comment.Path).Int("line", comment.Line).Msg("Failed to post commit comment")
+				unplaced = append(unplaced, comment)
+				continue
+			}
+			posted++
+		}
+
+		if findings := formatTargetFindings(owner, repo, target.Head, unplaced); findings != "" {
+			summary = strings.TrimSpace(summary) + "\n\n" + findings
+		}
+		body := TruncateForGitHub(FormatReview(summary, mode), maxCommentSize)
+		if err := r.githubClient.CreateCommitComment(ctx, owner, repo, target.Head, "", 0, body); err != nil {
+			return posted, err
+		}
+		return posted + 1, nil
+	}
+
+	title := fmt.Sprintf("%s %s of %s...%s on %s", GetModeEmoji(mode), GetModeDescription(mode),
+		shortSHA(target.Base), shortSHA(target.Head), target.Ref)
+
+	if r.compareOutput == CompareOutputCheck {
+		annotations := make([]*github.CheckRunAnnotation, 0, len(comments))
+		for _, comment := range comments {
+			annotations = append(annotations, &github.CheckRunAnnotation{
+				Path:            github.String(comment.Path),
+				StartLine:       github.Int(comment.Line),
+				EndLine:         github.Int(comment.Line),
+				AnnotationLevel: github.String(annotationLevel(comment.Severity)),
+				Message:         github.String(comment.Body),
+			})
+		}
+		conclusion := "success"
+		if len(comments) > 0 {
+			conclusion = "neutral"
+		}
+		name := "TechyBot " + GetModeDescription(mode)
+		// Check run summaries are limited to 65535 characters
+		if err := r.githubClient.CreateCheckRun(ctx, owner, repo, name, target.Head, conclusion, title,
+			TruncateForGitHub(summary, maxCommentSize), annotations); err != nil {
+			return 0, err
+		}
+		return len(comments), nil
+	}
+
+	if findings := formatTargetFindings(owner, repo, target.Head, comments); findings != "" {
+		summary = strings.TrimSpace(summary) + "\n\n" + findings
+	}
+	body := TruncateForGitHub(FormatReview(summary, mode), maxCommentSize)
+	number, err := r.githubClient.CreateIssue(ctx, owner, repo, title, body)
+	if err != nil {
+		return 0, err
+	}
+	log.Info().Int("issue", number).Msg("Opened issue with compare review")
+	return 1, nil
+}
+
+// formatTargetFindings lists findings with links to their lines at sha, for
+// reviews whose findings cannot be posted inline
+func formatTargetFindings(owner, repo, sha string, comments []models.ReviewComment) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### Findings\n\n")
+	for _, comment := range comments {
+		sb.WriteString(fmt.Sprintf("#### [`%s:%d`](https://github.com/%s/%s/blob/%s/%s#L%d)\n\n%s\n\n",
+			comment.Path, comment.Line, owner, repo, sha, comment.Path, comment.Line,
+			strings.TrimSpace(FormatInlineComment(comment.Body, comment.Severity))))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// annotationLevel maps a finding's severity to a check run annotation level
+func annotationLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "failure"
+	case "warning":
+		return "warning"
+	}
+	return "notice"
+}
@@ -0,0 +1,313 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/sandbox"
+	"github.com/CREVIOS/revo/internal/symbols"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/google/go-github/v60/github"
+	"github.com/rs/zerolog/log"
+)
+
+// Test delivery targets
+const (
+	TestsDeliveryComment = "comment" // code blocks in a comment on the PR
+	TestsDeliveryPR      = "pr"      // a follow-up PR into the PR's head branch, or its base branch for forks
+)
+
+// maxTestTargets bounds the functions sent for test generation in one run
+const maxTestTargets = 20
+
+// maxTestFiles bounds the existing test files read to find untested functions
+const maxTestFiles = 50
+
+var (
+	testFilePattern = regexp.MustCompile("(?ms)^TEST_FILE:\\s*`?([^`\\s]+)`?\\s*\\n```[\\w+-]*\\n(.*?)\\n```")
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+)
+
+// SetTestsDelivery chooses how `tests` delivers generated tests: a comment or a follow-up PR
+func (r *Reviewer) SetTestsDelivery(delivery string) {
+	r.testsDelivery = delivery
+}
+
+// IsTestFile reports whether path is a Go or Python test file
+func IsTestFile(p string) bool {
+	base := path.Base(p)
+	switch path.Ext(base) {
+	case ".go":
+		return strings.HasSuffix(base, "_test.go")
+	case ".py":
+		return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py")
+	}
+	return false
+}
+
+// testable reports whether a changed file is source code test generation supports
+func testable(file models.PRFile) bool {
+	if file.Status == "removed" || file.Generated || IsTestFile(file.Filename) {
+		return false
+	}
+	ext := path.Ext(file.Filename)
+	return ext == ".go" || ext == ".py"
+}
+
+// testDirs lists the directories whose test files may cover a source file
+func testDirs(filename string) []string {
+	dir := path.Dir(filename)
+	if path.Ext(filename) != ".py" {
+		return []string{dir}
+	}
+	return []string{dir, path.Join(dir, "tests"), "tests"}
+}
+
+// UntestedFunctions returns the functions and methods changed in files that no
+// test file in the same package mentions by name. tests maps test file paths
+// to their contents.
+func UntestedFunctions(files []models.PRFile, tests map[string]string) []models.TestTarget {
+	byDir := map[string][]string{}
+	for p, content := range tests {
+		byDir[path.Dir(p)] = append(byDir[path.Dir(p)], content)
+	}
+
+	seen := map[string]bool{}
+	var targets []models.TestTarget
+	for _, file := range files {
+		if !testable(file) {
+			continue
+		}
+		for _, hunk := range file.Hunks {
+			if hunk.Symbol == "" || (hunk.SymbolKind != symbols.KindFunc && hunk.SymbolKind != symbols.KindMethod) {
+				continue
+			}
+			key := file.Filename + ":" + hunk.Symbol
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			name := hunk.Symbol[strings.LastIndex(hunk.Symbol, ".")+1:]
+			if name == "init" || name == "main" || strings.HasPrefix(name, "__") {
+				continue
+			}
+			if mentioned(name, file.Filename, byDir) {
+				continue
+			}
+			targets = append(targets, models.TestTarget{
+				Path: file.Filename,
+				Name: hunk.Symbol,
+				Kind: hunk.SymbolKind,
+				Line: hunk.FirstChange,
+			})
+			if len(targets) == maxTestTargets {
+				return targets
+			}
+		}
+	}
+	return targets
+}
+
+// mentioned reports whether a test next to filename refers to name as a whole word
+func mentioned(name, filename string, byDir map[string][]string) bool {
+	for _, dir := range testDirs(filename) {
+		for _, content := range byDir[dir] {
+			if containsWord(content, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsWord reports whether word occurs in s between non-word characters
+func containsWord(s, word string) bool {
+	for i := 0; word != ""; {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isWordByte(s[start-1])) && (end == len(s) || !isWordByte(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+// isWordByte reports whether b is a letter, digit or underscore, as \w matches
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// existingTests reads the test files in the directories of the changed files,
+// from the checkout when there is one and otherwise through the API
+func (r *Reviewer) existingTests(ctx context.Context, owner, repo, ref string, workspace *sandbox.Workspace, files []models.PRFile) map[string]string {
+	dirs := map[string]bool{}
+	for _, file := range files {
+		if testable(file) {
+			for _, dir := range testDirs(file.Filename) {
+				dirs[dir] = true
+			}
+		}
+	}
+	ordered := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		ordered = append(ordered, dir)
+	}
+	sort.Strings(ordered)
+
+	tests := map[string]string{}
+	for _, dir := range ordered {
+		var paths []string
+		if workspace != nil {
+			entries, err := os.ReadDir(filepath.Join(workspace.Dir, filepath.FromSlash(dir)))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					paths = append(paths, path.Join(dir, entry.Name()))
+				}
+			}
+		} else {
+			listed, err := r.githubClient.ListDirectory(ctx, owner, repo, dir, ref)
+			if err != nil {
+				log.Warn().Err(err).Str("dir", dir).Msg("Failed to list test directory")
+				continue
+			}
+			paths = listed
+		}
+
+		for _, p := range paths {
+			if !IsTestFile(p) {
+				continue
+			}
+			if len(tests) == maxTestFiles {
+				return tests
+			}
+			if workspace != nil {
+				if data, err := os.ReadFile(filepath.Join(workspace.Dir, filepath.FromSlash(p))); err == nil {
+					tests[p] = string(data)
+				}
+				continue
+			}
+			content, err := r.githubClient.GetFileContent(ctx, owner, repo, p, ref)
+			if err != nil {
+				log.Warn().Err(err).Str("path", p).Msg("Failed to fetch test file")
+				continue
+			}
+			tests[p] = content
+		}
+	}
+	return tests
+}
+
+// ParseGeneratedTests extracts the TEST_FILE blocks from Claude's response. Files
+// with unsafe paths, paths that are not test files and existing files are dropped.
+// The text outside the blocks is returned as notes.
+func ParseGeneratedTests(response string, existing map[string]string) ([]gh.FileChange, string) {
+	var files []gh.FileChange
+	seen := map[string]bool{}
+	for _, match := range testFilePattern.FindAllStringSubmatch(response, -1) {
+		p := strings.TrimPrefix(match[1], "./")
+		if p != path.Clean(p) || path.IsAbs(p) || strings.HasPrefix(p, "../") || strings.HasPrefix(p, ".github/") {
+			log.Warn().Str("path", match[1]).Msg("Dropping generated test with unsafe path")
+			continue
+		}
+		if !IsTestFile(p) || seen[p] {
+			continue
+		}
+		if _, ok := existing[p]; ok {
+			log.Warn().Str("path", p).Msg("Dropping generated test that would overwrite an existing file")
+			continue
+		}
+		seen[p] = true
+		files = append(files, gh.FileChange{Path: p, Content: match[2] + "\n"})
+	}
+
+	notes := strings.TrimSpace(blankLines.ReplaceAllString(testFilePattern.ReplaceAllString(response, ""), "\n\n"))
+	return files, notes
+}
+
+// FormatGeneratedTests lays out generated test files as collapsible code blocks
+func FormatGeneratedTests(files []gh.FileChange, notes string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Generated %d test file(s). Suggestion blocks can only change lines in the diff, so add these as new files:\n\n", len(files)))
+	for _, file := range files {
+		lang := "go"
+		if path.Ext(file.Path) == ".py" {
+			lang = "python"
+		}
+		sb.WriteString(CollapsibleSection(fmt.Sprintf("<code>%s</code>", file.Path), fmt.Sprintf("```%s\n%s```", lang, file.Content)))
+		sb.WriteString("\n\n")
+	}
+	if notes != "" {
+		sb.WriteString(notes)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// deliverTests posts generated tests as a comment or opens a follow-up PR with
+// them, and returns what was posted and the number of test files. The footer
+// is added to whichever comment is posted on the PR.
+func (r *Reviewer) deliverTests(ctx context.Context, owner, repo string, pr *github.PullRequest, response string, existing map[string]string, footer string) (string, int, error) {
+	mode := models.ModeTests
+	headSHA := pr.GetHead().GetSHA()
+	files, notes := ParseGeneratedTests(response, existing)
+
+	// Only the changed files' directories were read; never replace a file elsewhere
+	kept := files[:0]
+	for _, file := range files {
+		if content, err := r.githubClient.GetFileContent(ctx, owner, repo, file.Path, headSHA); err == nil && content == "" {
+			kept = append(kept, file)
+		}
+	}
+	files = kept
+	if len(files) == 0 {
+		body := FormatReview(appendSection("No tests could be generated for the changed functions.\n\n"+notes, footer), mode)
+		return body, 0, r.postComment(ctx, owner, repo, pr.GetNumber(), mode, headSHA, body)
+	}
+
+	if r.testsDelivery == TestsDeliveryPR {
+		branch := fmt.Sprintf("techy/tests-%d-%s", pr.GetNumber(), shortSHA(headSHA))
+		var list strings.Builder
+		for _, file := range files {
+			list.WriteString(fmt.Sprintf("- `%s`\n", file.Path))
+		}
+		prBody := fmt.Sprintf("Tests generated by `@techy tests` for the functions changed in #%d.\n\n%s\n%s", pr.GetNumber(), list.String(), notes)
+
+		// A fork's branch cannot be pushed to, but its commits are reachable here
+		// through the PR's head ref, so the tests are stacked on them in a PR
+		// into the base branch instead
+		into := pr.GetHead().GetRef()
+		if pr.GetHead().GetRepo().GetFullName() != owner+"/"+repo {
+			into = pr.GetBase().GetRef()
+			prBody = fmt.Sprintf("#%d comes from a fork the app cannot push to, so this PR contains its commits with the tests on top. "+
+				"Merge #%d first, or copy the test files into it.\n\n%s", pr.GetNumber(), pr.GetNumber(), prBody)
+		}
+
+		followUp, err := r.githubClient.CreateBranchPullRequest(ctx, owner, repo, into, headSHA, branch,
+			fmt.Sprintf("Add tests for #%d", pr.GetNumber()),
+			fmt.Sprintf("🧪 Add tests for #%d", pr.GetNumber()),
+			TruncateForGitHub(prBody, maxCommentSize), nil, files)
+		if err == nil {
+			body := FormatReview(appendSection(fmt.Sprintf("Opened #%d into `%s` with %d test file(s):\n\n%s", followUp.GetNumber(), into, len(files), list.String()), footer), mode)
+			return body, len(files), r.postComment(ctx, owner, repo, pr.GetNumber(), mode, headSHA, body)
+		}
+		log.Warn().Err(err).Msg("Failed to open follow-up PR with tests, posting them as a comment")
+	}
+
+	body := TruncateForGitHub(FormatReview(appendSection(FormatGeneratedTests(files, notes), footer), mode), maxCommentSize)
+	return body, len(files), r.postComment(ctx, owner, repo, pr.GetNumber(), mode, headSHA, body)
+}
@@ -0,0 +1,132 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+)
+
+func TestParseGeneratedTests(t *testing.T) {
+	block := func(p, lang, body string) string {
+		return "TEST_FILE: " + p + "\n```" + lang + "\n" + body + "\n```\n"
+	}
+
+	tests := []struct {
+		name      string
+		response  string
+		existing  map[string]string
+		wantFiles []gh.FileChange
+		wantNotes string
+	}{
+		{
+			name:      "no blocks",
+			response:  "Nothing to test here.",
+			wantNotes: "Nothing to test here.",
+		},
+		{
+			name:     "go and python files with notes",
+			response: "Intro.\n\n" + block("store/store_test.go", "go", "package store") + "\n\n\n" + block("`./app/test_app.py`", "python", "def test_app():\n    pass") + "\nCovers the nil case.",
+			wantFiles: []gh.FileChange{
+				{Path: "store/store_test.go", Content: "package store\n"},
+				{Path: "app/test_app.py", Content: "def test_app():\n    pass\n"},
+			},
+			wantNotes: "Intro.\n\nCovers the nil case.",
+		},
+		{
+			name:     "unsafe paths are dropped",
+			response: block("../escape_test.go", "go", "x") + block("/abs_test.go", "go", "x") + block("a/../b_test.go", "go", "x") + block(".github/workflows/x_test.go", "go", "x"),
+		},
+		{
+			name:     "non-test files are dropped",
+			response: block("store/store.go", "go", "package store") + block("README.md", "", "hi"),
+		},
+		{
+			name:      "duplicates keep the first",
+			response:  block("a_test.go", "go", "first") + block("a_test.go", "go", "second"),
+			wantFiles: []gh.FileChange{{Path: "a_test.go", Content: "first\n"}},
+		},
+		{
+			name:     "existing files are not overwritten",
+			response: block("store/store_test.go", "go", "package store"),
+			existing: map[string]string{"store/store_test.go": "package store"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, notes := ParseGeneratedTests(tt.response, tt.existing)
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("files = %+v, want %+v", files, tt.wantFiles)
+			}
+			if notes != tt.wantNotes {
+				t.Errorf("notes = %q, want %q", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestMentioned(t *testing.T) {
+	byDir := map[string][]string{
+		"store":       {"func TestGet(t *testing.T) { s.GetAll(); parse_config_v2() }"},
+		"app":         {"def test_load():\n    cfg.load()"},
+		"app/tests":   {"def test_save():\n    save(cfg)"},
+		"tests":       {"from app import parse_config\n"},
+		"unrelated/x": {"Get()"},
+	}
+
+	tests := []struct {
+		name, filename string
+		want           bool
+	}{
+		{"Get", "store/store.go", false},
+		{"GetAll", "store/store.go", true},
+		{"parse_config", "store/store.go", false},
+		{"load", "app/config.py", true},
+		{"save", "app/config.py", true},
+		{"parse_config", "app/config.py", true},
+		{"save", "store/store.go", false},
+	}
+	for _, tt := range tests {
+		if got := mentioned(tt.name, tt.filename, byDir); got != tt.want {
+			t.Errorf("mentioned(%q, %q) = %v, want %v", tt.name, tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		s, word string
+		want    bool
+	}{
+		{"store.Get(1)", "Get", true},
+		{"GetAll()", "Get", false},
+		{"s.GetAll(); s.Get()", "Get", true},
+		{"TestGet", "Get", false},
+		{"Get", "Get", true},
+		{"parse_config(x)", "parse_config", true},
+		{"parse_config_v2", "parse_config", false},
+		{"anything", "", false},
+	}
+	for _, tt := range tests {
+		if got := containsWord(tt.s, tt.word); got != tt.want {
+			t.Errorf("containsWord(%q, %q) = %v, want %v", tt.s, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	tests := map[string]bool{
+		"a/b_test.go":   true,
+		"a/b.go":        false,
+		"test_app.py":   true,
+		"app_test.py":   true,
+		"app.py":        false,
+		"testdata.json": false,
+	}
+	for p, want := range tests {
+		if got := IsTestFile(p); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
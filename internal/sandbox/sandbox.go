@@ -0,0 +1,195 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TokenSource issues a token that can read a repository's contents
+type TokenSource interface {
+	GetCloneToken(ctx context.Context, owner, repo string) (string, error)
+}
+
+// Config configures repository checkouts
+type Config struct {
+	BaseDir      string        // parent of the per-task directories; defaults to the OS temp dir
+	CloneTimeout time.Duration // limit for fetching and checking out one commit
+	MaxSizeMB    int           // abandon checkouts larger than this; 0 disables the check
+}
+
+// Manager creates ephemeral checkouts of pull request heads
+type Manager struct {
+	tokens TokenSource
+	config Config
+}
+
+// Workspace is a checkout of one commit, removed by Close
+type Workspace struct {
+	Dir    string // repository root, used as the CLI's working directory
+	TmpDir string // scratch directory for the CLI
+	root   string
+}
+
+// NewManager creates a new checkout manager
+func NewManager(tokens TokenSource, config Config) *Manager {
+	if config.BaseDir == "" {
+		config.BaseDir = os.TempDir()
+	}
+	if config.CloneTimeout <= 0 {
+		config.CloneTimeout = 2 * time.Minute
+	}
+	return &Manager{
+		tokens: tokens,
+		config: config,
+	}
+}
+
+// Checkout shallow-clones a single commit into a new directory. The caller
+// must Close the workspace when the review finishes.
+func (m *Manager) Checkout(ctx context.Context, owner, repo, sha string) (*Workspace, error) {
+	if sha == "" {
+		return nil, fmt.Errorf("no commit to check out")
+	}
+
+	token, err := m.tokens.GetCloneToken(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clone token: %w", err)
+	}
+
+	if err := os.MkdirAll(m.config.BaseDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	root, err := os.MkdirTemp(m.config.BaseDir, "techy-"+sanitize(owner+"-"+repo)+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+
+	ws := &Workspace{
+		Dir:    filepath.Join(root, "repo"),
+		TmpDir: filepath.Join(root, "tmp"),
+		root:   root,
+	}
+	for _, dir := range []string{ws.Dir, ws.TmpDir} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			ws.Close()
+			return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+		}
+	}
+
+	cloneCtx, cancel := context.WithTimeout(ctx, m.config.CloneTimeout)
+	defer cancel()
+
+	start := time.Now()
+	url := fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", "--no-tags", url, sha},
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if err := git(cloneCtx, ws.Dir, token, args...); err != nil {
+			ws.Close()
+			return nil, err
+		}
+	}
+
+	if m.config.MaxSizeMB > 0 {
+		if size := dirSize(ws.Dir); size > int64(m.config.MaxSizeMB)<<20 {
+			ws.Close()
+			return nil, fmt.Errorf("checkout of %s/%s is %d MB, above the %d MB limit", owner, repo, size>>20, m.config.MaxSizeMB)
+		}
+	}
+
+	// The CLI only reads the files; drop the object store to save space
+	if err := os.RemoveAll(filepath.Join(ws.Dir, ".git")); err != nil {
+		log.Warn().Err(err).Msg("Failed to remove .git from sandbox")
+	}
+
+	log.Info().
+		Str("repo", fmt.Sprintf("%s/%s", owner, repo)).
+		Str("sha", sha).
+		Dur("duration", time.Since(start)).
+		Msg("Checked out repository into sandbox")
+
+	return ws, nil
+}
+
+// Scratch creates a new directory in the workspace, outside the checkout,
+// that is removed with it
+func (w *Workspace) Scratch(prefix string) (string, error) {
+	return os.MkdirTemp(w.root, prefix)
+}
+
+// Close removes the workspace and everything written to it
+func (w *Workspace) Close() {
+	if w == nil || w.root == "" {
+		return
+	}
+	if err := os.RemoveAll(w.root); err != nil {
+		log.Warn().Err(err).Str("dir", w.root).Msg("Failed to remove sandbox")
+	}
+}
+
+// git runs a git command with the token passed as an HTTP header through the
+// environment, so it never appears in arguments or .git/config
+func git(ctx context.Context, dir, token string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_LFS_SKIP_SMUDGE=1",
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=http.https://github.com/.extraheader",
+		"GIT_CONFIG_VALUE_0=AUTHORIZATION: basic "+auth,
+		// Check symlinks out as plain files so none point the CLI outside the checkout
+		"GIT_CONFIG_KEY_1=core.symlinks",
+		"GIT_CONFIG_VALUE_1=false",
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// dirSize returns the total size of regular files under dir
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// sanitize keeps a repository name safe for use in a directory name
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,302 @@
+package sarif
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// Schema and version of the SARIF documents produced
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// ToolName is the driver name reported in SARIF runs
+const ToolName = "TechyBot"
+
+// InformationURI points code scanning users at the project
+const InformationURI = "https://github.com/CREVIOS/revo"
+
+// Log is the root of a SARIF document
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is a single invocation of the tool
+type Run struct {
+	Tool                     Tool                `json:"tool"`
+	Results                  []Result            `json:"results"`
+	AutomationDetails        *AutomationDetails  `json:"automationDetails,omitempty"`
+	VersionControlProvenance []VersionControlRef `json:"versionControlProvenance,omitempty"`
+}
+
+// Tool describes the analysis tool
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver is the tool component that produced the results
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules,omitempty"`
+}
+
+// Rule describes a kind of finding
+type Rule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	ShortDescription     *Message          `json:"shortDescription,omitempty"`
+	DefaultConfiguration *RuleConfig       `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]string `json:"properties,omitempty"`
+}
+
+// RuleConfig holds a rule's default level
+type RuleConfig struct {
+	Level string `json:"level"`
+}
+
+// Result is a single finding
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+// Message is plain text with an optional markdown rendering
+type Message struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+// Location points at a region of a file
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is a file and region
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation is a repository-relative file path
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a line range
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// AutomationDetails identifies the category of a run, e.g. the review mode
+type AutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// VersionControlRef records the commit that was analyzed
+type VersionControlRef struct {
+	RepositoryURI string `json:"repositoryUri,omitempty"`
+	RevisionID    string `json:"revisionId,omitempty"`
+}
+
+// Options describe the run a document is built for
+type Options struct {
+	Mode          models.ReviewMode
+	RepositoryURI string
+	CommitSHA     string
+}
+
+// FromComments builds a SARIF document from parsed review comments.
+// Findings are grouped into one rule per mode and severity.
+func FromComments(comments []models.ReviewComment, opts Options) *Log {
+	findings := make([]finding, 0, len(comments))
+	for _, c := range comments {
+		findings = append(findings, finding{Path: c.Path, Line: c.Line, Severity: c.Severity, Body: c.Body})
+	}
+	return build(findings, opts)
+}
+
+// FromReview builds a SARIF document from a stored review and its comments.
+// Rules are grouped by comment category, falling back to the review's mode.
+func FromReview(review *database.Review) *Log {
+	findings := make([]finding, 0, len(review.Comments))
+	for _, c := range review.Comments {
+		findings = append(findings, finding{
+			Category: c.Category,
+			Path:     c.FilePath,
+			Line:     c.Line,
+			Severity: c.Severity,
+			Body:     c.Body,
+		})
+	}
+	return build(findings, Options{
+		Mode:          models.ReviewMode(review.Mode),
+		RepositoryURI: fmt.Sprintf("https://github.com/%s/%s", review.Owner, review.Repo),
+		CommitSHA:     review.CommitSHA,
+	})
+}
+
+// finding is the common shape of parsed and stored comments
+type finding struct {
+	Category string
+	Path     string
+	Line     int
+	Severity string
+	Body     string
+}
+
+// build assembles the document, one rule per category and severity
+func build(findings []finding, opts Options) *Log {
+	mode := string(opts.Mode)
+	if mode == "" {
+		mode = string(models.ModeReview)
+	}
+
+	rules := map[string]Rule{}
+	results := make([]Result, 0, len(findings))
+	for _, f := range findings {
+		if f.Path == "" {
+			continue
+		}
+
+		category := f.Category
+		if category == "" {
+			category = mode
+		}
+		severity := f.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		ruleID := fmt.Sprintf("techy/%s/%s", category, severity)
+		if _, ok := rules[ruleID]; !ok {
+			rules[ruleID] = Rule{
+				ID:                   ruleID,
+				Name:                 capitalize(category) + capitalize(severity),
+				ShortDescription:     &Message{Text: fmt.Sprintf("TechyBot %s finding (%s)", category, severity)},
+				DefaultConfiguration: &RuleConfig{Level: Level(severity)},
+				Properties:           map[string]string{"category": category, "severity": severity},
+			}
+		}
+
+		result := Result{
+			RuleID:  ruleID,
+			Level:   Level(severity),
+			Message: Message{Text: plainText(f.Body), Markdown: strings.TrimSpace(f.Body)},
+			Locations: []Location{{
+				PhysicalLocation: PhysicalLocation{
+					ArtifactLocation: ArtifactLocation{URI: strings.TrimPrefix(f.Path, "/")},
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				"techyFinding/v1": fingerprint(f.Path, f.Severity, f.Body),
+			},
+		}
+		if f.Line > 0 {
+			result.Locations[0].PhysicalLocation.Region = &Region{StartLine: f.Line}
+		}
+		results = append(results, result)
+	}
+
+	ruleList := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		ruleList = append(ruleList, rule)
+	}
+	sort.Slice(ruleList, func(i, j int) bool { return ruleList[i].ID < ruleList[j].ID })
+
+	run := Run{
+		Tool: Tool{Driver: Driver{
+			Name:           ToolName,
+			InformationURI: InformationURI,
+			Rules:          ruleList,
+		}},
+		Results:           results,
+		AutomationDetails: &AutomationDetails{ID: "techy/" + mode + "/"},
+	}
+	if opts.RepositoryURI != "" || opts.CommitSHA != "" {
+		run.VersionControlProvenance = []VersionControlRef{{
+			RepositoryURI: opts.RepositoryURI,
+			RevisionID:    opts.CommitSHA,
+		}}
+	}
+
+	return &Log{
+		Schema:  Schema,
+		Version: Version,
+		Runs:    []Run{run},
+	}
+}
+
+// Marshal encodes a SARIF document as indented JSON
+func (l *Log) Marshal() ([]byte, error) {
+	return json.MarshalIndent(l, "", "  ")
+}
+
+// Encode gzips and base64-encodes a document, as the code scanning upload API expects
+func (l *Log) Encode() (string, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return "", fmt.Errorf("failed to compress SARIF: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress SARIF: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Level maps TechyBot severities to SARIF levels
+func Level(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "info":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// fingerprint identifies a finding across runs independently of its line number
+func fingerprint(path, severity, body string) string {
+	first, _, _ := strings.Cut(plainText(body), "\n")
+	sum := sha256.Sum256([]byte(path + "\n" + severity + "\n" + first))
+	return hex.EncodeToString(sum[:16])
+}
+
+// plainText strips markdown emphasis from a comment for the SARIF text message
+func plainText(body string) string {
+	text := strings.NewReplacer("**", "", "`", "").Replace(strings.TrimSpace(body))
+	if text == "" {
+		return "TechyBot finding"
+	}
+	return text
+}
+
+// capitalize upper-cases the first letter of an ASCII word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
@@ -0,0 +1,227 @@
+package sarif
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+func TestFromComments(t *testing.T) {
+	comments := []models.ReviewComment{
+		{Path: "internal/retry.go", Line: 42, Severity: "error", Body: "**Bug:** the loop never stops\n\nDetails follow."},
+		{Path: "/internal/cache.go", Line: 7, Severity: "warning", Body: "Unbounded `map` growth"},
+		{Path: "README.md", Severity: "info", Body: "Typo"},
+		{Path: "main.go", Line: 3, Body: "No severity"},
+		{Path: "", Line: 1, Severity: "error", Body: "Summary without a file"},
+		{Path: "internal/retry.go", Line: 50, Severity: "error", Body: "Second error"},
+	}
+
+	log := FromComments(comments, Options{Mode: models.ModeHunt, RepositoryURI: "https://github.com/acme/api", CommitSHA: "abc123"})
+	if log.Version != Version || log.Schema != Schema || len(log.Runs) != 1 {
+		t.Fatalf("log = %+v, want one %s run", log, Version)
+	}
+	run := log.Runs[0]
+
+	type result struct {
+		ruleID string
+		level  string
+		uri    string
+		line   int
+		text   string
+	}
+	want := []result{
+		{"techy/hunt/error", "error", "internal/retry.go", 42, "Bug: the loop never stops\n\nDetails follow."},
+		{"techy/hunt/warning", "warning", "internal/cache.go", 7, "Unbounded map growth"},
+		{"techy/hunt/info", "note", "README.md", 0, "Typo"},
+		{"techy/hunt/warning", "warning", "main.go", 3, "No severity"},
+		{"techy/hunt/error", "error", "internal/retry.go", 50, "Second error"},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(want))
+	}
+	for i, r := range run.Results {
+		location := r.Locations[0].PhysicalLocation
+		got := result{r.RuleID, r.Level, location.ArtifactLocation.URI, 0, r.Message.Text}
+		if location.Region != nil {
+			got.line = location.Region.StartLine
+		}
+		if got != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	wantRules := []struct {
+		id, name, level string
+	}{
+		{"techy/hunt/error", "HuntError", "error"},
+		{"techy/hunt/info", "HuntInfo", "note"},
+		{"techy/hunt/warning", "HuntWarning", "warning"},
+	}
+	rules := run.Tool.Driver.Rules
+	if len(rules) != len(wantRules) {
+		t.Fatalf("rules = %+v, want %d", rules, len(wantRules))
+	}
+	for i, rule := range rules {
+		if rule.ID != wantRules[i].id || rule.Name != wantRules[i].name || rule.DefaultConfiguration.Level != wantRules[i].level {
+			t.Errorf("rule %d = %s %s %s, want %+v", i, rule.ID, rule.Name, rule.DefaultConfiguration.Level, wantRules[i])
+		}
+	}
+
+	if run.Tool.Driver.Name != ToolName || run.AutomationDetails.ID != "techy/hunt/" {
+		t.Errorf("driver %q, automation %q", run.Tool.Driver.Name, run.AutomationDetails.ID)
+	}
+	if len(run.VersionControlProvenance) != 1 || run.VersionControlProvenance[0].RevisionID != "abc123" {
+		t.Errorf("provenance = %+v", run.VersionControlProvenance)
+	}
+}
+
+func TestFromCommentsDefaults(t *testing.T) {
+	log := FromComments([]models.ReviewComment{{Path: "a.go", Line: 1, Body: "  "}}, Options{})
+	run := log.Runs[0]
+	if run.AutomationDetails.ID != "techy/review/" || run.Results[0].RuleID != "techy/review/warning" {
+		t.Errorf("automation %q, rule %q, want the review mode", run.AutomationDetails.ID, run.Results[0].RuleID)
+	}
+	if run.Results[0].Message.Text != "TechyBot finding" {
+		t.Errorf("message = %q, want a placeholder for an empty body", run.Results[0].Message.Text)
+	}
+	if run.VersionControlProvenance != nil {
+		t.Errorf("provenance = %+v, want none without a repository or commit", run.VersionControlProvenance)
+	}
+
+	empty := FromComments(nil, Options{Mode: models.ModeSecurity})
+	data, err := empty.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Code scanning rejects a run whose results are null
+	if !bytes.Contains(data, []byte(`"results": []`)) {
+		t.Errorf("empty document = %s, want an empty results array", data)
+	}
+}
+
+func TestFromReview(t *testing.T) {
+	review := &database.Review{
+		Owner:     "acme",
+		Repo:      "api",
+		Mode:      "security",
+		CommitSHA: "def456",
+		Comments: []database.ReviewComment{
+			{FilePath: "auth.go", Line: 10, Severity: "error", Category: "injection", Body: "SQL built from input"},
+			{FilePath: "auth.go", Line: 20, Severity: "error", Body: "Token logged"},
+			{FilePath: "auth.go", Line: 30, Severity: "info", Category: "injection", Body: "Consider prepared statements"},
+		},
+	}
+
+	run := FromReview(review).Runs[0]
+	var ruleIDs []string
+	for _, r := range run.Results {
+		ruleIDs = append(ruleIDs, r.RuleID)
+	}
+	want := []string{"techy/injection/error", "techy/security/error", "techy/injection/info"}
+	if len(ruleIDs) != len(want) {
+		t.Fatalf("rule IDs = %q, want %q", ruleIDs, want)
+	}
+	for i := range want {
+		if ruleIDs[i] != want[i] {
+			t.Errorf("rule IDs = %q, want %q", ruleIDs, want)
+			break
+		}
+	}
+	if len(run.Tool.Driver.Rules) != 3 || run.Tool.Driver.Rules[0].Properties["category"] != "injection" {
+		t.Errorf("rules = %+v", run.Tool.Driver.Rules)
+	}
+	if ref := run.VersionControlProvenance[0]; ref.RepositoryURI != "https://github.com/acme/api" || ref.RevisionID != "def456" {
+		t.Errorf("provenance = %+v", ref)
+	}
+	if run.AutomationDetails.ID != "techy/security/" {
+		t.Errorf("automation = %q", run.AutomationDetails.ID)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := map[string]string{
+		"error":   "error",
+		"warning": "warning",
+		"info":    "note",
+		"":        "warning",
+		"high":    "warning",
+	}
+	for severity, want := range tests {
+		if got := Level(severity); got != want {
+			t.Errorf("Level(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := fingerprint("a.go", "error", "**Nil map** write\n\nMore detail.")
+	if len(base) != 32 {
+		t.Fatalf("fingerprint = %q, want 32 hex characters", base)
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		sev   string
+		body  string
+		equal bool
+	}{
+		{"same finding", "a.go", "error", "**Nil map** write\n\nMore detail.", true},
+		{"different detail", "a.go", "error", "**Nil map** write\n\nReworded detail.", true},
+		{"markdown and spacing", "a.go", "error", "  Nil map write\n", true},
+		{"different path", "b.go", "error", "**Nil map** write", false},
+		{"different severity", "a.go", "warning", "**Nil map** write", false},
+		{"different first line", "a.go", "error", "**Nil map** read", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.path, tt.sev, tt.body); (got == base) != tt.equal {
+				t.Errorf("fingerprint() = %q, base %q, want equal %v", got, base, tt.equal)
+			}
+		})
+	}
+
+	// The line is not part of the fingerprint, so moved code keeps its alert
+	moved := FromComments([]models.ReviewComment{
+		{Path: "a.go", Line: 10, Severity: "error", Body: "Nil map write"},
+		{Path: "a.go", Line: 90, Severity: "error", Body: "Nil map write"},
+	}, Options{}).Runs[0].Results
+	if moved[0].PartialFingerprints["techyFinding/v1"] != moved[1].PartialFingerprints["techyFinding/v1"] {
+		t.Errorf("fingerprints differ across lines: %v, %v", moved[0].PartialFingerprints, moved[1].PartialFingerprints)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	log := FromComments([]models.ReviewComment{{Path: "a.go", Line: 1, Severity: "error", Body: "Bug"}}, Options{})
+	encoded, err := log.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("not base64: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("not gzip: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Log
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("not SARIF JSON: %v", err)
+	}
+	if len(decoded.Runs) != 1 || len(decoded.Runs[0].Results) != 1 || decoded.Runs[0].Results[0].RuleID != "techy/review/error" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
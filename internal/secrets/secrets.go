@@ -0,0 +1,260 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Finding is a secret found on an added line of a diff
+type Finding struct {
+	Path        string
+	Line        int    // line in the new file, 0 on a removed line
+	Rule        string // id of the rule that matched, e.g. aws-access-key-id
+	Description string
+	Secret      string // the matched value; never post or store it
+	Added       bool   // on an added line rather than a removed or unchanged one
+}
+
+// rule matches one kind of secret; Group selects the secret within the match
+type rule struct {
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+	Group       int
+	MinEntropy  float64 // minimum Shannon entropy of the secret in bits per character
+}
+
+// rules are checked in order; the first to match a value claims it
+var rules = []rule{
+	{ID: "aws-access-key-id", Description: "AWS access key ID", Pattern: regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`), Group: 1},
+	{ID: "aws-secret-access-key", Description: "AWS secret access key", Pattern: regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|sk).{0,20}?[:=]\s*["']?([A-Za-z0-9/+]{40})\b`), Group: 1, MinEntropy: 4},
+	{ID: "github-token", Description: "GitHub token", Pattern: regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`), Group: 1},
+	{ID: "gitlab-token", Description: "GitLab personal access token", Pattern: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20})\b`), Group: 1},
+	{ID: "slack-token", Description: "Slack token", Pattern: regexp.MustCompile(`\b(xox[baprs]-[A-Za-z0-9-]{10,})\b`), Group: 1},
+	{ID: "slack-webhook", Description: "Slack incoming webhook URL", Pattern: regexp.MustCompile(`(https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+)`), Group: 1},
+	{ID: "stripe-key", Description: "Stripe live secret key", Pattern: regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{24,})\b`), Group: 1},
+	{ID: "google-api-key", Description: "Google API key", Pattern: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})`), Group: 1},
+	{ID: "google-oauth-secret", Description: "Google OAuth client secret", Pattern: regexp.MustCompile(`\b(GOCSPX-[A-Za-z0-9_-]{28})`), Group: 1},
+	{ID: "anthropic-api-key", Description: "Anthropic API key", Pattern: regexp.MustCompile(`\b(sk-ant-[A-Za-z0-9_-]{32,})`), Group: 1},
+	{ID: "openai-api-key", Description: "OpenAI API key", Pattern: regexp.MustCompile(`\b(sk-(?:proj-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,})`), Group: 1},
+	{ID: "npm-token", Description: "npm access token", Pattern: regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`), Group: 1},
+	{ID: "sendgrid-key", Description: "SendGrid API key", Pattern: regexp.MustCompile(`\b(SG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43})`), Group: 1},
+	{ID: "azure-storage-key", Description: "Azure storage account key", Pattern: regexp.MustCompile(`AccountKey=([A-Za-z0-9+/]{86}==)`), Group: 1},
+	{ID: "jwt", Description: "JSON Web Token", Pattern: regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`), Group: 1},
+	{ID: "connection-string-password", Description: "Password in a connection URL", Pattern: regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@'"]+:([^\s:/@'"]{6,})@`), Group: 1, MinEntropy: 2.5},
+	{ID: "generic-secret", Description: "High-entropy value assigned to a secret-like name", Pattern: regexp.MustCompile(`(?i)[\w.-]*(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|private[_-]?key|credential|auth[_-]?key)[\w.-]*["']?\s*(?::=|=>|[:=])\s*["'` + "`" + `]([A-Za-z0-9+/=_\-.~!@#$%^&*]{16,})["'` + "`" + `]`), Group: 1, MinEntropy: 3.5},
+}
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN ((?:RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END ((?:RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)
+	hunkHeader      = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// placeholders mark example values that are not real secrets
+var placeholders = []string{"example", "xxxx", "changeme", "placeholder", "dummy", "your_", "your-", "<", "${", "{{", "redacted", "0000000000"}
+
+// ScanDiff finds secrets on every line of a unified diff. Secrets on removed
+// and unchanged lines are still sent with the diff, so they are returned for
+// redaction too; only those on added lines are marked Added and reported.
+func ScanDiff(diff string) []Finding {
+	var findings []Finding
+	var file, oldFile string
+	line, oldLeft, newLeft := 0, 0, 0
+	inKey := false
+	var key Finding
+
+	for _, text := range strings.Split(diff, "\n") {
+		inHunk := oldLeft > 0 || newLeft > 0
+		switch {
+		case !inHunk && strings.HasPrefix(text, "--- "):
+			oldFile = diffPath(strings.TrimPrefix(text, "--- "), "a/")
+			inKey = false
+			continue
+		case !inHunk && strings.HasPrefix(text, "+++ "):
+			file = diffPath(strings.TrimPrefix(text, "+++ "), "b/")
+			if file == "" {
+				file = oldFile
+			}
+			inKey = false
+			continue
+		case !inHunk && strings.HasPrefix(text, "diff --git "):
+			continue
+		case strings.HasPrefix(text, "@@"):
+			if match := hunkHeader.FindStringSubmatch(text); match != nil {
+				oldLeft = hunkCount(match[1])
+				line, _ = strconv.Atoi(match[2])
+				newLeft = hunkCount(match[3])
+			}
+			continue
+		case strings.HasPrefix(text, `\`):
+			continue
+		}
+
+		// Removed lines have no line in the new file
+		added := strings.HasPrefix(text, "+")
+		removed := strings.HasPrefix(text, "-")
+		at := line
+		switch {
+		case added:
+			newLeft--
+			line++
+		case removed:
+			oldLeft--
+			at = 0
+		default:
+			oldLeft--
+			newLeft--
+			line++
+		}
+		if len(text) > 0 {
+			text = text[1:]
+		}
+
+		if inKey {
+			// The key's body is redacted line by line under the BEGIN finding
+			if privateKeyEnd.MatchString(text) {
+				inKey = false
+			} else if body := strings.TrimSpace(text); len(body) >= 16 {
+				findings = append(findings, Finding{Path: key.Path, Line: at, Rule: key.Rule, Secret: body, Added: added})
+			}
+			continue
+		}
+		if privateKeyBegin.MatchString(text) {
+			inKey = !privateKeyEnd.MatchString(text)
+			findings = append(findings, Finding{
+				Path:        file,
+				Line:        at,
+				Rule:        "private-key",
+				Description: "Private key",
+				Secret:      strings.TrimSpace(privateKeyBegin.ReplaceAllString(text, "")),
+				Added:       added,
+			})
+			key = findings[len(findings)-1]
+			continue
+		}
+
+		for _, f := range scanLine(text) {
+			f.Path = file
+			f.Line = at
+			f.Added = added
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// diffPath returns the path of a ---/+++ header, or "" for /dev/null
+func diffPath(name, prefix string) string {
+	name, _, _ = strings.Cut(name, "\t")
+	if name == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+// hunkCount parses the optional line count of a hunk range, which defaults to 1
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(count)
+	return n
+}
+
+// scanLine applies the rules to one line
+func scanLine(text string) []Finding {
+	var findings []Finding
+	claimed := map[string]bool{}
+	for _, r := range rules {
+		for _, match := range r.Pattern.FindAllStringSubmatch(text, -1) {
+			secret := match[r.Group]
+			if claimed[secret] || isPlaceholder(secret) {
+				continue
+			}
+			if r.MinEntropy > 0 && (Entropy(secret) < r.MinEntropy || (r.ID == "generic-secret" && !mixedCharacters(secret))) {
+				continue
+			}
+			claimed[secret] = true
+			findings = append(findings, Finding{Rule: r.ID, Description: r.Description, Secret: secret})
+		}
+	}
+	return findings
+}
+
+// Reported keeps the secrets the diff adds, dropping the continuation lines
+// of private keys, leaving one finding per secret to post
+func Reported(findings []Finding) []Finding {
+	var reported []Finding
+	for _, f := range findings {
+		if f.Added && f.Description != "" {
+			reported = append(reported, f)
+		}
+	}
+	return reported
+}
+
+// Redact replaces every secret of findings in text with a marker naming its rule
+func Redact(text string, findings []Finding) string {
+	if len(findings) == 0 || text == "" {
+		return text
+	}
+	// Replace longer secrets first so one secret containing another is fully redacted
+	sorted := append([]Finding(nil), findings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Secret) > len(sorted[j].Secret) })
+
+	pairs := make([]string, 0, 2*len(sorted))
+	seen := map[string]bool{}
+	for _, f := range sorted {
+		if f.Secret == "" || seen[f.Secret] {
+			continue
+		}
+		seen[f.Secret] = true
+		pairs = append(pairs, f.Secret, Marker(f.Rule))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Marker is the text that replaces a redacted secret
+func Marker(ruleID string) string {
+	return "[REDACTED " + ruleID + "]"
+}
+
+// Entropy is the Shannon entropy of s in bits per character
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// isPlaceholder reports whether a value looks like an example rather than a secret
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, p := range placeholders {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// mixedCharacters reports whether a value has both letters and digits, which
+// identifiers and prose assigned to secret-like names rarely do
+func mixedCharacters(value string) bool {
+	return strings.ContainsAny(value, "0123456789") &&
+		strings.ContainsAny(strings.ToLower(value), "abcdefghijklmnopqrstuvwxyz")
+}
@@ -4,12 +4,17 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/CREVIOS/revo/internal/database"
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/internal/prompts"
+	"github.com/CREVIOS/revo/internal/sarif"
+	"github.com/CREVIOS/revo/pkg/models"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
@@ -66,10 +71,16 @@ func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	modeCounts := map[string]int64{}
-	for _, mode := range []string{"review", "hunt", "security", "performance", "analyze"} {
-		var count int64
-		_ = db.Model(&database.Review{}).Where("mode = ?", mode).Count(&count).Error
-		modeCounts[mode] = count
+	for _, mode := range modes.Names("", "") {
+		modeCounts[mode] = 0
+	}
+	var modeRows []struct {
+		Mode  string
+		Count int64
+	}
+	_ = db.Model(&database.Review{}).Select("mode, COUNT(*) AS count").Group("mode").Scan(&modeRows).Error
+	for _, row := range modeRows {
+		modeCounts[row.Mode] = row.Count
 	}
 
 	var bugsSum sql.NullInt64
@@ -160,6 +171,35 @@ func (s *Server) getReviewHandler(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, review)
 }
 
+// reviewSARIFHandler exports a review's stored findings as SARIF 2.1.0
+func (s *Server) reviewSARIFHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var review database.Review
+	query := s.store.DB().Preload("Comments").First(&review, id)
+	if err := query.Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	data, err := sarif.FromReview(&review).Marshal()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode SARIF")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/sarif+json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=techy-review-%d.sarif", review.ID))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		log.Error().Err(err).Msg("Failed to write SARIF response")
+	}
+}
+
 func (s *Server) createReviewHandler(w http.ResponseWriter, r *http.Request) {
 	var review database.Review
 	if err := decodeJSON(r, &review); err != nil {
@@ -681,26 +721,447 @@ func (s *Server) deleteAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (s *Server) listCustomModesHandler(w http.ResponseWriter, r *http.Request) {
+	query := s.store.DB().Model(&database.CustomMode{})
+
+	if v := r.URL.Query().Get("name"); v != "" {
+		query = query.Where("name = ?", v)
+	}
+	if v := r.URL.Query().Get("owner"); v != "" {
+		query = query.Where("owner = ?", v)
+	}
+	if v := r.URL.Query().Get("repo"); v != "" {
+		query = query.Where("repo = ?", v)
+	}
+	if v := r.URL.Query().Get("is_active"); v != "" {
+		if isActive, err := strconv.ParseBool(v); err == nil {
+			query = query.Where("is_active = ?", isActive)
+		}
+	}
+
+	items := []database.CustomMode{}
+	page, ok := findPage(w, r, query, &items)
+	if !ok {
+		return
+	}
+	for i := range items {
+		items[i].Disabled = modes.Disabled(items[i].ID)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) getCustomModeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var mode database.CustomMode
+	if err := s.store.DB().First(&mode, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+	mode.Disabled = modes.Disabled(mode.ID)
+
+	writeJSON(w, http.StatusOK, mode)
+}
+
+func (s *Server) createCustomModeHandler(w http.ResponseWriter, r *http.Request) {
+	mode := database.CustomMode{IsActive: true}
+	if err := decodeJSON(r, &mode); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := modes.Validate(&mode); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mode.ID = 0
+	if err := s.store.DB().Create(&mode).Error; err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create custom mode")
+		return
+	}
+
+	s.reloadModes()
+	writeJSON(w, http.StatusCreated, mode)
+}
+
+func (s *Server) updateCustomModeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var mode database.CustomMode
+	if err := s.store.DB().First(&mode, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	// Apply the partial update onto the stored record so the result can be validated
+	if err := decodeJSON(r, &mode); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mode.ID = id
+	if err := modes.Validate(&mode); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.DB().Save(&mode).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	s.reloadModes()
+	writeJSON(w, http.StatusOK, mode)
+}
+
+func (s *Server) deleteCustomModeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := s.store.DB().Delete(&database.CustomMode{}, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	s.reloadModes()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// availableModesHandler lists the modes usable on a repository (?owner=&repo=)
+func (s *Server) availableModesHandler(w http.ResponseWriter, r *http.Request) {
+	owner := r.URL.Query().Get("owner")
+	repo := r.URL.Query().Get("repo")
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items": modes.Available(owner, repo),
+	})
+}
+
+// reloadModes refreshes this instance's mode registry after an API change
+func (s *Server) reloadModes() {
+	if err := modes.Load(s.store); err != nil {
+		log.Warn().Err(err).Msg("Failed to reload custom modes")
+	}
+}
+
+func (s *Server) listPromptTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	query := s.store.DB().Model(&database.PromptTemplate{})
+
+	if v := r.URL.Query().Get("mode"); v != "" {
+		query = query.Where("mode = ?", v)
+	}
+	if v := r.URL.Query().Get("is_active"); v != "" {
+		if isActive, err := strconv.ParseBool(v); err == nil {
+			query = query.Where("is_active = ?", isActive)
+		}
+	}
+
+	listWithPagination(w, r, query, &[]database.PromptTemplate{})
+}
+
+func (s *Server) getPromptTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var tmpl database.PromptTemplate
+	if err := s.store.DB().First(&tmpl, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tmpl)
+}
+
+// createPromptTemplateHandler stores a new version of a mode's prompt template.
+// Versions are immutable; change the text by creating another version.
+func (s *Server) createPromptTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tmpl := database.PromptTemplate{IsActive: true}
+	if err := decodeJSON(r, &tmpl); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tmpl.Mode = strings.ToLower(strings.TrimSpace(tmpl.Mode))
+	if _, ok := modes.Get(models.ReviewMode(tmpl.Mode)); !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown mode %q", tmpl.Mode))
+		return
+	}
+	if err := prompts.Validate(tmpl.Template); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.validateRollout(tmpl.Mode, 0, tmpl.RolloutPercent, tmpl.IsActive); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := s.store.NextPromptVersion(tmpl.Mode)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to allocate prompt version")
+		return
+	}
+
+	tmpl.ID = 0
+	tmpl.Version = version
+	if err := s.store.DB().Create(&tmpl).Error; err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create prompt template")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tmpl)
+}
+
+// updatePromptTemplateHandler changes a version's rollout, activation or notes
+func (s *Server) updatePromptTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	updates, err := decodeUpdates(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for key := range updates {
+		switch key {
+		case "rollout_percent", "is_active", "notes":
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("%s cannot be changed; create a new version instead", key))
+			return
+		}
+	}
+
+	var tmpl database.PromptTemplate
+	if err := s.store.DB().First(&tmpl, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	rollout, isActive := tmpl.RolloutPercent, tmpl.IsActive
+	if v, ok := updates["rollout_percent"].(float64); ok {
+		rollout = int(v)
+	}
+	if v, ok := updates["is_active"].(bool); ok {
+		isActive = v
+	}
+	if err := s.validateRollout(tmpl.Mode, tmpl.ID, rollout, isActive); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.DB().Model(&database.PromptTemplate{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	if err := s.store.DB().First(&tmpl, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tmpl)
+}
+
+func (s *Server) deletePromptTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := s.store.DB().Delete(&database.PromptTemplate{}, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateRollout checks that a mode's active rollouts stay within 100 percent
+func (s *Server) validateRollout(mode string, excludeID uint, percent int, isActive bool) error {
+	active, err := s.store.ListActivePromptTemplates(mode)
+	if err != nil {
+		return fmt.Errorf("failed to check rollout: %w", err)
+	}
+	return prompts.ValidateRollout(mode, active, excludeID, percent, isActive)
+}
+
+// experimentsHandler compares outcomes of reviews per prompt version (?mode=&since=RFC3339).
+// The acceptance rate is the share of a variant's stored findings that were
+// applied with the fix command.
+func (s *Server) experimentsHandler(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be an RFC3339 timestamp")
+			return
+		}
+	}
+
+	query := s.store.DB().Model(&database.Review{})
+	if mode != "" {
+		query = query.Where("mode = ?", mode)
+	}
+	if !since.IsZero() {
+		query = query.Where("created_at >= ?", since)
+	}
+
+	var variants []struct {
+		Mode              string  `json:"mode"`
+		PromptVersion     string  `json:"prompt_version"`
+		Reviews           int64   `json:"reviews"`
+		Completed         int64   `json:"completed"`
+		Failed            int64   `json:"failed"`
+		AvgBugsFound      float64 `json:"avg_bugs_found"`
+		AvgCommentsPosted float64 `json:"avg_comments_posted"`
+		AvgDurationMs     float64 `json:"avg_duration_ms"`
+		Findings          int64   `json:"findings"`
+		Accepted          int64   `json:"accepted"`
+		AcceptanceRate    float64 `json:"acceptance_rate"`
+	}
+	err := query.Select(`mode, COALESCE(NULLIF(prompt_version, ''), 'default') AS prompt_version,
+		COUNT(*) AS reviews,
+		SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END) AS completed,
+		SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) AS failed,
+		COALESCE(AVG(CASE WHEN status = 'completed' THEN bugs_found END), 0) AS avg_bugs_found,
+		COALESCE(AVG(CASE WHEN status = 'completed' THEN comments_posted END), 0) AS avg_comments_posted,
+		COALESCE(AVG(CASE WHEN status = 'completed' THEN duration_ms END), 0) AS avg_duration_ms`).
+		Group("mode, COALESCE(NULLIF(prompt_version, ''), 'default')").
+		Order("mode, prompt_version").
+		Scan(&variants).Error
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to aggregate experiments")
+		return
+	}
+
+	findings := s.store.DB().Model(&database.ReviewComment{}).
+		Joins("JOIN reviews ON reviews.id = review_comments.review_id AND reviews.deleted_at IS NULL")
+	if mode != "" {
+		findings = findings.Where("reviews.mode = ?", mode)
+	}
+	if !since.IsZero() {
+		findings = findings.Where("reviews.created_at >= ?", since)
+	}
+
+	var acceptance []struct {
+		Mode          string
+		PromptVersion string
+		Findings      int64
+		Accepted      int64
+	}
+	err = findings.Select(`reviews.mode AS mode, COALESCE(NULLIF(reviews.prompt_version, ''), 'default') AS prompt_version,
+		COUNT(*) AS findings,
+		COUNT(review_comments.fixed_at) AS accepted`).
+		Group("reviews.mode, COALESCE(NULLIF(reviews.prompt_version, ''), 'default')").
+		Scan(&acceptance).Error
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to aggregate finding acceptance")
+		return
+	}
+
+	for i := range variants {
+		for _, a := range acceptance {
+			if a.Mode == variants[i].Mode && a.PromptVersion == variants[i].PromptVersion {
+				variants[i].Findings = a.Findings
+				variants[i].Accepted = a.Accepted
+				if a.Findings > 0 {
+					variants[i].AcceptanceRate = float64(a.Accepted) / float64(a.Findings)
+				}
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items": variants,
+	})
+}
+
+func (s *Server) listAuditLogsHandler(w http.ResponseWriter, r *http.Request) {
+	query := s.store.DB().Model(&database.AuditLog{})
+
+	if v := r.URL.Query().Get("owner"); v != "" {
+		query = query.Where("owner = ?", v)
+	}
+	if v := r.URL.Query().Get("repo"); v != "" {
+		query = query.Where("repo = ?", v)
+	}
+	if v := r.URL.Query().Get("pr_number"); v != "" {
+		if prNumber, err := strconv.Atoi(v); err == nil {
+			query = query.Where("pr_number = ?", prNumber)
+		}
+	}
+	if v := r.URL.Query().Get("actor"); v != "" {
+		query = query.Where("actor = ?", v)
+	}
+	if v := r.URL.Query().Get("decision"); v != "" {
+		query = query.Where("decision = ?", v)
+	}
+
+	listWithPagination(w, r, query, &[]database.AuditLog{})
+}
+
+func (s *Server) getAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var entry database.AuditLog
+	if err := s.store.DB().First(&entry, id).Error; err != nil {
+		handleDBError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
 func listWithPagination(w http.ResponseWriter, r *http.Request, query *gorm.DB, out interface{}) {
+	if page, ok := findPage(w, r, query, out); ok {
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// findPage loads one page of records into out and returns the list response,
+// or writes the error and returns false
+func findPage(w http.ResponseWriter, r *http.Request, query *gorm.DB, out interface{}) (map[string]interface{}, bool) {
 	limit, offset := parsePagination(r)
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to count records")
-		return
+		return nil, false
 	}
 
 	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(out).Error; err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to list records")
-		return
+		return nil, false
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	return map[string]interface{}{
 		"items":  out,
 		"total":  total,
 		"limit":  limit,
 		"offset": offset,
-	})
+	}, true
 }
 
 func parsePagination(r *http.Request) (int, int) {
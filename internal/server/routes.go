@@ -2,9 +2,11 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/CREVIOS/revo/internal/modes"
 	"github.com/rs/zerolog/log"
 )
 
@@ -36,6 +38,7 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/reviews/{id:[0-9]+}", s.getReviewHandler).Methods(http.MethodGet)
 	api.HandleFunc("/reviews/{id:[0-9]+}", s.updateReviewHandler).Methods(http.MethodPut)
 	api.HandleFunc("/reviews/{id:[0-9]+}", s.deleteReviewHandler).Methods(http.MethodDelete)
+	api.HandleFunc("/reviews/{id:[0-9]+}/sarif", s.reviewSARIFHandler).Methods(http.MethodGet)
 
 	api.HandleFunc("/review-comments", s.listReviewCommentsHandler).Methods(http.MethodGet)
 	api.HandleFunc("/review-comments", s.createReviewCommentHandler).Methods(http.MethodPost)
@@ -67,6 +70,23 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/api-keys/{id:[0-9]+}", s.updateAPIKeyHandler).Methods(http.MethodPut)
 	api.HandleFunc("/api-keys/{id:[0-9]+}", s.deleteAPIKeyHandler).Methods(http.MethodDelete)
 
+	api.HandleFunc("/modes", s.listCustomModesHandler).Methods(http.MethodGet)
+	api.HandleFunc("/modes", s.createCustomModeHandler).Methods(http.MethodPost)
+	api.HandleFunc("/modes/available", s.availableModesHandler).Methods(http.MethodGet)
+	api.HandleFunc("/modes/{id:[0-9]+}", s.getCustomModeHandler).Methods(http.MethodGet)
+	api.HandleFunc("/modes/{id:[0-9]+}", s.updateCustomModeHandler).Methods(http.MethodPut)
+	api.HandleFunc("/modes/{id:[0-9]+}", s.deleteCustomModeHandler).Methods(http.MethodDelete)
+
+	api.HandleFunc("/prompt-templates", s.listPromptTemplatesHandler).Methods(http.MethodGet)
+	api.HandleFunc("/prompt-templates", s.createPromptTemplateHandler).Methods(http.MethodPost)
+	api.HandleFunc("/prompt-templates/{id:[0-9]+}", s.getPromptTemplateHandler).Methods(http.MethodGet)
+	api.HandleFunc("/prompt-templates/{id:[0-9]+}", s.updatePromptTemplateHandler).Methods(http.MethodPut)
+	api.HandleFunc("/prompt-templates/{id:[0-9]+}", s.deletePromptTemplateHandler).Methods(http.MethodDelete)
+	api.HandleFunc("/experiments", s.experimentsHandler).Methods(http.MethodGet)
+
+	api.HandleFunc("/audit-logs", s.listAuditLogsHandler).Methods(http.MethodGet)
+	api.HandleFunc("/audit-logs/{id:[0-9]+}", s.getAuditLogHandler).Methods(http.MethodGet)
+
 	// Cache management endpoints
 	api.HandleFunc("/cache/stats", s.cacheStatsHandler).Methods(http.MethodGet)
 	api.HandleFunc("/cache/clear", s.cacheClearHandler).Methods(http.MethodPost)
@@ -245,13 +265,7 @@ func (s *Server) infoHandler(w http.ResponseWriter, r *http.Request) {
 	}{
 		Name:        "TechyBot",
 		Description: "AI-powered code review bot using Claude Code CLI (like Cursor's BugBot)",
-		Commands: []string{
-			"@techy hunt - Quick bug detection (BugBot mode)",
-			"@techy review - Standard code review",
-			"@techy security - Security-focused analysis",
-			"@techy performance - Performance optimization",
-			"@techy analyze - Deep technical analysis",
-		},
+		Commands: modeCommands(s.config.BotUsername),
 		Features: []string{
 			"Inline comments with line numbers",
 			"Queue system for concurrent reviews",
@@ -267,6 +281,16 @@ func (s *Server) infoHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(info)
 }
 
+// modeCommands lists the globally available review modes as trigger commands
+func modeCommands(botUsername string) []string {
+	available := modes.Available("", "")
+	commands := make([]string, 0, len(available))
+	for _, m := range available {
+		commands = append(commands, fmt.Sprintf("@%s %s - %s", botUsername, m.Name, m.Description))
+	}
+	return commands
+}
+
 // statsHandler returns current queue and rate limiter statistics
 func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
 	stats := struct {
@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,10 +14,11 @@ import (
 	"github.com/CREVIOS/revo/internal/database"
 	"github.com/CREVIOS/revo/internal/dedup"
 	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/inbox"
+	"github.com/CREVIOS/revo/internal/modes"
 	"github.com/CREVIOS/revo/internal/ratelimit"
 	"github.com/CREVIOS/revo/internal/retry"
 	"github.com/CREVIOS/revo/internal/review"
-	"github.com/CREVIOS/revo/internal/tasks"
 	"github.com/CREVIOS/revo/pkg/models"
 	"github.com/gorilla/mux"
 	"github.com/hibiken/asynq"
@@ -41,6 +41,7 @@ type Server struct {
 	asynqInspector  *asynq.Inspector
 	asynqQueue      string
 	deduplicator    *dedup.Deduplicator
+	inbox           *inbox.Inbox
 }
 
 // New creates a new Server instance
@@ -57,6 +58,12 @@ func New(cfg *models.Config) (*Server, error) {
 	}
 	s.store = database.NewStore(db)
 
+	// Load user-defined review modes and keep them in sync with other instances
+	if err := modes.Load(s.store); err != nil {
+		log.Warn().Err(err).Msg("Failed to load custom modes")
+	}
+	modes.StartRefresh(context.Background(), s.store, modes.DefaultRefreshInterval)
+
 	redisOpt := asynq.RedisClientOpt{
 		Addr:     cfg.RedisAddr,
 		Password: cfg.RedisPassword,
@@ -103,10 +110,10 @@ func New(cfg *models.Config) (*Server, error) {
 	}
 
 	// Initialize reviewer with enhanced features
-	s.reviewer = review.NewReviewer(s.githubClient, s.claudeClient, cfg.MaxDiffSize)
-	s.reviewer.SetContextAnalyzer(s.contextAnalyzer)
-	s.reviewer.SetRateLimiter(s.rateLimiter)
-	s.reviewer.SetStore(s.store)
+	s.reviewer = review.NewFromConfig(cfg, s.githubClient, s.claudeClient, s.store, s.contextAnalyzer, s.rateLimiter)
+
+	// Initialize durable inbox for incoming commands
+	s.inbox = inbox.New(s.store, s.githubClient, s.asynqClient, cfg.BotUsername, cfg.AsynqQueue, cfg.AsynqMaxRetry)
 
 	// Initialize webhook handler
 	s.webhookHandler = gh.NewWebhookHandler(
@@ -114,6 +121,9 @@ func New(cfg *models.Config) (*Server, error) {
 		cfg.BotUsername,
 		s.handleCommand,
 	)
+	s.webhookHandler.SetAutoSummarize(cfg.AutoSummarize)
+	s.webhookHandler.SetAutoMigrations(len(cfg.MigrationPaths) > 0)
+	s.webhookHandler.SetPushReview(cfg.PushReviewBranches, models.ReviewMode(cfg.PushReviewMode))
 
 	// Setup routes
 	s.setupRoutes()
@@ -130,170 +140,42 @@ func New(cfg *models.Config) (*Server, error) {
 	return s, nil
 }
 
-// handleCommand processes a parsed command from a webhook event
+// handleCommand records a parsed command in the durable inbox.
+// The expensive follow-up work runs in the worker as a "webhook:ingest" task.
 func (s *Server) handleCommand(event *gh.WebhookEvent) error {
 	owner := event.Repository.Owner.Login
 	repo := event.Repository.Name
 	prNumber := event.PullRequest.Number
-	commitSHA := ""
-	senderLogin := ""
-	if event.Sender != nil {
-		senderLogin = event.Sender.Login
-	}
-
-	// Add eyes reaction immediately to acknowledge we've seen the request
-	// This provides instant feedback like Cursor BugBot does
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	if err := s.githubClient.AddReaction(ctx, owner, repo, event.Comment.ID, "eyes"); err != nil {
-		log.Warn().Err(err).Msg("Failed to add eyes reaction")
-	}
-	cancel()
 
-	// Check for duplicate requests (same PR + commit + mode within TTL)
-	if s.deduplicator != nil {
-		dedupKey := dedup.RequestKeyWithMode(owner, repo, prNumber, "", string(event.Command.Mode))
-		if event.PullRequest != nil && event.PullRequest.Head != nil {
-			dedupKey = dedup.RequestKeyWithMode(owner, repo, prNumber, event.PullRequest.Head.SHA, string(event.Command.Mode))
+	// Check for duplicate requests (same PR + commit + mode and options within TTL)
+	if s.deduplicator != nil && event.CommandError == "" && !event.Command.IsControl() {
+		commandKey := event.Command.Key()
+		dedupKey := dedup.RequestKeyWithMode(owner, repo, prNumber, "", commandKey)
+		if event.PullRequest.Head != nil {
+			dedupKey = dedup.RequestKeyWithMode(owner, repo, prNumber, event.PullRequest.Head.SHA, commandKey)
+		} else if issue := event.IssueNumber(); issue != 0 {
+			// Issue commands differ by their text, so only redeliveries of a comment are duplicates
+			dedupKey = dedup.IssueKey(owner, repo, issue, event.Comment.ID, commandKey)
 		}
 
-		isDuplicate, waitChan := s.deduplicator.CheckAndMark(dedupKey)
+		isDuplicate, _ := s.deduplicator.CheckAndMark(dedupKey)
 		if isDuplicate {
 			log.Info().
 				Str("key", dedupKey).
 				Msg("Duplicate request detected, skipping")
-			// Optionally wait for the original request to complete
-			if waitChan != nil {
-				select {
-				case <-waitChan:
-					log.Debug().Str("key", dedupKey).Msg("Original request completed")
-				case <-time.After(100 * time.Millisecond):
-					// Don't block too long
-				}
-			}
 			return nil
 		}
-		// Mark for completion when this function returns
-		defer func() {
-			s.deduplicator.Complete(dedupKey, nil)
-		}()
-	}
-
-	if s.store != nil {
-		if event.PullRequest != nil && event.PullRequest.Head != nil {
-			commitSHA = event.PullRequest.Head.SHA
-		}
-		if commitSHA == "" {
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			pr, err := s.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
-			cancel()
-			if err == nil && pr.GetHead() != nil {
-				commitSHA = pr.GetHead().GetSHA()
-			}
-		}
-
-		_ = s.store.UpsertRepository(&database.Repository{
-			Owner:     owner,
-			Name:      repo,
-			FullName:  event.Repository.FullName,
-			IsPrivate: event.Repository.Private,
-			IsActive:  true,
-		})
-
-		reviewRecord := &database.Review{
-			Owner:       owner,
-			Repo:        repo,
-			PRNumber:    prNumber,
-			Mode:        string(event.Command.Mode),
-			Status:      "queued",
-			QueuedAt:    time.Now(),
-			RequestedBy: event.Sender.Login,
-		}
-		if event.PullRequest != nil {
-			reviewRecord.PRTitle = event.PullRequest.Title
-			reviewRecord.CommitSHA = commitSHA
-		}
 
-		if err := s.store.CreateReview(reviewRecord); err != nil {
-			log.Warn().Err(err).Msg("Failed to create review record")
-		} else {
-			event.ReviewID = reviewRecord.ID
-			_ = s.store.CreateWebhookEvent(&database.WebhookEvent{
-				EventType:   event.EventType,
-				Owner:       owner,
-				Repo:        repo,
-				PRNumber:    prNumber,
-				Action:      event.Action,
-				ProcessedAt: ptrTime(time.Now()),
-				ReviewID:    &reviewRecord.ID,
-			})
+		if err := s.inbox.Accept(event); err != nil {
+			// Forget the key so a redelivery of this webhook is not treated as a duplicate
+			s.deduplicator.Remove(dedupKey)
+			return err
 		}
+		s.deduplicator.Complete(dedupKey, nil)
+		return nil
 	}
 
-	payload := tasks.ReviewPayload{
-		EventType:   event.EventType,
-		Action:      event.Action,
-		Owner:       owner,
-		Repo:        repo,
-		PRNumber:    prNumber,
-		CommentID:   event.Comment.ID,
-		CommentBody: event.Comment.Body,
-		SenderLogin: senderLogin,
-		Mode:        string(event.Command.Mode),
-		Verbose:     event.Command.Verbose,
-		CommitSHA:   commitSHA,
-		ReviewID:    event.ReviewID,
-	}
-
-	task, err := tasks.NewReviewTask(payload)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to build review task")
-		return err
-	}
-
-	taskID := fmt.Sprintf("review:%s/%s/%d", owner, repo, prNumber)
-	if commitSHA != "" {
-		taskID = fmt.Sprintf("%s:%s", taskID, commitSHA)
-	}
-
-	_, err = s.asynqClient.Enqueue(
-		task,
-		asynq.Queue(s.asynqQueue),
-		asynq.MaxRetry(s.config.AsynqMaxRetry),
-		asynq.TaskID(taskID),
-	)
-	if err != nil {
-		if err == asynq.ErrDuplicateTask || err == asynq.ErrTaskIDConflict {
-			log.Info().Err(err).Msg("Duplicate review task ignored")
-			if s.store != nil && event.ReviewID > 0 {
-				completedAt := time.Now()
-				_ = s.store.UpdateReview(event.ReviewID, map[string]interface{}{
-					"status":        "cancelled",
-					"error_message": "duplicate task",
-					"completed_at":  completedAt,
-					"duration_ms":   int64(0),
-				})
-			}
-			return nil
-		}
-
-		log.Error().Err(err).Msg("Failed to enqueue review task")
-		if s.store != nil && event.ReviewID > 0 {
-			completedAt := time.Now()
-			_ = s.store.UpdateReview(event.ReviewID, map[string]interface{}{
-				"status":        "failed",
-				"error_message": err.Error(),
-				"completed_at":  completedAt,
-				"duration_ms":   int64(0),
-			})
-		}
-		return err
-	}
-
-	return nil
-}
-
-func ptrTime(t time.Time) *time.Time {
-	return &t
+	return s.inbox.Accept(event)
 }
 
 func (s *Server) queueInfo() interface{} {
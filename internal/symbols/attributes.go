@@ -0,0 +1,87 @@
+package symbols
+
+import (
+	"strings"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+)
+
+// Linguist attributes that mark files as not hand-written
+var linguistAttributes = []string{"linguist-generated", "linguist-vendored"}
+
+// attributeRule is one .gitattributes line setting or unsetting a linguist attribute
+type attributeRule struct {
+	pattern   string
+	generated bool
+}
+
+// Attributes holds the linguist attributes from a repository's root .gitattributes
+type Attributes struct {
+	rules []attributeRule
+}
+
+// ParseGitattributes reads linguist-generated and linguist-vendored from
+// .gitattributes content. "attr", "attr=true" set an attribute; "-attr",
+// "attr=false" and "!attr" unset it.
+func ParseGitattributes(content string) *Attributes {
+	attrs := &Attributes{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern := strings.TrimPrefix(fields[0], "/")
+		for _, field := range fields[1:] {
+			name, value, hasValue := strings.Cut(field, "=")
+			generated := true
+			switch {
+			case strings.HasPrefix(name, "-"), strings.HasPrefix(name, "!"):
+				name, generated = name[1:], false
+			case hasValue:
+				generated = value == "true" || value == "1"
+			}
+			for _, attr := range linguistAttributes {
+				if name == attr {
+					attrs.rules = append(attrs.rules, attributeRule{pattern: pattern, generated: generated})
+				}
+			}
+		}
+	}
+	return attrs
+}
+
+// Generated reports whether the attributes mark filename as generated or
+// vendored, and whether any rule applies at all. Later lines win.
+func (a *Attributes) Generated(filename string) (generated, ok bool) {
+	if a == nil {
+		return false, false
+	}
+	for _, rule := range a.rules {
+		if gh.MatchGlob(rule.pattern, filename) {
+			generated, ok = rule.generated, true
+		}
+	}
+	return generated, ok
+}
+
+// Rules decide which changed files are left out of a review
+type Rules struct {
+	Patterns   []string    // globs from configuration, on top of the built-in list
+	Attributes *Attributes // linguist attributes; an explicit unset overrides everything else
+}
+
+// classify reports whether a path is generated by its name alone, and
+// whether that answer is final
+func (r Rules) classify(filename string) (generated, final bool) {
+	if generated, ok := r.Attributes.Generated(filename); ok {
+		return generated, true
+	}
+	return IsGeneratedPath(filename) || gh.MatchAnyGlob(r.Patterns, filename), false
+}
+
+// IsGenerated reports whether a path is generated by its name alone, for
+// files that are not part of a diff
+func (r Rules) IsGenerated(filename string) bool {
+	generated, _ := r.classify(filename)
+	return generated
+}
@@ -0,0 +1,63 @@
+package symbols
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// headerLines is how far into a file a generated-code marker is looked for
+const headerLines = 10
+
+// generatedHeader matches the markers code generators leave at the top of files,
+// e.g. Go's "// Code generated by X. DO NOT EDIT." and "@generated"
+var generatedHeader = regexp.MustCompile(`(?i)(code generated .*do not edit|@generated|autogenerated file|auto-generated by|this file was automatically generated)`)
+
+// vendoredDirs are path segments of third-party code
+var vendoredDirs = []string{"vendor", "node_modules", "third_party", "bower_components", "Pods"}
+
+// generatedSuffixes are file name endings of generated or bundled files
+var generatedSuffixes = []string{
+	".pb.go", ".pb.gw.go", "_generated.go", "_gen.go", "zz_generated.deepcopy.go", ".gen.go",
+	"_pb2.py", "_pb2_grpc.py",
+	".min.js", ".min.css", ".bundle.js", ".map",
+	".designer.cs", ".g.dart", ".freezed.dart",
+}
+
+// lockFiles are dependency lock files regenerated by package managers
+var lockFiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "go.sum": true,
+	"Cargo.lock": true, "poetry.lock": true, "Pipfile.lock": true, "composer.lock": true,
+	"Gemfile.lock": true, "uv.lock": true, "bun.lockb": true,
+}
+
+// IsGeneratedPath reports whether a path is vendored, generated or a lock file
+func IsGeneratedPath(filename string) bool {
+	for _, segment := range strings.Split(path.Dir(filename), "/") {
+		for _, dir := range vendoredDirs {
+			if segment == dir {
+				return true
+			}
+		}
+	}
+
+	base := path.Base(filename)
+	if lockFiles[base] {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGeneratedHeader reports whether the first lines of content carry a generated-code marker
+func HasGeneratedHeader(content string) bool {
+	lines := strings.SplitN(content, "\n", headerLines+1)
+	if len(lines) > headerLines {
+		lines = lines[:headerLines]
+	}
+	return generatedHeader.MatchString(strings.Join(lines, "\n"))
+}
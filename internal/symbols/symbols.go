@@ -0,0 +1,326 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+
+	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+// Symbol kinds
+const (
+	KindFunc   = "func"
+	KindMethod = "method"
+	KindType   = "type"
+	KindClass  = "class"
+)
+
+// Symbol is a named declaration spanning a range of lines
+type Symbol struct {
+	Name      string
+	Kind      string
+	StartLine int
+	EndLine   int
+}
+
+// ContentSource returns a file's post-change content, if available
+type ContentSource func(filename string) (string, bool)
+
+// Annotate fills in each file's hunks with the symbol they change and marks
+// generated files. Go files are parsed with go/ast when their content is
+// available; otherwise the hunk header context from git is used.
+func Annotate(files []models.PRFile, source ContentSource, rules Rules) {
+	for i := range files {
+		file := &files[i]
+		file.Hunks = ParseHunks(file.Patch)
+
+		generated, final := rules.classify(file.Filename)
+		var syms []Symbol
+		if source != nil && file.Status != "removed" {
+			if content, ok := source(file.Filename); ok {
+				generated = generated || (!final && HasGeneratedHeader(content))
+				syms = Extract(file.Filename, content)
+			}
+		}
+		file.Generated = generated || (!final && HasGeneratedHeader(addedHead(file.Patch)))
+
+		for j := range file.Hunks {
+			hunk := &file.Hunks[j]
+			if sym := Enclosing(syms, hunk.FirstChange); sym != nil {
+				hunk.Symbol, hunk.SymbolKind = sym.Name, sym.Kind
+				continue
+			}
+			hunk.Symbol, hunk.SymbolKind = fromHeaderContext(hunk.Context)
+		}
+	}
+}
+
+// ParseHunks splits a patch into hunks, recording the first changed line
+func ParseHunks(patch string) []models.DiffHunk {
+	var hunks []models.DiffHunk
+	var current *models.DiffHunk
+	line := 0
+	changed := false
+
+	for _, text := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(text, "@@") {
+			current = nil
+			info := gh.ParseHunkHeader(text)
+			if info == nil {
+				continue
+			}
+			hunks = append(hunks, models.DiffHunk{
+				OldStart:    info.OldStart,
+				OldLines:    info.OldLines,
+				NewStart:    info.NewStart,
+				NewLines:    info.NewLines,
+				FirstChange: info.NewStart,
+				Context:     headerContext(text),
+			})
+			current = &hunks[len(hunks)-1]
+			line = info.NewStart
+			changed = false
+			continue
+		}
+		if current == nil || strings.HasPrefix(text, "\\") {
+			continue
+		}
+
+		isChange := strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-")
+		if isChange && !changed {
+			current.FirstChange = line
+			changed = true
+		}
+		if !strings.HasPrefix(text, "-") {
+			line++
+		}
+	}
+
+	return hunks
+}
+
+// Extract returns the declarations in a file. Go is parsed with go/ast and
+// Python by indentation; other languages rely on the hunk header context.
+func Extract(filename, content string) []Symbol {
+	switch path.Ext(filename) {
+	case ".go":
+		return extractGo(filename, content)
+	case ".py":
+		return extractPython(content)
+	}
+	return nil
+}
+
+// Enclosing returns the innermost symbol containing line
+func Enclosing(syms []Symbol, line int) *Symbol {
+	var best *Symbol
+	for i := range syms {
+		s := &syms[i]
+		if line < s.StartLine || line > s.EndLine {
+			continue
+		}
+		if best == nil || s.EndLine-s.StartLine < best.EndLine-best.StartLine {
+			best = s
+		}
+	}
+	return best
+}
+
+// SymbolAt returns the symbol of the hunk containing a new-file line
+func SymbolAt(files []models.PRFile, filename string, line int) (string, string) {
+	for _, file := range files {
+		if file.Filename != filename {
+			continue
+		}
+		for _, hunk := range file.Hunks {
+			if line >= hunk.NewStart && line < hunk.NewStart+max(hunk.NewLines, 1) {
+				return hunk.Symbol, hunk.SymbolKind
+			}
+		}
+	}
+	return "", ""
+}
+
+// extractGo lists functions, methods and type declarations using go/ast
+func extractGo(filename, content string) []Symbol {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, content, parser.SkipObjectResolution)
+	if err != nil && file == nil {
+		return nil
+	}
+
+	var syms []Symbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			sym := Symbol{
+				Name:      d.Name.Name,
+				Kind:      KindFunc,
+				StartLine: fset.Position(d.Pos()).Line,
+				EndLine:   fset.Position(d.End()).Line,
+			}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				sym.Kind = KindMethod
+				if recv := receiverName(d.Recv.List[0].Type); recv != "" {
+					sym.Name = recv + "." + sym.Name
+				}
+			}
+			syms = append(syms, sym)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				start := ts.Pos()
+				if len(d.Specs) == 1 {
+					start = d.Pos()
+				}
+				syms = append(syms, Symbol{
+					Name:      ts.Name.Name,
+					Kind:      KindType,
+					StartLine: fset.Position(start).Line,
+					EndLine:   fset.Position(ts.End()).Line,
+				})
+			}
+		}
+	}
+	return syms
+}
+
+// receiverName returns the type name of a method receiver
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	}
+	return ""
+}
+
+var pythonDefPattern = regexp.MustCompile(`^(\s*)(?:async\s+)?(def|class)\s+([A-Za-z_]\w*)`)
+
+// extractPython finds def and class blocks by indentation
+func extractPython(content string) []Symbol {
+	lines := strings.Split(content, "\n")
+
+	type open struct {
+		indent int
+		index  int
+	}
+	var syms []Symbol
+	var stack []open
+	closeTo := func(indent, endLine int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			syms[stack[len(stack)-1].index].EndLine = endLine
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	lastCode := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		closeTo(indent, lastCode)
+
+		if match := pythonDefPattern.FindStringSubmatch(line); match != nil {
+			kind := KindFunc
+			if match[2] == "class" {
+				kind = KindClass
+			}
+			name := match[3]
+			if len(stack) > 0 {
+				parent := syms[stack[len(stack)-1].index]
+				name = parent.Name + "." + name
+				if parent.Kind == KindClass && kind == KindFunc {
+					kind = KindMethod
+				}
+			}
+			syms = append(syms, Symbol{Name: name, Kind: kind, StartLine: i + 1})
+			stack = append(stack, open{indent: indent, index: len(syms) - 1})
+		}
+		lastCode = i + 1
+	}
+	closeTo(0, lastCode)
+
+	return syms
+}
+
+var headerPatterns = []struct {
+	pattern *regexp.Regexp
+	kind    string
+}{
+	{regexp.MustCompile(`^func\s+\(\s*\w*\s*\*?\s*(\w+)(?:\[[^\]]*\])?\s*\)\s*(\w+)`), KindMethod},
+	{regexp.MustCompile(`^func\s+(\w+)`), KindFunc},
+	{regexp.MustCompile(`^type\s+(\w+)`), KindType},
+	{regexp.MustCompile(`^\s*(?:async\s+)?def\s+(?:self\.)?(\w+[?!]?)`), KindFunc},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`), KindClass},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`), KindFunc},
+	{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?fn\s+(\w+)`), KindFunc},
+}
+
+// fromHeaderContext derives a symbol from the text git prints after a hunk header
+func fromHeaderContext(context string) (string, string) {
+	if context == "" {
+		return "", ""
+	}
+	for _, hp := range headerPatterns {
+		match := hp.pattern.FindStringSubmatch(context)
+		if match == nil {
+			continue
+		}
+		if hp.kind == KindMethod {
+			return match[1] + "." + match[2], KindMethod
+		}
+		return match[1], hp.kind
+	}
+	return "", ""
+}
+
+// headerContext returns the text after the closing @@ of a hunk header
+func headerContext(header string) string {
+	rest := strings.TrimPrefix(header, "@@")
+	if i := strings.Index(rest, "@@"); i >= 0 {
+		return strings.TrimSpace(rest[i+2:])
+	}
+	return ""
+}
+
+// addedHead returns the first added lines of a patch, enough for a generated header
+func addedHead(patch string) string {
+	var sb strings.Builder
+	count := 0
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			if count > 0 {
+				break
+			}
+			if info := gh.ParseHunkHeader(line); info == nil || info.NewStart != 1 {
+				return ""
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			continue
+		}
+		sb.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "+"), " "))
+		sb.WriteString("\n")
+		if count++; count >= headerLines {
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,215 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/CREVIOS/revo/pkg/models"
+)
+
+const goSource = `package store
+
+type Store struct {
+	db *DB
+}
+
+func New(db *DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) Get(id int) (*Row, error) {
+	row := s.db.Find(id)
+	if row == nil {
+		return nil, ErrNotFound
+	}
+	return row, nil
+}
+`
+
+func TestAnnotate(t *testing.T) {
+	sources := map[string]string{
+		"store/store.go":      goSource,
+		"api/types.pb.go":     "package api\n",
+		"gen/client.go":       "// Code generated by oapi-codegen. DO NOT EDIT.\npackage gen\n",
+		"keep/mocks_gen.go":   "package keep\n",
+		"docs/handwritten.go": "package docs\n",
+	}
+	source := func(filename string) (string, bool) {
+		content, ok := sources[filename]
+		return content, ok
+	}
+
+	tests := []struct {
+		name          string
+		file          models.PRFile
+		rules         Rules
+		wantSymbols   []string
+		wantKinds     []string
+		wantFirst     []int
+		wantGenerated bool
+	}{
+		{
+			name: "go method from source",
+			file: models.PRFile{
+				Filename: "store/store.go",
+				Patch:    "@@ -11,4 +11,4 @@ func New(db *DB) *Store {\n func (s *Store) Get(id int) (*Row, error) {\n-\trow := s.db.Get(id)\n+\trow := s.db.Find(id)\n \tif row == nil {",
+			},
+			wantSymbols: []string{"Store.Get"},
+			wantKinds:   []string{KindMethod},
+			wantFirst:   []int{12},
+		},
+		{
+			name: "go type and function in separate hunks",
+			file: models.PRFile{
+				Filename: "store/store.go",
+				Patch:    "@@ -3,2 +3,3 @@\n type Store struct {\n+\tdb *DB\n }\n@@ -7,2 +8,2 @@ type Store struct {\n func New(db *DB) *Store {\n-\treturn &Store{}\n+\treturn &Store{db: db}",
+			},
+			wantSymbols: []string{"Store", "New"},
+			wantKinds:   []string{KindType, KindFunc},
+			wantFirst:   []int{4, 9},
+		},
+		{
+			name: "header context without source",
+			file: models.PRFile{
+				Filename: "web/app.ts",
+				Patch:    "@@ -20,3 +20,3 @@ export async function loadUser(id) {\n   const user = await fetch(id)\n-  return user\n+  return user.json()",
+			},
+			wantSymbols: []string{"loadUser"},
+			wantKinds:   []string{KindFunc},
+			wantFirst:   []int{21},
+		},
+		{
+			name: "removed file uses header context",
+			file: models.PRFile{
+				Filename: "store/store.go",
+				Status:   "removed",
+				Patch:    "@@ -1,2 +0,0 @@ func (s *Store) Close() error {\n-package store\n-",
+			},
+			wantSymbols: []string{"Store.Close"},
+			wantKinds:   []string{KindMethod},
+			wantFirst:   []int{0},
+		},
+		{
+			name:          "generated by file name",
+			file:          models.PRFile{Filename: "api/types.pb.go", Patch: "@@ -1 +1 @@\n-package x\n+package api"},
+			wantSymbols:   []string{""},
+			wantKinds:     []string{""},
+			wantFirst:     []int{1},
+			wantGenerated: true,
+		},
+		{
+			name:          "generated by header in content",
+			file:          models.PRFile{Filename: "gen/client.go", Patch: "@@ -2 +2 @@\n-package x\n+package gen"},
+			wantSymbols:   []string{""},
+			wantKinds:     []string{""},
+			wantFirst:     []int{2},
+			wantGenerated: true,
+		},
+		{
+			name:          "generated by header in patch",
+			file:          models.PRFile{Filename: "new/client.go", Status: "added", Patch: "@@ -0,0 +1,2 @@\n+// @generated\n+package new"},
+			wantSymbols:   []string{""},
+			wantKinds:     []string{""},
+			wantFirst:     []int{1},
+			wantGenerated: true,
+		},
+		{
+			name:          "generated by configured pattern",
+			file:          models.PRFile{Filename: "docs/handwritten.go", Patch: "@@ -1 +1 @@\n-package x\n+package docs"},
+			rules:         Rules{Patterns: []string{"docs/**"}},
+			wantSymbols:   []string{""},
+			wantKinds:     []string{""},
+			wantFirst:     []int{1},
+			wantGenerated: true,
+		},
+		{
+			name:          "gitattributes unset overrides the built-in list",
+			file:          models.PRFile{Filename: "keep/mocks_gen.go", Patch: "@@ -1 +1 @@\n-package x\n+package keep"},
+			rules:         Rules{Attributes: ParseGitattributes("keep/** -linguist-generated\n")},
+			wantSymbols:   []string{""},
+			wantKinds:     []string{""},
+			wantFirst:     []int{1},
+			wantGenerated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []models.PRFile{tt.file}
+			Annotate(files, source, tt.rules)
+			file := files[0]
+
+			var names, kinds []string
+			var first []int
+			for _, hunk := range file.Hunks {
+				names = append(names, hunk.Symbol)
+				kinds = append(kinds, hunk.SymbolKind)
+				first = append(first, hunk.FirstChange)
+			}
+			if !reflect.DeepEqual(names, tt.wantSymbols) || !reflect.DeepEqual(kinds, tt.wantKinds) {
+				t.Errorf("symbols = %q %q, want %q %q", names, kinds, tt.wantSymbols, tt.wantKinds)
+			}
+			if !reflect.DeepEqual(first, tt.wantFirst) {
+				t.Errorf("first changes = %v, want %v", first, tt.wantFirst)
+			}
+			if file.Generated != tt.wantGenerated {
+				t.Errorf("Generated = %v, want %v", file.Generated, tt.wantGenerated)
+			}
+		})
+	}
+}
+
+func TestExtractPython(t *testing.T) {
+	content := `import os
+
+class Config:
+    def __init__(self, path):
+        self.path = path
+
+    async def load(self):
+        return os.read(self.path)
+
+def main():
+    cfg = Config("x")
+
+    def inner():
+        pass
+    return cfg
+`
+	want := []Symbol{
+		{Name: "Config", Kind: KindClass, StartLine: 3, EndLine: 8},
+		{Name: "Config.__init__", Kind: KindMethod, StartLine: 4, EndLine: 5},
+		{Name: "Config.load", Kind: KindMethod, StartLine: 7, EndLine: 8},
+		{Name: "main", Kind: KindFunc, StartLine: 10, EndLine: 15},
+		{Name: "main.inner", Kind: KindFunc, StartLine: 13, EndLine: 14},
+	}
+	if got := Extract("app.py", content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromHeaderContext(t *testing.T) {
+	tests := []struct {
+		context  string
+		wantName string
+		wantKind string
+	}{
+		{"", "", ""},
+		{"func (r *Reviewer) ProcessReview(ctx context.Context) error {", "Reviewer.ProcessReview", KindMethod},
+		{"func (l List[T]) Len() int {", "List.Len", KindMethod},
+		{"func main() {", "main", KindFunc},
+		{"type Config struct {", "Config", KindType},
+		{"    def handle(self, event):", "handle", KindFunc},
+		{"export default class App extends Component {", "App", KindClass},
+		{"pub(crate) async fn run(&self) {", "run", KindFunc},
+		{"import (", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			name, kind := fromHeaderContext(tt.context)
+			if name != tt.wantName || kind != tt.wantKind {
+				t.Errorf("fromHeaderContext() = %q, %q, want %q, %q", name, kind, tt.wantName, tt.wantKind)
+			}
+		})
+	}
+}
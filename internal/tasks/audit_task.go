@@ -0,0 +1,84 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/hibiken/asynq"
+)
+
+const TypeAudit = "review:audit"
+
+// AuditPayload is the task payload for a scheduled audit of a repository's default branch.
+type AuditPayload struct {
+	Owner string   `json:"owner"`
+	Repo  string   `json:"repo"`
+	Kind  string   `json:"kind"`            // security or dependencies
+	Paths []string `json:"paths,omitempty"` // directories to audit; empty for the whole repository
+}
+
+// AuditSchedule is an audit enqueued on a cron schedule
+type AuditSchedule struct {
+	Cron    string
+	Payload AuditPayload
+}
+
+func NewAuditTask(payload AuditPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeAudit, data), nil
+}
+
+func ParseAuditTask(task *asynq.Task) (AuditPayload, error) {
+	var payload AuditPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return AuditPayload{}, err
+	}
+	return payload, nil
+}
+
+// ParseAuditSchedules reads "cron|owner/repo|kind|dir,dir" audits separated by
+// semicolons, e.g. "0 3 * * 1|acme/api|security|cmd,internal". The directory
+// list may be left empty to audit the whole repository.
+func ParseAuditSchedules(spec string) ([]AuditSchedule, error) {
+	var schedules []AuditSchedule
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, "|")
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("audit schedule %q: want cron|owner/repo|kind|dirs", entry)
+		}
+		cron := strings.TrimSpace(fields[0])
+		owner, repo, ok := strings.Cut(strings.TrimSpace(fields[1]), "/")
+		if cron == "" || !ok || owner == "" || repo == "" {
+			return nil, fmt.Errorf("audit schedule %q: want cron|owner/repo|kind|dirs", entry)
+		}
+		kind := strings.ToLower(strings.TrimSpace(fields[2]))
+		if kind != models.AuditSecurity && kind != models.AuditDependencies {
+			return nil, fmt.Errorf("audit schedule %q: kind must be %s or %s", entry, models.AuditSecurity, models.AuditDependencies)
+		}
+
+		var paths []string
+		if len(fields) == 4 {
+			for _, dir := range strings.Split(fields[3], ",") {
+				if dir = strings.Trim(strings.TrimSpace(dir), "/"); dir != "" {
+					paths = append(paths, dir)
+				}
+			}
+		}
+
+		schedules = append(schedules, AuditSchedule{
+			Cron:    cron,
+			Payload: AuditPayload{Owner: owner, Repo: repo, Kind: kind, Paths: paths},
+		})
+	}
+	return schedules, nil
+}
@@ -0,0 +1,30 @@
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeWebhookIngest = "webhook:ingest"
+
+// IngestPayload is the task payload for processing a persisted webhook event.
+type IngestPayload struct {
+	WebhookEventID uint `json:"webhook_event_id"`
+}
+
+func NewIngestTask(payload IngestPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeWebhookIngest, data), nil
+}
+
+func ParseIngestTask(task *asynq.Task) (IngestPayload, error) {
+	var payload IngestPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return IngestPayload{}, err
+	}
+	return payload, nil
+}
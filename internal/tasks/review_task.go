@@ -3,6 +3,7 @@ package tasks
 import (
 	"encoding/json"
 
+	"github.com/CREVIOS/revo/pkg/models"
 	"github.com/hibiken/asynq"
 )
 
@@ -22,6 +23,9 @@ type ReviewPayload struct {
 	Verbose     bool   `json:"verbose"`
 	CommitSHA   string `json:"commit_sha"`
 	ReviewID    uint   `json:"review_id"`
+
+	Options models.CommandOptions `json:"options"`
+	Target  *models.ReviewTarget  `json:"target,omitempty"`
 }
 
 func NewReviewTask(payload ReviewPayload) (*asynq.Task, error) {
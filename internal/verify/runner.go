@@ -0,0 +1,213 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultImages maps test languages to container images
+var DefaultImages = map[string]string{
+	"go":         "golang:1.24",
+	"python":     "python:3.12-slim",
+	"javascript": "node:22-slim",
+	"typescript": "node:22-slim",
+	"ruby":       "ruby:3.3-slim",
+	"rust":       "rust:1-slim",
+	"java":       "maven:3-eclipse-temurin-21",
+}
+
+// RunnerConfig limits the container a test runs in
+type RunnerConfig struct {
+	DockerPath string
+	Images     map[string]string // language -> image; DefaultImages when empty
+	Timeout    time.Duration
+	MemoryMB   int
+	CPUs       string
+	PidsLimit  int
+	Network    string // docker network mode, "none" for no network
+
+	// Go modules are not in the checkout, and tests run without network. They
+	// come from GoModCache, a pre-warmed module cache mounted read-only, or
+	// otherwise from a go mod download run with DownloadNetwork per checkout.
+	GoModCache      string
+	DownloadNetwork string // docker network mode for downloads; "none" disables them
+}
+
+// goModCachePath is where the module cache is mounted in containers
+const goModCachePath = "/go/pkg/mod"
+
+// Runner executes commands in throwaway containers with the checkout mounted
+type Runner struct {
+	config RunnerConfig
+}
+
+// RunResult is the outcome of one container run
+type RunResult struct {
+	Image    string
+	Output   string
+	ExitCode int
+	TimedOut bool
+	Err      error // the container could not be started
+}
+
+// NewRunner creates a new container runner
+func NewRunner(config RunnerConfig) *Runner {
+	if config.DockerPath == "" {
+		config.DockerPath = "docker"
+	}
+	if len(config.Images) == 0 {
+		config.Images = DefaultImages
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Minute
+	}
+	if config.MemoryMB <= 0 {
+		config.MemoryMB = 1024
+	}
+	if config.CPUs == "" {
+		config.CPUs = "1"
+	}
+	if config.PidsLimit <= 0 {
+		config.PidsLimit = 256
+	}
+	if config.Network == "" {
+		config.Network = "none"
+	}
+	if config.DownloadNetwork == "" {
+		config.DownloadNetwork = "none"
+	}
+	return &Runner{config: config}
+}
+
+// NeedsDownload reports whether tests in language need their dependencies
+// downloaded into a module cache for the checkout in dir first
+func (r *Runner) NeedsDownload(dir, language string) bool {
+	if language != "go" || r.config.GoModCache != "" || r.config.DownloadNetwork == "none" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// Download fills modCache with the Go modules the checkout in dir requires.
+// Only this step has network access; the checkout is mounted read-only.
+func (r *Runner) Download(ctx context.Context, dir, modCache string) RunResult {
+	image, ok := r.config.Images["go"]
+	if !ok {
+		return RunResult{Err: fmt.Errorf("no image configured for language %q", "go")}
+	}
+	return r.run(ctx, image, r.config.DownloadNetwork, []string{
+		"--volume", dir + ":/work:ro",
+		"--volume", modCache + ":" + goModCachePath + ":rw",
+		"--env", "GOMODCACHE=" + goModCachePath,
+		"--env", "GOFLAGS=-mod=mod -modcacherw", // writable, so the workspace can be removed
+	}, "go mod download")
+}
+
+// Run executes command with dir mounted at /work. The container has no
+// network by default, a read-only root filesystem, dropped capabilities and
+// memory, CPU and process limits. Go modules are read from the configured
+// cache or from modCache, filled by Download.
+func (r *Runner) Run(ctx context.Context, dir, modCache, language, command string) RunResult {
+	image, ok := r.config.Images[language]
+	if !ok {
+		return RunResult{Err: fmt.Errorf("no image configured for language %q", language)}
+	}
+
+	mounts := []string{
+		"--volume", dir + ":/work:rw",
+		"--env", "GOFLAGS=-mod=mod",
+		"--env", "GOPROXY=off",
+		"--env", "GOSUMDB=off", // modules come from the cache, checked when downloaded
+	}
+	if r.config.GoModCache != "" {
+		modCache = r.config.GoModCache
+	}
+	if modCache != "" {
+		mounts = append(mounts,
+			"--volume", modCache+":"+goModCachePath+":ro",
+			"--env", "GOMODCACHE="+goModCachePath,
+		)
+	}
+	return r.run(ctx, image, r.config.Network, mounts, command)
+}
+
+// run starts a limited container with the given mounts and environment
+func (r *Runner) run(ctx context.Context, image, network string, mounts []string, command string) RunResult {
+	runCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	name := fmt.Sprintf("techy-verify-%d", time.Now().UnixNano())
+	args := []string{
+		"run", "--rm", "--name", name,
+		"--network", network,
+		"--memory", fmt.Sprintf("%dm", r.config.MemoryMB),
+		"--memory-swap", fmt.Sprintf("%dm", r.config.MemoryMB),
+		"--cpus", r.config.CPUs,
+		"--pids-limit", fmt.Sprint(r.config.PidsLimit),
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--read-only",
+		"--tmpfs", "/tmp:rw,exec,size=512m",
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), // the checkout is only readable by us
+		"--env", "HOME=/tmp",
+		"--env", "GOCACHE=/tmp/go-cache",
+	}
+	args = append(args, mounts...)
+	args = append(args,
+		"--workdir", "/work",
+		image,
+		"sh", "-c", command,
+	)
+
+	cmd := exec.CommandContext(runCtx, r.config.DockerPath, args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	result := RunResult{Image: image, Output: strings.TrimSpace(output.String())}
+
+	if runCtx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+		// The CLI being killed does not stop the container
+		_ = exec.Command(r.config.DockerPath, "rm", "-f", name).Run()
+		return result
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		// docker run exits 125-127 when the container itself could not run
+		if result.ExitCode >= 125 && result.ExitCode <= 127 && !strings.Contains(result.Output, Marker) {
+			result.Err = fmt.Errorf("docker exited with %d", result.ExitCode)
+		}
+	default:
+		result.Err = err
+	}
+	return result
+}
+
+// ParseImages reads "language=image" pairs separated by commas on top of DefaultImages
+func ParseImages(spec string) map[string]string {
+	images := make(map[string]string, len(DefaultImages))
+	for language, image := range DefaultImages {
+		images[language] = image
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		language, image, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && language != "" && image != "" {
+			images[strings.ToLower(strings.TrimSpace(language))] = strings.TrimSpace(image)
+		}
+	}
+	return images
+}
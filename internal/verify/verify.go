@@ -0,0 +1,341 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/CREVIOS/revo/internal/sandbox"
+	"github.com/CREVIOS/revo/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Verification outcomes stored on review comments
+const (
+	StatusVerified      = "verified"       // the generated test failed with the reproduction marker
+	StatusNotReproduced = "not_reproduced" // the test ran and passed
+	StatusInconclusive  = "inconclusive"   // no test, a build error, a timeout or a runner failure
+)
+
+// Modes decide what happens to findings that could not be reproduced
+const (
+	ModeAnnotate = "annotate" // post everything, mark reproduced findings as verified
+	ModeFilter   = "filter"   // drop checked findings that were not reproduced
+)
+
+// Marker is printed by a generated test when its assertion fails, so a
+// compile error or missing dependency is not mistaken for a reproduction
+const Marker = "TECHY_BUG_REPRODUCED"
+
+// maxOutput bounds the stored verification output
+const maxOutput = 8000
+
+// Generator asks the model for a test inside the checkout
+type Generator interface {
+	Prompt(ctx context.Context, prompt, model, workDir, tmpDir string) (string, error)
+}
+
+// Config configures bug verification
+type Config struct {
+	Mode        string // annotate or filter
+	MinSeverity string // only findings at this severity or above are checked
+	MaxFindings int    // findings checked per review
+	Runner      RunnerConfig
+}
+
+// Verifier reproduces findings by generating and running failing tests
+type Verifier struct {
+	generator Generator
+	runner    *Runner
+	config    Config
+}
+
+// NewVerifier creates a new verifier
+func NewVerifier(generator Generator, config Config) *Verifier {
+	if config.Mode == "" {
+		config.Mode = ModeAnnotate
+	}
+	if config.MinSeverity == "" {
+		config.MinSeverity = "error"
+	}
+	return &Verifier{
+		generator: generator,
+		runner:    NewRunner(config.Runner),
+		config:    config,
+	}
+}
+
+// Verify checks eligible findings against the workspace, records the outcome
+// on each comment and returns the comments to post
+func (v *Verifier) Verify(ctx context.Context, ws *sandbox.Workspace, request *models.ReviewRequest, comments []models.ReviewComment) []models.ReviewComment {
+	deps := &dependencies{}
+	checked := 0
+	for i := range comments {
+		c := &comments[i]
+		if severityRank(c.Severity) < severityRank(v.config.MinSeverity) {
+			continue
+		}
+		if v.config.MaxFindings > 0 && checked >= v.config.MaxFindings {
+			break
+		}
+		checked++
+
+		status, output := v.verifyOne(ctx, ws, request, *c, deps)
+		c.Verification = status
+		c.VerificationOutput = truncateOutput(output)
+
+		log.Info().
+			Str("file", c.Path).
+			Int("line", c.Line).
+			Str("verification", status).
+			Msg("Verified finding")
+
+		if status == StatusVerified {
+			c.Body = strings.TrimSpace(c.Body) + "\n\n✅ **Verified:** reproduced by a generated test in an isolated sandbox."
+		}
+	}
+
+	if v.config.Mode != ModeFilter {
+		return comments
+	}
+
+	kept := comments[:0]
+	for _, c := range comments {
+		if c.Verification == "" || c.Verification == StatusVerified {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// dependencies is the module cache downloaded for one workspace, shared by
+// the findings verified in it
+type dependencies struct {
+	modCache   string
+	downloaded bool
+	failure    string // why the download failed
+}
+
+// prepare downloads the dependencies a test needs, once per workspace
+func (v *Verifier) prepare(ctx context.Context, ws *sandbox.Workspace, language string, deps *dependencies) error {
+	if deps.downloaded || !v.runner.NeedsDownload(ws.Dir, language) {
+		if deps.failure != "" {
+			return errors.New(deps.failure)
+		}
+		return nil
+	}
+	deps.downloaded = true
+
+	dir, err := ws.Scratch("gomodcache-")
+	if err != nil {
+		deps.failure = fmt.Sprintf("failed to create module cache: %v", err)
+		return errors.New(deps.failure)
+	}
+	result := v.runner.Download(ctx, ws.Dir, dir)
+	switch {
+	case result.Err != nil:
+		deps.failure = fmt.Sprintf("dependency download failed: %v", result.Err)
+	case result.TimedOut:
+		deps.failure = "dependency download timed out"
+	case result.ExitCode != 0:
+		deps.failure = "dependency download failed:\n" + result.Output
+	default:
+		deps.modCache = dir
+		return nil
+	}
+	return errors.New(deps.failure)
+}
+
+// verifyOne generates a test for a finding, runs it and classifies the result
+func (v *Verifier) verifyOne(ctx context.Context, ws *sandbox.Workspace, request *models.ReviewRequest, c models.ReviewComment, deps *dependencies) (string, string) {
+	response, err := v.generator.Prompt(ctx, buildPrompt(request, c), request.Command.Options.Model, ws.Dir, ws.TmpDir)
+	if err != nil {
+		return StatusInconclusive, fmt.Sprintf("test generation failed: %v", err)
+	}
+
+	test, reason := parseTest(response)
+	if test == nil {
+		return StatusInconclusive, "no test generated: " + reason
+	}
+
+	if existing, err := safeJoin(ws.Dir, test.File); err != nil {
+		return StatusInconclusive, err.Error()
+	} else if _, err := os.Stat(existing); err == nil {
+		return StatusInconclusive, fmt.Sprintf("refusing to overwrite existing file %s", test.File)
+	}
+
+	if err := v.prepare(ctx, ws, test.Language, deps); err != nil {
+		return StatusInconclusive, err.Error()
+	}
+
+	// Each test runs in its own copy, so nothing it or its build writes
+	// reaches the checkout other findings are verified against
+	runDir, err := ws.Scratch("verify-")
+	if err != nil {
+		return StatusInconclusive, fmt.Sprintf("failed to create test directory: %v", err)
+	}
+	defer os.RemoveAll(runDir)
+	if err := copyDir(ws.Dir, runDir); err != nil {
+		return StatusInconclusive, fmt.Sprintf("failed to copy checkout: %v", err)
+	}
+
+	testPath, err := safeJoin(runDir, test.File)
+	if err != nil {
+		return StatusInconclusive, err.Error()
+	}
+	if err := os.MkdirAll(filepath.Dir(testPath), 0o755); err != nil {
+		return StatusInconclusive, fmt.Sprintf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(testPath, []byte(test.Code), 0o644); err != nil {
+		return StatusInconclusive, fmt.Sprintf("failed to write test: %v", err)
+	}
+
+	result := v.runner.Run(ctx, runDir, deps.modCache, test.Language, test.Command)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("$ %s  # %s, %s\n", test.Command, test.File, result.Image))
+	sb.WriteString(result.Output)
+	output := sb.String() + "\n--- " + test.File + " ---\n" + test.Code
+
+	switch {
+	case result.Err != nil:
+		return StatusInconclusive, output + fmt.Sprintf("\n\nrunner error: %v", result.Err)
+	case result.TimedOut:
+		return StatusInconclusive, output + "\n\ntimed out"
+	case result.ExitCode != 0 && strings.Contains(result.Output, Marker):
+		return StatusVerified, output
+	case result.ExitCode == 0:
+		return StatusNotReproduced, output
+	default:
+		return StatusInconclusive, output
+	}
+}
+
+// Test is a generated reproduction
+type Test struct {
+	Language string
+	File     string
+	Command  string
+	Code     string
+}
+
+var codeBlockPattern = regexp.MustCompile("(?s)```[a-zA-Z0-9_+-]*\n(.*?)```")
+
+// parseTest reads the LANGUAGE, FILE and COMMAND headers and the code block,
+// or returns the reason given with CANNOT_REPRODUCE
+func parseTest(response string) (*Test, string) {
+	test := &Test{}
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "CANNOT_REPRODUCE:"):
+			return nil, strings.TrimSpace(strings.TrimPrefix(trimmed, "CANNOT_REPRODUCE:"))
+		case strings.HasPrefix(trimmed, "LANGUAGE:") && test.Language == "":
+			test.Language = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "LANGUAGE:")))
+		case strings.HasPrefix(trimmed, "FILE:") && test.File == "":
+			test.File = strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "FILE:")), "`")
+		case strings.HasPrefix(trimmed, "COMMAND:") && test.Command == "":
+			test.Command = strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "COMMAND:")), "`")
+		}
+	}
+
+	if match := codeBlockPattern.FindStringSubmatch(response); match != nil {
+		test.Code = match[1]
+	}
+
+	if test.File == "" || test.Command == "" || strings.TrimSpace(test.Code) == "" {
+		return nil, "response did not contain FILE, COMMAND and a code block"
+	}
+	return test, ""
+}
+
+// buildPrompt asks for a minimal test that fails only while the bug exists
+func buildPrompt(request *models.ReviewRequest, c models.ReviewComment) string {
+	var sb strings.Builder
+	sb.WriteString("You are verifying a bug reported by a code reviewer. The repository is checked out at the pull request's head commit in your working directory; read the code as needed.\n\n")
+	sb.WriteString(fmt.Sprintf("## Reported bug\n\n**Location:** `%s:%d`\n\n%s\n\n", c.Path, c.Line, strings.TrimSpace(c.Body)))
+	if request.PRTitle != "" {
+		sb.WriteString(fmt.Sprintf("**Pull request:** %s\n\n", request.PRTitle))
+	}
+	sb.WriteString("## Task\n\n")
+	sb.WriteString("Write ONE minimal, self-contained test in the repository's language and test framework that FAILS while the bug exists and would pass once it is fixed.\n")
+	sb.WriteString(fmt.Sprintf("- When the assertion that demonstrates the bug fails, the test must print `%s` before failing.\n", Marker))
+	sb.WriteString("- The test runs offline in a container with only the repository, its Go module dependencies and the language's standard toolchain; do not rely on network access or services.\n")
+	sb.WriteString("- Put it in a NEW file next to the code under test, with a name that does not exist yet.\n")
+	sb.WriteString("- The command runs from the repository root and must run only this test.\n\n")
+	sb.WriteString("Respond in exactly this format:\n\n")
+	sb.WriteString("LANGUAGE: <go|python|javascript|typescript|...>\nFILE: <path relative to the repository root>\nCOMMAND: <shell command>\n```\n<test code>\n```\n\n")
+	sb.WriteString("If the bug cannot be demonstrated with such a test, or you believe it is not a real bug, respond with a single line:\nCANNOT_REPRODUCE: <reason>\n")
+	return sb.String()
+}
+
+// safeJoin resolves a repository-relative path, refusing paths outside root
+func safeJoin(root, rel string) (string, error) {
+	if rel == "" || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("invalid test path %q", rel)
+	}
+	joined := filepath.Join(root, filepath.FromSlash(rel))
+	if !strings.HasPrefix(joined, filepath.Clean(root)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("test path %q escapes the checkout", rel)
+	}
+	return joined, nil
+}
+
+// copyDir copies the directories and regular files under src into dst
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		default:
+			return nil // the checkout has no symlinks; skip anything else
+		}
+	})
+}
+
+// truncateOutput keeps the start and end of long output
+func truncateOutput(output string) string {
+	if len(output) <= maxOutput {
+		return output
+	}
+	half := maxOutput / 2
+	return strings.ToValidUTF8(output[:half]+"\n... (truncated) ...\n"+output[len(output)-half:], "")
+}
+
+// severityRank orders severities so they can be compared
+func severityRank(severity string) int {
+	switch severity {
+	case "error":
+		return 3
+	case "warning":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,168 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeJoin(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "run")
+
+	tests := []struct {
+		rel     string
+		want    string // relative to root; empty when the path is refused
+		message string
+	}{
+		{rel: "pkg/x_test.go", want: "pkg/x_test.go"},
+		{rel: "./pkg/./x_test.go", want: "pkg/x_test.go"},
+		{rel: "pkg/sub/../x_test.go", want: "pkg/x_test.go"},
+		{rel: "..foo/x_test.go", want: "..foo/x_test.go"},
+		{rel: "~/x_test.go", want: "~/x_test.go"},
+		{rel: "pkg/link -> ../../x_test.go", want: "pkg/x_test.go"},
+		{rel: "", message: "invalid test path"},
+		{rel: "/etc/passwd", message: "invalid test path"},
+		{rel: "..", message: "escapes the checkout"},
+		{rel: ".", message: "escapes the checkout"},
+		{rel: "pkg/..", message: "escapes the checkout"},
+		{rel: "../x_test.go", message: "escapes the checkout"},
+		{rel: "pkg/../../x_test.go", message: "escapes the checkout"},
+		{rel: "../run2/x_test.go", message: "escapes the checkout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			got, err := safeJoin(root, tt.rel)
+			if tt.message != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Errorf("safeJoin(%q) = %q, %v, want error %q", tt.rel, got, err, tt.message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("safeJoin(%q) error = %v", tt.rel, err)
+			}
+			if want := filepath.Join(root, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("safeJoin(%q) = %q, want %q", tt.rel, got, want)
+			}
+		})
+	}
+}
+
+func TestCopyDirSkipsSymlinks(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "run")
+	if err := os.MkdirAll(filepath.Join(src, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "pkg", "a.go"), []byte("package pkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(t.TempDir(), filepath.Join(src, "pkg", "outside")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if err := copyDir(src, dst); err != nil {
+		t.Fatalf("copyDir() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "pkg", "a.go")); err != nil || string(data) != "package pkg\n" {
+		t.Errorf("copied file = %q, %v", data, err)
+	}
+	// A test written through the link must not land outside the copy
+	if _, err := os.Lstat(filepath.Join(dst, "pkg", "outside")); !os.IsNotExist(err) {
+		t.Errorf("symlink was copied: %v", err)
+	}
+}
+
+func TestParseTest(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     *Test
+		reason   string
+	}{
+		{
+			name: "complete",
+			response: "LANGUAGE: Go\nFILE: `pkg/retry_bug_test.go`\nCOMMAND: `go test ./pkg -run TestRetryBug`\n" +
+				"```go\npackage pkg\n\nfunc TestRetryBug(t *testing.T) {}\n```\n",
+			want: &Test{
+				Language: "go",
+				File:     "pkg/retry_bug_test.go",
+				Command:  "go test ./pkg -run TestRetryBug",
+				Code:     "package pkg\n\nfunc TestRetryBug(t *testing.T) {}\n",
+			},
+		},
+		{
+			name:     "indented fields and an untagged block",
+			response: "Here is the test:\n  FILE: a_test.py\n  COMMAND: pytest a_test.py\n```\ndef test_a():\n    assert False\n```",
+			want: &Test{
+				File:    "a_test.py",
+				Command: "pytest a_test.py",
+				Code:    "def test_a():\n    assert False\n",
+			},
+		},
+		{
+			name:     "first field wins",
+			response: "FILE: a_test.go\nFILE: b_test.go\nCOMMAND: go test -run A\nCOMMAND: go test ./...\n```go\npackage a\n```",
+			want:     &Test{File: "a_test.go", Command: "go test -run A", Code: "package a\n"},
+		},
+		{
+			name:     "cannot reproduce",
+			response: "CANNOT_REPRODUCE:  the retry is bounded by the caller \n",
+			reason:   "the retry is bounded by the caller",
+		},
+		{
+			name:     "cannot reproduce after a partial test",
+			response: "FILE: a_test.go\nCOMMAND: go test\nCANNOT_REPRODUCE: needs a database\n```go\npackage a\n```",
+			reason:   "needs a database",
+		},
+		{
+			name:     "empty response",
+			response: "",
+			reason:   "response did not contain FILE, COMMAND and a code block",
+		},
+		{
+			name:     "missing file",
+			response: "COMMAND: go test\n```go\npackage a\n```",
+			reason:   "response did not contain FILE, COMMAND and a code block",
+		},
+		{
+			name:     "missing command",
+			response: "FILE: a_test.go\n```go\npackage a\n```",
+			reason:   "response did not contain FILE, COMMAND and a code block",
+		},
+		{
+			name:     "unterminated code block",
+			response: "FILE: a_test.go\nCOMMAND: go test\n```go\npackage a\n",
+			reason:   "response did not contain FILE, COMMAND and a code block",
+		},
+		{
+			name:     "blank code block",
+			response: "FILE: a_test.go\nCOMMAND: go test\n```go\n   \n```",
+			reason:   "response did not contain FILE, COMMAND and a code block",
+		},
+		{
+			name:     "empty field values",
+			response: "FILE:\nCOMMAND: ``\n```go\npackage a\n```",
+			reason:   "response did not contain FILE, COMMAND and a code block",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := parseTest(tt.response)
+			if reason != tt.reason {
+				t.Errorf("parseTest() reason = %q, want %q", reason, tt.reason)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("parseTest() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("parseTest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,9 @@ import (
 	contextaware "github.com/CREVIOS/revo/internal/context"
 	"github.com/CREVIOS/revo/internal/database"
 	gh "github.com/CREVIOS/revo/internal/github"
+	"github.com/CREVIOS/revo/internal/inbox"
+	"github.com/CREVIOS/revo/internal/modes"
+	"github.com/CREVIOS/revo/internal/policy"
 	"github.com/CREVIOS/revo/internal/ratelimit"
 	"github.com/CREVIOS/revo/internal/retry"
 	"github.com/CREVIOS/revo/internal/review"
@@ -20,6 +23,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// auditUniqueTTL keeps the schedulers of several workers from enqueueing the same audit run twice
+const auditUniqueTTL = 10 * time.Minute
+
 // Run starts the background worker for processing review tasks.
 func Run(cfg *models.Config) error {
 	db, err := database.Connect(cfg.DatabaseURL)
@@ -28,6 +34,11 @@ func Run(cfg *models.Config) error {
 	}
 	store := database.NewStore(db)
 
+	if err := modes.Load(store); err != nil {
+		log.Warn().Err(err).Msg("Failed to load custom modes")
+	}
+	modes.StartRefresh(context.Background(), store, modes.DefaultRefreshInterval)
+
 	githubClient := gh.NewClient(cfg.GitHubAppID, cfg.GitHubPrivateKey)
 
 	// Initialize Claude client with retry and caching
@@ -52,10 +63,7 @@ func Run(cfg *models.Config) error {
 	contextAnalyzer := contextaware.NewContextAwareAnalyzer(githubClient, cfg.BotUsername)
 	rateLimiter := ratelimit.NewLimiter(cfg.RateLimitMaxTokens, time.Duration(cfg.RateLimitRefillSec)*time.Second)
 
-	reviewer := review.NewReviewer(githubClient, claudeClient, cfg.MaxDiffSize)
-	reviewer.SetContextAnalyzer(contextAnalyzer)
-	reviewer.SetRateLimiter(rateLimiter)
-	reviewer.SetStore(store)
+	reviewer := review.NewFromConfig(cfg, githubClient, claudeClient, store, contextAnalyzer, rateLimiter)
 
 	redisOpt := asynq.RedisClientOpt{
 		Addr:     cfg.RedisAddr,
@@ -70,7 +78,24 @@ func Run(cfg *models.Config) error {
 		},
 	})
 
+	asynqClient := asynq.NewClient(redisOpt)
+	defer asynqClient.Close()
+
+	inspector := asynq.NewInspector(redisOpt)
+	defer inspector.Close()
+
+	ingest := inbox.New(store, githubClient, asynqClient, cfg.BotUsername, cfg.AsynqQueue, cfg.AsynqMaxRetry)
+	ingest.SetInspector(inspector)
+	ingest.SetPolicy(policy.NewChecker(githubClient, store, policy.Config{
+		Enabled:         cfg.AuthEnabled,
+		MinPermission:   cfg.AuthMinPermission,
+		AllowOrgMembers: cfg.AuthAllowOrgMembers,
+		RequireApproval: cfg.AuthRequireApproval,
+	}))
+	ingest.SetMigrationPaths(cfg.MigrationPaths)
+
 	mux := asynq.NewServeMux()
+	mux.HandleFunc(tasks.TypeWebhookIngest, ingest.Process)
 	mux.HandleFunc(tasks.TypeReview, func(ctx context.Context, task *asynq.Task) error {
 		payload, err := tasks.ParseReviewTask(task)
 		if err != nil {
@@ -102,12 +127,49 @@ func Run(cfg *models.Config) error {
 				Mode:    mode,
 				Verbose: payload.Verbose,
 				Raw:     "@" + cfg.BotUsername + " " + payload.Mode,
+				Options: payload.Options,
 			},
 			ReviewID: payload.ReviewID,
+			Target:   payload.Target,
 		}
 
 		return reviewer.ProcessReview(ctx, event)
 	})
+	mux.HandleFunc(tasks.TypeAudit, func(ctx context.Context, task *asynq.Task) error {
+		payload, err := tasks.ParseAuditTask(task)
+		if err != nil {
+			return fmt.Errorf("invalid audit payload: %v: %w", err, asynq.SkipRetry)
+		}
+		return reviewer.ProcessAudit(ctx, payload.Owner, payload.Repo, payload.Kind, payload.Paths)
+	})
+
+	// Enqueue scheduled audits. Every worker runs the scheduler, so the tasks
+	// are unique for a while to enqueue each run once.
+	schedules, err := tasks.ParseAuditSchedules(cfg.AuditSchedules)
+	if err != nil {
+		return fmt.Errorf("invalid AUDIT_SCHEDULES: %w", err)
+	}
+	if len(schedules) > 0 {
+		scheduler := asynq.NewScheduler(redisOpt, nil)
+		for _, schedule := range schedules {
+			task, err := tasks.NewAuditTask(schedule.Payload)
+			if err != nil {
+				return fmt.Errorf("failed to build audit task: %w", err)
+			}
+			if _, err := scheduler.Register(schedule.Cron, task,
+				asynq.Queue(cfg.AsynqQueue),
+				asynq.MaxRetry(cfg.AsynqMaxRetry),
+				asynq.Unique(auditUniqueTTL),
+			); err != nil {
+				return fmt.Errorf("failed to schedule audit of %s/%s: %w", schedule.Payload.Owner, schedule.Payload.Repo, err)
+			}
+		}
+		if err := scheduler.Start(); err != nil {
+			return fmt.Errorf("failed to start audit scheduler: %w", err)
+		}
+		defer scheduler.Shutdown()
+		log.Info().Int("audits", len(schedules)).Msg("Audit scheduler started")
+	}
 
 	log.Info().
 		Int("concurrency", cfg.AsynqConcurrency).
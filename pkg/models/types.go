@@ -1,6 +1,10 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
 	"time"
 )
 
@@ -13,13 +17,107 @@ const (
 	ModeSecurity    ReviewMode = "security"
 	ModePerformance ReviewMode = "performance"
 	ModeAnalyze     ReviewMode = "analyze"
+	ModeSummarize   ReviewMode = "summarize"
+	ModeTests       ReviewMode = "tests"
+	ModeMigrations  ReviewMode = "migrations"
+	ModeTriage      ReviewMode = "triage"  // issues only
+	ModeExplain     ReviewMode = "explain" // issues only
 )
 
+// CommandAction identifies what a command asks the bot to do
+type CommandAction string
+
+const (
+	ActionReview  CommandAction = ""        // run a review in Mode
+	ActionHelp    CommandAction = "help"    // list available commands
+	ActionStatus  CommandAction = "status"  // report queued and running reviews
+	ActionCancel  CommandAction = "cancel"  // abort the requester's in-flight reviews
+	ActionApprove CommandAction = "approve" // let reviews awaiting maintainer approval run
+	ActionFix     CommandAction = "fix"     // apply the suggested patch of the finding replied to
+)
+
+// TargetKind identifies what a review covers
+type TargetKind string
+
+const (
+	TargetPullRequest TargetKind = "pull_request"
+	TargetCommit      TargetKind = "commit"
+	TargetCompare     TargetKind = "compare"
+	TargetAudit       TargetKind = "audit" // scheduled audit of a branch's files
+	TargetIssue       TargetKind = "issue" // a plain issue, for triage and explain
+)
+
+// Scheduled audit kinds
+const (
+	AuditSecurity     = "security"     // source files
+	AuditDependencies = "dependencies" // dependency manifests
+)
+
+// ReviewTarget is the change a review covers when it is not a pull request
+type ReviewTarget struct {
+	Kind TargetKind `json:"kind"`
+	Base string     `json:"base,omitempty"` // start of a compare range
+	Head string     `json:"head"`           // the commit, or the end of a compare range
+	Ref  string     `json:"ref,omitempty"`  // branch the commits were pushed to
+
+	Issue int `json:"issue,omitempty"` // the plain issue a triage or explain command was made on
+
+	Paths []string `json:"paths,omitempty"` // directories a scheduled audit covers
+}
+
+// IsPullRequest reports whether the target is a pull request; a nil target is one
+func (t *ReviewTarget) IsPullRequest() bool {
+	return t == nil || t.Kind == "" || t.Kind == TargetPullRequest
+}
+
+// IsPush reports whether the target is pushed commits, reviewed without a requester
+func (t *ReviewTarget) IsPush() bool {
+	return t != nil && (t.Kind == TargetCommit || t.Kind == TargetCompare)
+}
+
 // Command represents a parsed @techy command from a GitHub comment
 type Command struct {
-	Mode    ReviewMode
-	Verbose bool
-	Raw     string
+	Action  CommandAction  `json:"action,omitempty"`
+	Mode    ReviewMode     `json:"mode"`
+	Verbose bool           `json:"verbose"`
+	Raw     string         `json:"raw"`
+	Options CommandOptions `json:"options"`
+}
+
+// IsControl reports whether the command controls the bot rather than requesting a review
+func (c *Command) IsControl() bool {
+	return c.Action != ActionReview
+}
+
+// Key identifies what a command asks for: its mode, followed by a short hash
+// of its options when it has any. Requests with the same key are duplicates.
+func (c *Command) Key() string {
+	opts := c.Options
+	opts.Files = append([]string(nil), opts.Files...)
+	opts.Exclude = append([]string(nil), opts.Exclude...)
+	sort.Strings(opts.Files)
+	sort.Strings(opts.Exclude)
+	if !c.Verbose && len(opts.Files) == 0 && len(opts.Exclude) == 0 && opts.Model == "" &&
+		opts.MinSeverity == "" && opts.Focus == "" && opts.Text == "" {
+		return string(c.Mode)
+	}
+
+	data, _ := json.Marshal(struct {
+		Verbose bool           `json:"verbose"`
+		Options CommandOptions `json:"options"`
+	}{c.Verbose, opts})
+	sum := sha256.Sum256(data)
+	return string(c.Mode) + "-" + hex.EncodeToString(sum[:6])
+}
+
+// CommandOptions holds the optional arguments of a command
+type CommandOptions struct {
+	Files       []string `json:"files,omitempty"`        // glob patterns of files to review
+	Exclude     []string `json:"exclude,omitempty"`      // glob patterns of files to skip
+	Model       string   `json:"model,omitempty"`        // model override (opus, sonnet, haiku or full id)
+	MinSeverity string   `json:"min_severity,omitempty"` // error, warning or info
+	Focus       string   `json:"focus,omitempty"`        // area the review should concentrate on
+	Text        string   `json:"text,omitempty"`         // free text instructions
 }
 
 // ReviewRequest contains all information needed to perform a code review
@@ -35,6 +133,19 @@ type ReviewRequest struct {
 	PRBody      string
 	Files       []PRFile
 	PRContext   interface{ BuildContextPrompt() string } // For context-aware reviews
+	Target      *ReviewTarget                            // set when reviewing pushed commits instead of a PR
+	Labels      []string                                 // labels defined in the repository, for triage
+
+	ContextFiles  []ContextFile      // Full file contents at the head commit
+	SkippedFiles  []PRFile           // Generated, vendored and lock files left out of the diff
+	Analysis      []AnalyzerFinding  // Static analyzer findings on changed lines
+	TestTargets   []TestTarget       // Changed functions with no tests, for the tests mode
+	Dependencies  []DependencyChange // Package changes parsed from changed manifests
+	Migrations    []AnalyzerFinding  // Dangerous statements found in changed SQL migrations
+	WorkDir       string             // Repository checkout the CLI runs in; empty runs without one
+	TmpDir        string             // Scratch directory for the CLI when WorkDir is set
+	SystemPrompt  string             // Rendered prompt template; empty uses the mode's default prompt
+	PromptVersion string             // Template version that produced SystemPrompt
 }
 
 // PRFile represents a file changed in a pull request
@@ -46,6 +157,36 @@ type PRFile struct {
 	Changes      int
 	Patch        string
 	PreviousName string // for renamed files
+
+	Hunks     []DiffHunk // hunks annotated with the symbols they change
+	Generated bool       // vendored, generated or a lock file
+}
+
+// DiffHunk is a hunk of a file's patch and the code symbol it changes
+type DiffHunk struct {
+	OldStart    int
+	OldLines    int
+	NewStart    int
+	NewLines    int
+	FirstChange int    // first added or removed line, in new-file numbering
+	Context     string // text git prints after the hunk header
+	Symbol      string // enclosing function, method or type, e.g. Reviewer.ProcessReview
+	SymbolKind  string // func, method, type or class
+}
+
+// TestTarget is a changed function that no test in its package refers to
+type TestTarget struct {
+	Path string
+	Name string // Reviewer.ProcessReview or parse_config
+	Kind string // func or method
+	Line int    // first changed line in the function
+}
+
+// ContextFile is the full content of a file included in the prompt as context
+type ContextFile struct {
+	Path    string
+	Content string
+	Reason  string // changed, or imported by a changed file
 }
 
 // ReviewResponse contains the formatted review result
@@ -62,6 +203,46 @@ type ReviewComment struct {
 	Side     string // LEFT or RIGHT
 	Body     string
 	Severity string // error, warning, info
+
+	Verification       string // verified, not_reproduced, inconclusive; empty if not checked
+	VerificationOutput string // generated test, command and its output
+	Source             string // static analyzer the finding confirms; empty for the model's own
+}
+
+// AnalyzerFinding is a diagnostic reported by a static analysis tool
+type AnalyzerFinding struct {
+	Tool     string // go vet, staticcheck, gosec or a configured command
+	Rule     string // tool-specific check id, e.g. SA4006 or G104
+	Path     string
+	Line     int
+	Column   int
+	Severity string // error, warning, info
+	Message  string
+}
+
+// DependencyChange is a package added, removed or changed in a dependency manifest
+type DependencyChange struct {
+	Manifest   string // path of the changed manifest
+	Ecosystem  string // OSV ecosystem: Go, npm, PyPI or crates.io
+	Name       string
+	Change     string // added, removed, upgraded, downgraded or changed
+	OldVersion string
+	NewVersion string // as written in the manifest: one release or a range
+	Pinned     bool   // NewVersion names one release
+	Checked    bool   // NewVersion was looked up in the vulnerability database
+	License    string // SPDX expression from the license database; empty when unknown
+	Disallowed bool   // License is not on the allowlist
+
+	Vulnerabilities []Vulnerability
+}
+
+// Vulnerability is a known vulnerability affecting a package version
+type Vulnerability struct {
+	ID       string // OSV id, e.g. GHSA-xxxx-xxxx-xxxx or GO-2024-0001
+	Aliases  []string
+	Summary  string
+	Severity string // critical, high, moderate or low; empty when the advisory has none
+	Fixed    string // first release with the fix; empty when there is none
 }
 
 // OAuthCredentials holds the Claude OAuth tokens
@@ -193,6 +374,84 @@ type Config struct {
 	DedupEnabled bool // Enable request deduplication
 	DedupTTLMin  int  // Deduplication TTL in minutes
 
+	// Authorization policy (per-repository settings override these)
+	AuthEnabled         bool   // Check who may trigger reviews
+	AuthMinPermission   string // Minimum repository role: read, triage, write, maintain, admin
+	AuthAllowOrgMembers bool   // Members of the owning organization may trigger reviews
+	AuthRequireApproval bool   // First-time contributors need a maintainer's `approve`
+
+	// File context (full contents of changed files in the prompt)
+	ContextFilesEnabled   bool // Send full post-change contents of modified files
+	ContextIncludeImports bool // Also send files imported by modified files
+	ContextMaxTokens      int  // Approximate token budget for file contents
+	ContextMaxFileSize    int  // Skip files larger than this many bytes
+
+	// Static analysis on the sandbox checkout
+	AnalyzersEnabled    bool     // Run analyzers and send their findings with the diff
+	Analyzers           []string // Built-in analyzers to run: go vet, staticcheck, gosec
+	AnalyzerCommands    string   // Extra name=command analyzers separated by semicolons
+	AnalyzerTimeoutSec  int      // Limit for one analyzer
+	AnalyzerMaxFindings int      // Findings sent with one review
+
+	// Generated, vendored and lock files
+	SkipGeneratedFiles    bool     // Leave them out of the diff sent for review
+	GeneratedFilePatterns []string // Globs on top of the built-in list and .gitattributes
+
+	// Sandboxed checkouts for the CLI
+	SandboxEnabled         bool   // Clone the PR head and run the CLI inside it
+	SandboxDir             string // Parent directory for checkouts
+	SandboxCloneTimeoutSec int    // Limit for cloning one commit
+	SandboxMaxSizeMB       int    // Skip the checkout for larger repositories
+
+	// Bug verification in containers (needs the sandbox)
+	VerifyEnabled         bool   // Reproduce high-severity findings with generated tests
+	VerifyMode            string // annotate or filter
+	VerifyMinSeverity     string // Findings at this severity or above are checked
+	VerifyMaxFindings     int    // Findings checked per review
+	VerifyTimeoutSec      int    // Limit for one test run
+	VerifyMemoryMB        int    // Container memory limit
+	VerifyCPUs            string // Container CPU limit
+	VerifyNetwork         string // Container network mode
+	VerifyImages          string // language=image pairs overriding the defaults
+	VerifyGoModCache      string // Pre-warmed Go module cache mounted read-only
+	VerifyDownloadNetwork string // Network mode for downloading Go modules; none disables it
+	DockerPath            string // Path to the docker CLI
+
+	// Code scanning
+	SARIFUploadEnabled bool     // Upload findings to GitHub code scanning after a review
+	SARIFUploadModes   []string // Modes whose findings are uploaded
+
+	// Sticky comments
+	StickyComments bool // Edit one comment per mode on re-runs and collapse superseded reviews
+
+	// PR summaries
+	AutoSummarize bool   // Summarize PRs when they are opened or marked ready for review
+	SummaryTarget string // comment (sticky comment) or body (fill an empty PR description)
+
+	// Test generation
+	TestsDelivery string // comment (code blocks in a comment) or pr (follow-up PR into the head branch)
+
+	// Reviews of pushed commits
+	PushReviewBranches  []string // Branch globs whose pushes are reviewed; empty disables push reviews
+	PushReviewMode      string   // Mode pushes are reviewed in
+	CompareReviewOutput string   // issue or check, for pushes of several commits
+
+	// Scheduled audits
+	AuditSchedules string // "cron|owner/repo|kind|dir,dir" entries separated by semicolons
+	AuditMaxFiles  int    // Files read per audit run
+
+	// Migration checks
+	MigrationPaths []string // Globs of migration files; pull requests changing them are checked automatically
+
+	// Secret scanning
+	SecretScan bool // Report and redact secrets in diffs before prompting
+
+	// Dependency review
+	DependencyReview bool     // Parse changed manifests and check their packages
+	OSVDir           string   // Directory of OSV export files: advisory JSON or per-ecosystem all.zip
+	LicenseFile      string   // JSON object mapping "ecosystem:name" to an SPDX license
+	LicenseAllowlist []string // SPDX licenses packages may use; empty skips the check
+
 	// Admin API
 	AdminAPIKey string
 
@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestCommandKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Command
+		same bool
+	}{
+		{
+			name: "same mode without options",
+			a:    Command{Mode: ModeHunt, Raw: "@techy hunt"},
+			b:    Command{Mode: ModeHunt, Raw: "@techy  hunt"},
+			same: true,
+		},
+		{
+			name: "different modes",
+			a:    Command{Mode: ModeHunt},
+			b:    Command{Mode: ModeSecurity},
+		},
+		{
+			name: "different focus",
+			a:    Command{Mode: ModeHunt, Options: CommandOptions{Focus: "perf"}},
+			b:    Command{Mode: ModeHunt, Options: CommandOptions{Focus: "auth"}},
+		},
+		{
+			name: "options against none",
+			a:    Command{Mode: ModeHunt, Options: CommandOptions{Files: []string{"x/**"}}},
+			b:    Command{Mode: ModeHunt},
+		},
+		{
+			name: "file order does not matter",
+			a:    Command{Mode: ModeHunt, Options: CommandOptions{Files: []string{"a", "b"}}},
+			b:    Command{Mode: ModeHunt, Options: CommandOptions{Files: []string{"b", "a"}}},
+			same: true,
+		},
+		{
+			name: "verbose differs",
+			a:    Command{Mode: ModeHunt, Verbose: true},
+			b:    Command{Mode: ModeHunt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.a.Key() == tt.b.Key(); same != tt.same {
+				t.Errorf("Key() %q vs %q: same = %v, want %v", tt.a.Key(), tt.b.Key(), same, tt.same)
+			}
+		})
+	}
+
+	if got := (&Command{Mode: ModeReview}).Key(); got != "review" {
+		t.Errorf("Key() without options = %q, want %q", got, "review")
+	}
+	files := []string{"b", "a"}
+	(&Command{Mode: ModeHunt, Options: CommandOptions{Files: files}}).Key()
+	if files[0] != "b" {
+		t.Error("Key() reordered the command's files")
+	}
+}